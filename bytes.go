@@ -0,0 +1,21 @@
+package sfstypes
+
+import "unsafe"
+
+// BytesToInt8s reinterprets value as a []int8 without copying. Both slices
+// share the same memory.
+func BytesToInt8s(value []byte) []int8 {
+	if value == nil {
+		return nil
+	}
+	return unsafe.Slice((*int8)(unsafe.Pointer(unsafe.SliceData(value))), len(value))
+}
+
+// Int8sToBytes reinterprets value as a []byte without copying. Both slices
+// share the same memory.
+func Int8sToBytes(value []int8) []byte {
+	if value == nil {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(value))), len(value))
+}
@@ -0,0 +1,77 @@
+package sfstypes
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestBytesToInt8s(t *testing.T) {
+	if BytesToInt8s(nil) != nil {
+		t.Fatal("BytesToInt8s(nil) isn't nil")
+	}
+	if empty := BytesToInt8s([]byte{}); empty == nil || len(empty) != 0 {
+		t.Fatalf("BytesToInt8s([]byte{}) = %#v, want an empty non-nil slice", empty)
+	}
+	value := []byte{0, 1, 0x7f, 0x80, 0xff}
+	converted := BytesToInt8s(value)
+	if !slices.Equal(converted, []int8{0, 1, 127, -128, -1}) {
+		t.Fatalf("got %v", converted)
+	}
+	converted[0] = -2
+	if value[0] != 0xfe {
+		t.Fatal("BytesToInt8s copied its input")
+	}
+}
+
+func TestInt8sToBytes(t *testing.T) {
+	if Int8sToBytes(nil) != nil {
+		t.Fatal("Int8sToBytes(nil) isn't nil")
+	}
+	if empty := Int8sToBytes([]int8{}); empty == nil || len(empty) != 0 {
+		t.Fatalf("Int8sToBytes([]int8{}) = %#v, want an empty non-nil slice", empty)
+	}
+	value := []int8{0, 1, 127, -128, -1}
+	converted := Int8sToBytes(value)
+	if !bytes.Equal(converted, []byte{0, 1, 0x7f, 0x80, 0xff}) {
+		t.Fatalf("got %v", converted)
+	}
+	converted[0] = 0xfe
+	if value[0] != -2 {
+		t.Fatal("Int8sToBytes copied its input")
+	}
+}
+
+func TestUnsignedBytesSurviveDecoding(t *testing.T) {
+	sfsobject := NewSFSObject()
+	if err := sfsobject.Put("byte", uint8(200)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sfsobject.Put("blob", []byte{0xca, 0xfe}); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := NewSFSObjectFromBinaryData(sfsobject.ToBinary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := decoded.GetUnsignedByte("byte"); err != nil || value != 200 {
+		t.Fatalf("GetUnsignedByte = %v, %v", value, err)
+	}
+	if value, err := decoded.GetByte("byte"); err != nil || value != -56 {
+		t.Fatalf("GetByte = %v, %v", value, err)
+	}
+	if value, err := decoded.GetUnsignedByteArray("blob"); err != nil || !bytes.Equal(value, []byte{0xca, 0xfe}) {
+		t.Fatalf("GetUnsignedByteArray = %v, %v", value, err)
+	}
+	if value, err := decoded.GetByteArray("blob"); err != nil || !slices.Equal(value, []int8{-54, -2}) {
+		t.Fatalf("GetByteArray = %v, %v", value, err)
+	}
+
+	sfsarray := NewSFSArray()
+	if err := sfsarray.Add([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if typeId, err := sfsarray.TypeOf(0); err != nil || typeId != TypeByteArray {
+		t.Fatalf("TypeOf(0) = %v, %v", typeId, err)
+	}
+}
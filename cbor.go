@@ -0,0 +1,556 @@
+package sfstypes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// CBOR (RFC 8949) conversion maps SFS types onto CBOR items as follows:
+//
+//	NULL, BOOL                 null, false/true
+//	INT                        integer
+//	BYTE, SHORT, LONG          integer with a private tag
+//	FLOAT, DOUBLE              single, double precision float
+//	UTF_STRING                 text string
+//	TEXT                       text string with a private tag
+//	BYTE_ARRAY                 byte string
+//	SHORT_ARRAY, INT_ARRAY,    RFC 8746 typed array tags 73, 74, 75, 81 and 82
+//	LONG_ARRAY, FLOAT_ARRAY,   (big endian) on a byte string
+//	DOUBLE_ARRAY
+//	BOOL_ARRAY,                array with a private tag
+//	UTF_STRING_ARRAY
+//	SFS_OBJECT, SFS_ARRAY      map, array
+//
+// Integers, lengths and tags are written in their shortest form, as the
+// preferred serialization asks. Floats are not: FLOAT is always written as a
+// single and DOUBLE as a double precision float, because the width is what
+// tells the two types apart when decoding. Map keys are sorted by their
+// bytes, like in ToBinary, not by the length-first order of the RFC 8949
+// core deterministic encoding.
+//
+// Private tags are CBORTagBase plus the SFS type id. When reading CBOR from
+// other sources, untagged integers become INT or LONG, half precision floats
+// become FLOAT, text longer than a UTF_STRING can hold becomes TEXT and the
+// little endian typed array tags and tags 64 and 72 (uint8 and sint8, read as
+// BYTE_ARRAY) are accepted as well. Indefinite lengths are not supported.
+// Tags count as a nesting level towards the decoding depth limit, like maps
+// and arrays.
+
+// CBORTagBase is added to an SFS type id to form its private CBOR tag.
+const CBORTagBase = 0x53465300
+
+const (
+	cborUnsigned     = 0 << 5
+	cborNegative     = 1 << 5
+	cborByteString   = 2 << 5
+	cborTextString   = 3 << 5
+	cborArray        = 4 << 5
+	cborMap          = 5 << 5
+	cborTag          = 6 << 5
+	cborSimple       = 7 << 5
+	cborFalse        = cborSimple | 20
+	cborTrue         = cborSimple | 21
+	cborNull         = cborSimple | 22
+	cborUndefined    = cborSimple | 23
+	cborFloat16      = cborSimple | 25
+	cborFloat32      = cborSimple | 26
+	cborFloat64      = cborSimple | 27
+	cborTagUint8     = 64
+	cborTagSint8     = 72
+	cborTagSint16    = 73
+	cborTagSint32    = 74
+	cborTagSint64    = 75
+	cborTagFloat32   = 81
+	cborTagFloat64   = 82
+	cborLittleEndian = 4
+)
+
+func (sfsobject *SFSObject) ToCBOR() []byte {
+	return appendCBOR(nil, TypeSFSObject, sfsobject)
+}
+
+func (sfsarray *SFSArray) ToCBOR() []byte {
+	return appendCBOR(nil, TypeSFSArray, sfsarray)
+}
+
+func NewSFSObjectFromCBOR(data []byte) (*SFSObject, error) {
+	wrapper, err := decodeCBORContainer(data, TypeSFSObject)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSObject), nil
+}
+
+func NewSFSArrayFromCBOR(data []byte) (*SFSArray, error) {
+	wrapper, err := decodeCBORContainer(data, TypeSFSArray)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSArray), nil
+}
+
+// appendCBORHead writes the initial byte of an item of the given major type
+// with its argument in the shortest form.
+func appendCBORHead(dst []byte, major byte, argument uint64) []byte {
+	switch {
+	case argument < 24:
+		return append(dst, major|byte(argument))
+	case argument <= math.MaxUint8:
+		return append(dst, major|24, byte(argument))
+	case argument <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(argument))
+	case argument <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(argument))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), argument)
+	}
+}
+
+func appendCBORInteger(dst []byte, value int64) []byte {
+	if value < 0 {
+		return appendCBORHead(dst, cborNegative, uint64(-(value + 1)))
+	}
+	return appendCBORHead(dst, cborUnsigned, uint64(value))
+}
+
+func appendCBORString(dst []byte, major byte, str string) []byte {
+	return append(appendCBORHead(dst, major, uint64(len(str))), str...)
+}
+
+func appendCBOR(dst []byte, typeId DataType, data interface{}) []byte {
+	switch typeId {
+	case TypeNull:
+		return append(dst, cborNull)
+	case TypeBool:
+		if data.(bool) {
+			return append(dst, cborTrue)
+		}
+		return append(dst, cborFalse)
+	case TypeByte:
+		return appendCBORInteger(appendCBORHead(dst, cborTag, CBORTagBase+uint64(TypeByte)), int64(data.(int8)))
+	case TypeShort:
+		return appendCBORInteger(appendCBORHead(dst, cborTag, CBORTagBase+uint64(TypeShort)), int64(data.(int16)))
+	case TypeInt:
+		return appendCBORInteger(dst, int64(data.(int32)))
+	case TypeLong:
+		return appendCBORInteger(appendCBORHead(dst, cborTag, CBORTagBase+uint64(TypeLong)), data.(int64))
+	case TypeFloat:
+		return binary.BigEndian.AppendUint32(append(dst, cborFloat32), math.Float32bits(data.(float32)))
+	case TypeDouble:
+		return binary.BigEndian.AppendUint64(append(dst, cborFloat64), math.Float64bits(data.(float64)))
+	case TypeUtfString:
+		return appendCBORString(dst, cborTextString, data.(string))
+	case TypeText:
+		return appendCBORString(appendCBORHead(dst, cborTag, CBORTagBase+uint64(TypeText)), cborTextString, data.(string))
+	case TypeByteArray:
+		bytes := data.([]byte)
+		return append(appendCBORHead(dst, cborByteString, uint64(len(bytes))), bytes...)
+	case TypeBoolArray:
+		array := data.([]bool)
+		dst = appendCBORHead(dst, cborTag, CBORTagBase+uint64(TypeBoolArray))
+		dst = appendCBORHead(dst, cborArray, uint64(len(array)))
+		for _, element := range array {
+			dst = appendCBOR(dst, TypeBool, element)
+		}
+		return dst
+	case TypeUtfStringArray:
+		array := data.([]string)
+		dst = appendCBORHead(dst, cborTag, CBORTagBase+uint64(TypeUtfStringArray))
+		dst = appendCBORHead(dst, cborArray, uint64(len(array)))
+		for _, element := range array {
+			dst = appendCBORString(dst, cborTextString, element)
+		}
+		return dst
+	case TypeShortArray:
+		array := data.([]int16)
+		dst = appendCBORHead(appendCBORHead(dst, cborTag, cborTagSint16), cborByteString, uint64(len(array)*2))
+		for _, element := range array {
+			dst = binary.BigEndian.AppendUint16(dst, uint16(element))
+		}
+		return dst
+	case TypeIntArray:
+		array := data.([]int32)
+		dst = appendCBORHead(appendCBORHead(dst, cborTag, cborTagSint32), cborByteString, uint64(len(array)*4))
+		for _, element := range array {
+			dst = binary.BigEndian.AppendUint32(dst, uint32(element))
+		}
+		return dst
+	case TypeLongArray:
+		array := data.([]int64)
+		dst = appendCBORHead(appendCBORHead(dst, cborTag, cborTagSint64), cborByteString, uint64(len(array)*8))
+		for _, element := range array {
+			dst = binary.BigEndian.AppendUint64(dst, uint64(element))
+		}
+		return dst
+	case TypeFloatArray:
+		array := data.([]float32)
+		dst = appendCBORHead(appendCBORHead(dst, cborTag, cborTagFloat32), cborByteString, uint64(len(array)*4))
+		for _, element := range array {
+			dst = binary.BigEndian.AppendUint32(dst, math.Float32bits(element))
+		}
+		return dst
+	case TypeDoubleArray:
+		array := data.([]float64)
+		dst = appendCBORHead(appendCBORHead(dst, cborTag, cborTagFloat64), cborByteString, uint64(len(array)*8))
+		for _, element := range array {
+			dst = binary.BigEndian.AppendUint64(dst, math.Float64bits(element))
+		}
+		return dst
+	case TypeSFSObject:
+		sfsobject := data.(*SFSObject)
+		dst = appendCBORHead(dst, cborMap, uint64(len(sfsobject.dataHolder)))
+		for _, key := range sfsobject.sortedKeys() {
+			wrapper := sfsobject.dataHolder[key]
+			dst = appendCBORString(dst, cborTextString, key)
+			dst = appendCBOR(dst, wrapper.typeId, wrapper.data)
+		}
+		return dst
+	case TypeSFSArray:
+		sfsarray := data.(*SFSArray)
+		dst = appendCBORHead(dst, cborArray, uint64(len(sfsarray.dataHolder)))
+		for _, wrapper := range sfsarray.dataHolder {
+			dst = appendCBOR(dst, wrapper.typeId, wrapper.data)
+		}
+		return dst
+	}
+	return dst
+}
+
+func decodeCBORContainer(data []byte, typeId DataType) (sfsDataWrapper, error) {
+	decoder := sfsDecoder{data: data}
+	wrapper, err := decoder.decodeCBOR()
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	if wrapper.typeId != typeId {
+		return sfsDataWrapper{}, &ErrWrongType{ActualType: wrapper.typeId, WantedType: typeId}
+	}
+	if decoder.remaining() > 0 {
+		return sfsDataWrapper{}, fmt.Errorf("%d bytes of trailing data after CBOR item", decoder.remaining())
+	}
+	return wrapper, nil
+}
+
+// readCBORHead reads the initial byte of an item and its argument. For major
+// type 7 the argument of floats is returned as raw bits.
+func (decoder *sfsDecoder) readCBORHead() (byte, uint64, error) {
+	initial, err := decoder.readByte("CBOR initial byte")
+	if err != nil {
+		return 0, 0, err
+	}
+	major, info := initial&0xe0, initial&0x1f
+	var argument uint64
+	switch {
+	case info < 24:
+		argument = uint64(info)
+	case info == 24:
+		var value byte
+		value, err = decoder.readByte("CBOR argument")
+		argument = uint64(value)
+	case info == 25:
+		var value uint16
+		value, err = decoder.readUint16("CBOR argument")
+		argument = uint64(value)
+	case info == 26:
+		var value uint32
+		value, err = decoder.readUint32("CBOR argument")
+		argument = uint64(value)
+	case info == 27:
+		argument, err = decoder.readUint64("CBOR argument")
+	case info == 31 && major != cborUnsigned && major != cborNegative && major != cborTag && major != cborSimple:
+		err = fmt.Errorf("indefinite-length CBOR items are not supported")
+	default:
+		err = fmt.Errorf("malformed CBOR initial byte 0x%02x", initial)
+	}
+	return major, argument, err
+}
+
+func (decoder *sfsDecoder) readCBORLength(argument uint64, typeToRead string) (int, error) {
+	if argument > uint64(decoder.remaining()) {
+		return 0, decoder.readError(typeToRead)
+	}
+	return int(argument), nil
+}
+
+// decodeCBOR reads the next CBOR item.
+func (decoder *sfsDecoder) decodeCBOR() (sfsDataWrapper, error) {
+	start := decoder.offset
+	major, argument, err := decoder.readCBORHead()
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	switch major {
+	case cborUnsigned, cborNegative:
+		value, err := cborInteger(major, argument)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		if integerFits(value, TypeInt) {
+			return sfsDataWrapper{typeId: TypeInt, data: int32(value)}, nil
+		}
+		return sfsDataWrapper{typeId: TypeLong, data: value}, nil
+	case cborByteString:
+		length, err := decoder.readCBORLength(argument, "CBOR byte string")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		bytes, _ := decoder.read(length, "CBOR byte string")
+		results := make([]byte, length)
+		copy(results, bytes)
+		return sfsDataWrapper{typeId: TypeByteArray, data: results}, nil
+	case cborTextString:
+		length, err := decoder.readCBORLength(argument, "CBOR text string")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		bytes, _ := decoder.read(length, "CBOR text string")
+		if length > maxUtfStringLength {
+			return sfsDataWrapper{typeId: TypeText, data: string(bytes)}, nil
+		}
+		return sfsDataWrapper{typeId: TypeUtfString, data: string(bytes)}, nil
+	case cborArray:
+		size, err := decoder.readCBORLength(argument, "CBOR array")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return decoder.decodeCBORArray(size)
+	case cborMap:
+		size, err := decoder.readCBORLength(argument, "CBOR map")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return decoder.decodeCBORMap(size)
+	case cborTag:
+		return decoder.decodeCBORTag(argument)
+	}
+	switch decoder.data[start] {
+	case cborFalse, cborTrue:
+		return sfsDataWrapper{typeId: TypeBool, data: decoder.data[start] == cborTrue}, nil
+	case cborNull, cborUndefined:
+		return sfsDataWrapper{typeId: TypeNull}, nil
+	case cborFloat16:
+		return sfsDataWrapper{typeId: TypeFloat, data: float16ToFloat32(uint16(argument))}, nil
+	case cborFloat32:
+		return sfsDataWrapper{typeId: TypeFloat, data: math.Float32frombits(uint32(argument))}, nil
+	case cborFloat64:
+		return sfsDataWrapper{typeId: TypeDouble, data: math.Float64frombits(argument)}, nil
+	}
+	return sfsDataWrapper{}, fmt.Errorf("unsupported CBOR simple value 0x%02x", decoder.data[start])
+}
+
+func cborInteger(major byte, argument uint64) (int64, error) {
+	if argument > math.MaxInt64 {
+		return 0, fmt.Errorf("CBOR integer doesn't fit into a LONG")
+	}
+	if major == cborNegative {
+		return -1 - int64(argument), nil
+	}
+	return int64(argument), nil
+}
+
+func (decoder *sfsDecoder) decodeCBORTag(tag uint64) (sfsDataWrapper, error) {
+	if tag >= CBORTagBase && tag <= CBORTagBase+uint64(TypeText) {
+		return decoder.decodeCBORPrivateTag(DataType(tag - CBORTagBase))
+	}
+	// Tags nest like arrays, so chains of them count towards the depth limit.
+	if err := decoder.enter(); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	defer decoder.leave()
+	byteOrder := binary.ByteOrder(binary.BigEndian)
+	if (tag >= cborTagSint16+cborLittleEndian && tag <= cborTagSint64+cborLittleEndian) ||
+		(tag >= cborTagFloat32+cborLittleEndian && tag <= cborTagFloat64+cborLittleEndian) {
+		byteOrder = binary.LittleEndian
+		tag -= cborLittleEndian
+	}
+	var typeId DataType
+	var elementSize int
+	switch tag {
+	case cborTagUint8, cborTagSint8:
+		typeId, elementSize = TypeByteArray, 1
+	case cborTagSint16:
+		typeId, elementSize = TypeShortArray, 2
+	case cborTagSint32:
+		typeId, elementSize = TypeIntArray, 4
+	case cborTagSint64:
+		typeId, elementSize = TypeLongArray, 8
+	case cborTagFloat32:
+		typeId, elementSize = TypeFloatArray, 4
+	case cborTagFloat64:
+		typeId, elementSize = TypeDoubleArray, 8
+	default:
+		return sfsDataWrapper{}, fmt.Errorf("unsupported CBOR tag %d", tag)
+	}
+	content, err := decoder.decodeCBOR()
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	bytes, isBytes := content.data.([]byte)
+	if content.typeId != TypeByteArray || !isBytes || len(bytes)%elementSize != 0 {
+		return sfsDataWrapper{}, fmt.Errorf("CBOR typed array tag %d needs a byte string of %d byte elements", tag, elementSize)
+	}
+	length := len(bytes) / elementSize
+	switch typeId {
+	case TypeShortArray:
+		results := make([]int16, length)
+		for i := range results {
+			results[i] = int16(byteOrder.Uint16(bytes[i*2:]))
+		}
+		return sfsDataWrapper{typeId: typeId, data: results}, nil
+	case TypeIntArray:
+		results := make([]int32, length)
+		for i := range results {
+			results[i] = int32(byteOrder.Uint32(bytes[i*4:]))
+		}
+		return sfsDataWrapper{typeId: typeId, data: results}, nil
+	case TypeLongArray:
+		results := make([]int64, length)
+		for i := range results {
+			results[i] = int64(byteOrder.Uint64(bytes[i*8:]))
+		}
+		return sfsDataWrapper{typeId: typeId, data: results}, nil
+	case TypeFloatArray:
+		results := make([]float32, length)
+		for i := range results {
+			results[i] = math.Float32frombits(byteOrder.Uint32(bytes[i*4:]))
+		}
+		return sfsDataWrapper{typeId: typeId, data: results}, nil
+	case TypeDoubleArray:
+		results := make([]float64, length)
+		for i := range results {
+			results[i] = math.Float64frombits(byteOrder.Uint64(bytes[i*8:]))
+		}
+		return sfsDataWrapper{typeId: typeId, data: results}, nil
+	default:
+		return content, nil
+	}
+}
+
+// decodeCBORPrivateTag decodes the content of a private tag for typeId.
+func (decoder *sfsDecoder) decodeCBORPrivateTag(typeId DataType) (sfsDataWrapper, error) {
+	if err := decoder.enter(); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	defer decoder.leave()
+	content, err := decoder.decodeCBOR()
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	wrongContent := fmt.Errorf("CBOR tag for %s doesn't hold a matching item", typeId.Name())
+	switch typeId {
+	case TypeByte, TypeShort, TypeLong:
+		var value int64
+		switch data := content.data.(type) {
+		case int32:
+			value = int64(data)
+		case int64:
+			value = data
+		default:
+			return sfsDataWrapper{}, wrongContent
+		}
+		if !integerFits(value, typeId) {
+			return sfsDataWrapper{}, &ErrNumericOverflow{Value: value, Type: typeId}
+		}
+		return sfsDataWrapper{typeId: typeId, data: integerData(typeId, value)}, nil
+	case TypeText:
+		if _, isString := content.data.(string); !isString {
+			return sfsDataWrapper{}, wrongContent
+		}
+		return sfsDataWrapper{typeId: TypeText, data: content.data}, nil
+	case TypeBoolArray, TypeUtfStringArray:
+		array, isArray := content.data.(*SFSArray)
+		if !isArray {
+			return sfsDataWrapper{}, wrongContent
+		}
+		if typeId == TypeBoolArray {
+			results := make([]bool, array.Size())
+			for i := range results {
+				if results[i], err = array.GetBool(i); err != nil {
+					return sfsDataWrapper{}, wrongContent
+				}
+			}
+			return sfsDataWrapper{typeId: typeId, data: results}, nil
+		}
+		results := make([]string, array.Size())
+		for i := range results {
+			value, err := array.GetValue(i)
+			if err != nil {
+				return sfsDataWrapper{}, err
+			}
+			if results[i], err = value.AsString(); err != nil {
+				return sfsDataWrapper{}, wrongContent
+			}
+		}
+		return sfsDataWrapper{typeId: typeId, data: results}, nil
+	default:
+		return sfsDataWrapper{}, fmt.Errorf("unsupported CBOR tag for %s", typeId.Name())
+	}
+}
+
+func (decoder *sfsDecoder) decodeCBORMap(size int) (sfsDataWrapper, error) {
+	if size > math.MaxUint16 {
+		return sfsDataWrapper{}, fmt.Errorf("CBOR map with %d entries is too large for an SFSObject", size)
+	}
+	if err := decoder.enter(); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	defer decoder.leave()
+	sfsobject := NewSFSObject()
+	for i := 0; i < size; i++ {
+		key, err := decoder.decodeCBOR()
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		if key.typeId != TypeUtfString {
+			return sfsDataWrapper{}, fmt.Errorf("CBOR map key of type %s is not a string", key.typeId.Name())
+		}
+		value, err := decoder.decodeCBOR()
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		if err := sfsobject.putsfsDataWrapper(key.data.(string), &value); err != nil {
+			return sfsDataWrapper{}, err
+		}
+	}
+	return sfsDataWrapper{typeId: TypeSFSObject, data: sfsobject}, nil
+}
+
+func (decoder *sfsDecoder) decodeCBORArray(size int) (sfsDataWrapper, error) {
+	if size > math.MaxUint16 {
+		return sfsDataWrapper{}, fmt.Errorf("CBOR array with %d elements is too large for an SFSArray", size)
+	}
+	if err := decoder.enter(); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	defer decoder.leave()
+	sfsarray := &SFSArray{dataHolder: make([]sfsDataWrapper, 0, size)}
+	for i := 0; i < size; i++ {
+		value, err := decoder.decodeCBOR()
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		sfsarray.addsfsDataWrapper(value)
+	}
+	return sfsDataWrapper{typeId: TypeSFSArray, data: sfsarray}, nil
+}
+
+// float16ToFloat32 converts an IEEE 754 half precision float.
+func float16ToFloat32(bits uint16) float32 {
+	sign := uint32(bits>>15) << 31
+	exponent := uint32(bits>>10) & 0x1f
+	mantissa := uint32(bits) & 0x3ff
+	switch exponent {
+	case 0:
+		// Zero or subnormal: mantissa * 2^-24.
+		value := float32(mantissa) / (1 << 24)
+		if sign != 0 {
+			value = -value
+		}
+		return value
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mantissa<<13)
+	default:
+		return math.Float32frombits(sign | (exponent+127-15)<<23 | mantissa<<13)
+	}
+}
@@ -0,0 +1,189 @@
+package sfstypes
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestCBORChainedTags checks that long chains of tags fail with
+// ErrMaxDepthExceeded instead of overflowing the stack.
+func TestCBORChainedTags(t *testing.T) {
+	tags := map[string][]byte{
+		"standard": {0xd8, 0x40},                   // tag 64, uint8 typed array
+		"private":  {0xda, 0x53, 0x46, 0x53, 0x05}, // CBORTagBase + LONG
+	}
+	for name, tag := range tags {
+		t.Run(name, func(t *testing.T) {
+			data := append([]byte{0xa1, 0x61, 'k'}, bytes.Repeat(tag, 3_000_000)...)
+			data = append(data, 0x00)
+			var depthErr *ErrMaxDepthExceeded
+			if _, err := NewSFSObjectFromCBOR(data); !errors.As(err, &depthErr) {
+				t.Fatalf("got error %v, want ErrMaxDepthExceeded", err)
+			}
+		})
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	objects := fuzzSeedObjects()
+	for _, data := range readGoldenCorpus(t) {
+		sfsobject, err := NewSFSObjectFromBinaryData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, sfsobject)
+	}
+	for _, sfsobject := range objects {
+		decoded, err := NewSFSObjectFromCBOR(sfsobject.ToCBOR())
+		if err != nil {
+			t.Fatalf("%x: %v", sfsobject.ToCBOR(), err)
+		}
+		if got, want := decoded.ToBinary(), sfsobject.ToBinary(); !bytes.Equal(got, want) {
+			t.Fatalf("round trip changed the payload:\n%x\n%x", want, got)
+		}
+	}
+}
+
+func TestCBOREncoding(t *testing.T) {
+	tests := []struct {
+		typeId DataType
+		data   interface{}
+		want   []byte
+	}{
+		{TypeInt, int32(23), []byte{0x17}},
+		{TypeInt, int32(24), []byte{0x18, 0x18}},
+		{TypeInt, int32(-1000), []byte{0x39, 0x03, 0xe7}},
+		{TypeByte, int8(-1), []byte{0xda, 0x53, 0x46, 0x53, 0x02, 0x20}},
+		{TypeShort, int16(256), []byte{0xda, 0x53, 0x46, 0x53, 0x03, 0x19, 0x01, 0x00}},
+		{TypeLong, int64(1), []byte{0xda, 0x53, 0x46, 0x53, 0x05, 0x01}},
+		// Floats keep their width, even where a shorter one would be exact.
+		{TypeFloat, float32(1), []byte{0xfa, 0x3f, 0x80, 0x00, 0x00}},
+		{TypeDouble, float64(1), []byte{0xfb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+		{TypeText, "a", []byte{0xda, 0x53, 0x46, 0x53, 0x14, 0x61, 'a'}},
+		{TypeByteArray, []byte{1}, []byte{0x41, 0x01}},
+		{TypeBoolArray, []bool{true}, []byte{0xda, 0x53, 0x46, 0x53, 0x09, 0x81, 0xf5}},
+		{TypeUtfStringArray, []string{"a"}, []byte{0xda, 0x53, 0x46, 0x53, 0x10, 0x81, 0x61, 'a'}},
+		{TypeShortArray, []int16{1}, []byte{0xd8, 73, 0x42, 0x00, 0x01}},
+		{TypeIntArray, []int32{1}, []byte{0xd8, 74, 0x44, 0, 0, 0, 1}},
+		{TypeLongArray, []int64{1}, []byte{0xd8, 75, 0x48, 0, 0, 0, 0, 0, 0, 0, 1}},
+		{TypeFloatArray, []float32{1}, []byte{0xd8, 81, 0x44, 0x3f, 0x80, 0, 0}},
+		{TypeDoubleArray, []float64{1}, []byte{0xd8, 82, 0x48, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, test := range tests {
+		sfsarray := NewSFSArray()
+		sfsarray.addsfsDataWrapper(sfsDataWrapper{typeId: test.typeId, data: test.data})
+		encoded := sfsarray.ToCBOR()
+		if !bytes.Equal(encoded[1:], test.want) {
+			t.Errorf("%v: got %x, want %x", test.typeId, encoded[1:], test.want)
+		}
+		decoded, err := NewSFSArrayFromCBOR(encoded)
+		if err != nil {
+			t.Errorf("%v: %v", test.typeId, err)
+		} else if !bytes.Equal(decoded.ToBinary(), sfsarray.ToBinary()) {
+			t.Errorf("%v: round trip changed %x to %x", test.typeId, sfsarray.ToBinary(), decoded.ToBinary())
+		}
+	}
+
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("b", 1)
+	sfsobject.PutInt("a", 2)
+	if got, want := sfsobject.ToCBOR(), []byte{0xa2, 0x61, 'a', 0x02, 0x61, 'b', 0x01}; !bytes.Equal(got, want) {
+		t.Errorf("keys: got %x, want %x", got, want)
+	}
+}
+
+func TestCBORFromOtherProducers(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     []byte
+		wantType DataType
+		wantData interface{}
+	}{
+		{"int", []byte{0x1a, 0x7f, 0xff, 0xff, 0xff}, TypeInt, int32(math.MaxInt32)},
+		{"long", []byte{0x1a, 0x80, 0x00, 0x00, 0x00}, TypeLong, int64(math.MaxInt32 + 1)},
+		{"min long", []byte{0x3b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, TypeLong, int64(math.MinInt64)},
+		{"half", []byte{0xf9, 0x3c, 0x00}, TypeFloat, float32(1)},
+		{"half max", []byte{0xf9, 0x7b, 0xff}, TypeFloat, float32(65504)},
+		{"half subnormal", []byte{0xf9, 0x80, 0x01}, TypeFloat, float32(-5.9604645e-08)},
+		{"half infinity", []byte{0xf9, 0xfc, 0x00}, TypeFloat, float32(math.Inf(-1))},
+		{"undefined", []byte{0xf7}, TypeNull, nil},
+		{"uint8 array", []byte{0xd8, 64, 0x42, 0x01, 0xff}, TypeByteArray, []byte{1, 0xff}},
+		{"sint8 array", []byte{0xd8, 72, 0x41, 0xff}, TypeByteArray, []byte{0xff}},
+		{"sint16 little endian", []byte{0xd8, 77, 0x42, 0x01, 0x00}, TypeShortArray, []int16{1}},
+		{"sint32 little endian", []byte{0xd8, 78, 0x44, 0x01, 0, 0, 0x80}, TypeIntArray, []int32{math.MinInt32 + 1}},
+		{"sint64 little endian", []byte{0xd8, 79, 0x48, 2, 0, 0, 0, 0, 0, 0, 0}, TypeLongArray, []int64{2}},
+		{"float32 little endian", []byte{0xd8, 85, 0x44, 0, 0, 0x80, 0x3f}, TypeFloatArray, []float32{1}},
+		{"float64 little endian", []byte{0xd8, 86, 0x48, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f}, TypeDoubleArray, []float64{1}},
+		{"empty typed array", []byte{0xd8, 82, 0x40}, TypeDoubleArray, []float64{}},
+	}
+	for _, test := range tests {
+		data := append([]byte{0xa1, 0x61, 'k'}, test.item...)
+		sfsobject, err := NewSFSObjectFromCBOR(data)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		wrapper := sfsobject.dataHolder["k"]
+		if wrapper.typeId != test.wantType || !reflect.DeepEqual(wrapper.data, test.wantData) {
+			t.Errorf("%s: got %v %#v, want %v %#v", test.name, wrapper.typeId, wrapper.data, test.wantType, test.wantData)
+		}
+	}
+
+	sfsobject, err := NewSFSObjectFromCBOR([]byte{0xa1, 0x61, 'k', 0xf9, 0x7e, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := sfsobject.GetFloat("k"); !math.IsNaN(float64(value)) {
+		t.Errorf("half NaN decoded as %v", value)
+	}
+}
+
+func TestCBORRejectsCorruptInput(t *testing.T) {
+	valid := fuzzSeedObjects()[1].ToCBOR()
+	for length := 0; length < len(valid); length++ {
+		if _, err := NewSFSObjectFromCBOR(valid[:length]); err == nil {
+			t.Fatalf("truncated to %d of %d bytes: accepted", length, len(valid))
+		}
+	}
+
+	tests := []struct {
+		name string
+		item []byte
+	}{
+		{"indefinite map", []byte{0xbf, 0xff}},
+		{"indefinite string", []byte{0x7f, 0xff}},
+		{"reserved additional info", []byte{0x1c}},
+		{"integer beyond LONG", []byte{0x1b, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{"simple value", []byte{0xe0}},
+		{"unknown tag", []byte{0xc1, 0x00}},
+		{"typed array of odd length", []byte{0xd8, 73, 0x43, 0, 0, 0}},
+		{"typed array of text", []byte{0xd8, 74, 0x60}},
+		{"BYTE overflow", []byte{0xda, 0x53, 0x46, 0x53, 0x02, 0x18, 0x80}},
+		{"SHORT holding text", []byte{0xda, 0x53, 0x46, 0x53, 0x03, 0x60}},
+		{"TEXT holding an integer", []byte{0xda, 0x53, 0x46, 0x53, 0x14, 0x00}},
+		{"BOOL_ARRAY holding an integer", []byte{0xda, 0x53, 0x46, 0x53, 0x09, 0x81, 0x00}},
+		{"UTF_STRING_ARRAY holding an integer", []byte{0xda, 0x53, 0x46, 0x53, 0x10, 0x81, 0x00}},
+		{"private tag for INT", []byte{0xda, 0x53, 0x46, 0x53, 0x04, 0x00}},
+		{"huge byte string", []byte{0x5a, 0xff, 0xff, 0xff, 0xff}},
+		{"huge array", []byte{0x9a, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, test := range tests {
+		data := append([]byte{0xa1, 0x61, 'k'}, test.item...)
+		if _, err := NewSFSObjectFromCBOR(data); err == nil {
+			t.Errorf("%s: accepted", test.name)
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"trailing data": {0xa0, 0x00},
+		"not a map":     {0x80},
+		"integer key":   {0xa1, 0x01, 0x00},
+	} {
+		if _, err := NewSFSObjectFromCBOR(data); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/jannikdc/sfstypes"
+)
+
+type schemaFile struct {
+	Package  string                      `json:"package"`
+	Messages map[string]*sfstypes.Schema `json:"messages"`
+}
+
+// valueType maps an SFS type onto its Go type and the suffix of the
+// SFSObject/SFSArray methods that store and read it.
+type valueType struct {
+	goType string
+	method string
+}
+
+var valueTypes = map[sfstypes.DataType]valueType{
+	sfstypes.TypeBool:           {"bool", "Bool"},
+	sfstypes.TypeByte:           {"int8", "Byte"},
+	sfstypes.TypeShort:          {"int16", "Short"},
+	sfstypes.TypeInt:            {"int32", "Int"},
+	sfstypes.TypeLong:           {"int64", "Long"},
+	sfstypes.TypeFloat:          {"float32", "Float"},
+	sfstypes.TypeDouble:         {"float64", "Double"},
+	sfstypes.TypeUtfString:      {"string", "UtfString"},
+	sfstypes.TypeText:           {"string", "Text"},
+	sfstypes.TypeBoolArray:      {"[]bool", "BoolArray"},
+	sfstypes.TypeByteArray:      {"[]byte", "UnsignedByteArray"},
+	sfstypes.TypeShortArray:     {"[]int16", "ShortArray"},
+	sfstypes.TypeIntArray:       {"[]int32", "IntArray"},
+	sfstypes.TypeLongArray:      {"[]int64", "LongArray"},
+	sfstypes.TypeFloatArray:     {"[]float32", "FloatArray"},
+	sfstypes.TypeDoubleArray:    {"[]float64", "DoubleArray"},
+	sfstypes.TypeUtfStringArray: {"[]string", "UtfStringArray"},
+}
+
+type fieldKind int
+
+const (
+	kindValue fieldKind = iota
+	kindObject
+	kindRawObject
+	kindObjectArray
+	kindValueArray
+	kindRawArray
+)
+
+type field struct {
+	key      string
+	name     string
+	schema   *sfstypes.FieldSchema
+	kind     fieldKind
+	goType   string
+	nested   string
+	value    valueType
+	optional bool
+}
+
+type structType struct {
+	name   string
+	fields []*field
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	types   []*structType
+	reserve map[string]bool
+}
+
+func generate(file *schemaFile) ([]byte, error) {
+	if file.Package == "" {
+		return nil, fmt.Errorf("missing package name")
+	}
+	g := &generator{reserve: make(map[string]bool)}
+	names := make([]string, 0, len(file.Messages))
+	for name := range file.Messages {
+		names = append(names, name)
+		g.reserve[name] = true
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if !isIdentifier(name) {
+			return nil, fmt.Errorf("message name %q is not a valid Go identifier", name)
+		}
+		if err := g.addType(name, file.Messages[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintf(&g.buf, "// Code generated by sfsgen. DO NOT EDIT.\n\npackage %s\n\n", file.Package)
+	fmt.Fprintf(&g.buf, "import \"github.com/jannikdc/sfstypes\"\n")
+	for _, t := range g.types {
+		g.writeStruct(t)
+		g.writeToSFSObject(t)
+		g.writeFromSFSObject(t)
+	}
+	source, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return source, nil
+}
+
+// addType registers a struct type for schema, followed by the types of its
+// nested objects in field order.
+func (g *generator) addType(name string, schema *sfstypes.Schema) error {
+	if schema == nil {
+		return fmt.Errorf("message %s has no schema", name)
+	}
+	t := &structType{name: name}
+	g.types = append(g.types, t)
+	fieldNames := make(map[string]string)
+	keys := make([]string, 0, len(schema.Fields))
+	for key := range schema.Fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		f, err := g.newField(name, key, schema.Fields[key])
+		if err != nil {
+			return err
+		}
+		if other, exists := fieldNames[f.name]; exists {
+			return fmt.Errorf("%s: keys %q and %q both map to field %s", name, other, key, f.name)
+		}
+		fieldNames[f.name] = key
+		t.fields = append(t.fields, f)
+	}
+	for _, f := range t.fields {
+		if f.kind == kindObject {
+			if err := g.addType(f.nested, f.schema.Object); err != nil {
+				return err
+			}
+		} else if f.kind == kindObjectArray {
+			if err := g.addType(f.nested, f.schema.Elements.Object); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) newField(parent string, key string, schema *sfstypes.FieldSchema) (*field, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("%s: field %q has no schema", parent, key)
+	}
+	f := &field{
+		key:      key,
+		name:     goName(key),
+		schema:   schema,
+		optional: !schema.Required || schema.Nullable,
+	}
+	if f.name == "" {
+		return nil, fmt.Errorf("%s: can't derive a field name from key %q", parent, key)
+	}
+	switch schema.Type {
+	case sfstypes.TypeSFSObject:
+		if schema.Object == nil {
+			f.kind = kindRawObject
+			f.goType = "*sfstypes.SFSObject"
+			return f, nil
+		}
+		f.kind = kindObject
+		f.nested = parent + f.name
+		f.goType = "*" + f.nested
+	case sfstypes.TypeSFSArray:
+		elements := schema.Elements
+		switch {
+		case elements != nil && elements.Type == sfstypes.TypeSFSObject && elements.Object != nil:
+			f.kind = kindObjectArray
+			f.nested = parent + f.name + "Element"
+			f.goType = "[]*" + f.nested
+		case elements != nil && valueTypes[elements.Type].method != "":
+			f.kind = kindValueArray
+			f.value = valueTypes[elements.Type]
+			f.goType = "[]" + f.value.goType
+		default:
+			f.kind = kindRawArray
+			f.goType = "*sfstypes.SFSArray"
+			return f, nil
+		}
+	default:
+		value, supported := valueTypes[schema.Type]
+		if !supported {
+			return nil, fmt.Errorf("%s: field %q has unsupported type %s", parent, key, schema.Type.Name())
+		}
+		f.kind = kindValue
+		f.value = value
+		f.goType = value.goType
+		if f.optional && !strings.HasPrefix(f.goType, "[]") {
+			f.goType = "*" + f.goType
+		}
+	}
+	if f.nested != "" {
+		if g.reserve[f.nested] {
+			return nil, fmt.Errorf("%s: generated type name %s for key %q is already in use", parent, f.nested, key)
+		}
+		g.reserve[f.nested] = true
+	}
+	return f, nil
+}
+
+// isNilable reports whether an absent value is represented by nil.
+func (f *field) isNilable() bool {
+	return strings.HasPrefix(f.goType, "*") || strings.HasPrefix(f.goType, "[]")
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) writeStruct(t *structType) {
+	g.printf("\ntype %s struct {\n", t.name)
+	for _, f := range t.fields {
+		g.printf("\t%s %s\n", f.name, f.goType)
+	}
+	g.printf("}\n")
+}
+
+func (g *generator) writeToSFSObject(t *structType) {
+	g.printf("\nfunc (message *%s) ToSFSObject() (*sfstypes.SFSObject, error) {\n", t.name)
+	g.printf("\tsfsobject := sfstypes.NewSFSObject()\n")
+	for _, f := range t.fields {
+		key := fmt.Sprintf("%q", f.key)
+		target := "message." + f.name
+		indent := "\t"
+		if f.isNilable() && (f.optional || f.kind != kindValue) {
+			switch {
+			case f.schema.Nullable:
+				g.printf("\tif %s == nil {\n", target)
+				g.printf("\t\tif err := sfsobject.PutNull(%s); err != nil {\n\t\t\treturn nil, err\n\t\t}\n", key)
+				g.printf("\t} else {\n")
+			case f.schema.Required:
+				g.printf("\tif %s == nil {\n", target)
+				g.printf("\t\treturn nil, &sfstypes.ErrKeyNotFound{Key: %s}\n", key)
+				g.printf("\t} else {\n")
+			default:
+				g.printf("\tif %s != nil {\n", target)
+			}
+			indent = "\t\t"
+		}
+		switch f.kind {
+		case kindValue:
+			if strings.HasPrefix(f.goType, "*") {
+				target = "*" + target
+			}
+			g.printf("%sif err := sfsobject.Put%s(%s, %s); err != nil {\n%s\treturn nil, err\n%s}\n", indent, f.value.method, key, target, indent, indent)
+		case kindRawObject:
+			g.printf("%sif err := sfsobject.PutSFSObject(%s, %s); err != nil {\n%s\treturn nil, err\n%s}\n", indent, key, target, indent, indent)
+		case kindRawArray:
+			g.printf("%sif err := sfsobject.PutSFSArray(%s, %s); err != nil {\n%s\treturn nil, err\n%s}\n", indent, key, target, indent, indent)
+		case kindObject:
+			g.printf("%snested, err := %s.ToSFSObject()\n%sif err != nil {\n%s\treturn nil, err\n%s}\n", indent, target, indent, indent, indent)
+			g.printf("%sif err := sfsobject.PutSFSObject(%s, nested); err != nil {\n%s\treturn nil, err\n%s}\n", indent, key, indent, indent)
+		case kindObjectArray:
+			g.printf("%sarray := sfstypes.NewSFSArray()\n", indent)
+			g.printf("%sfor _, element := range %s {\n", indent, target)
+			g.printf("%s\tnested, err := element.ToSFSObject()\n%s\tif err != nil {\n%s\t\treturn nil, err\n%s\t}\n", indent, indent, indent, indent)
+			g.printf("%s\tarray.AddSFSObject(nested)\n%s}\n", indent, indent)
+			g.printf("%sif err := sfsobject.PutSFSArray(%s, array); err != nil {\n%s\treturn nil, err\n%s}\n", indent, key, indent, indent)
+		case kindValueArray:
+			g.printf("%sarray := sfstypes.NewSFSArray()\n", indent)
+			g.printf("%sfor _, element := range %s {\n%s\tarray.Add%s(element)\n%s}\n", indent, target, indent, f.value.method, indent)
+			g.printf("%sif err := sfsobject.PutSFSArray(%s, array); err != nil {\n%s\treturn nil, err\n%s}\n", indent, key, indent, indent)
+		}
+		if indent != "\t" {
+			g.printf("\t}\n")
+		}
+	}
+	g.printf("\treturn sfsobject, nil\n}\n")
+}
+
+func (g *generator) writeFromSFSObject(t *structType) {
+	g.printf("\nfunc (message *%s) FromSFSObject(sfsobject *sfstypes.SFSObject) error {\n", t.name)
+	g.printf("\t*message = %s{}\n", t.name)
+	for _, f := range t.fields {
+		key := fmt.Sprintf("%q", f.key)
+		target := "message." + f.name
+		indent := "\t\t"
+		g.printf("\tif sfsobject.ContainsKey(%s) {\n", key)
+		if f.schema.Nullable {
+			g.printf("\t\tif isNull, _ := sfsobject.IsNull(%s); !isNull {\n", key)
+			indent = "\t\t\t"
+		}
+		switch f.kind {
+		case kindValue:
+			g.printf("%svalue, err := sfsobject.Get%s(%s)\n%sif err != nil {\n%s\treturn err\n%s}\n", indent, f.value.method, key, indent, indent, indent)
+			if strings.HasPrefix(f.goType, "*") {
+				g.printf("%s%s = &value\n", indent, target)
+			} else {
+				g.printf("%s%s = value\n", indent, target)
+			}
+		case kindRawObject:
+			g.printf("%svalue, err := sfsobject.GetSFSObject(%s)\n%sif err != nil {\n%s\treturn err\n%s}\n", indent, key, indent, indent, indent)
+			g.printf("%s%s = value\n", indent, target)
+		case kindRawArray:
+			g.printf("%svalue, err := sfsobject.GetSFSArray(%s)\n%sif err != nil {\n%s\treturn err\n%s}\n", indent, key, indent, indent, indent)
+			g.printf("%s%s = value\n", indent, target)
+		case kindObject:
+			g.printf("%svalue, err := sfsobject.GetSFSObject(%s)\n%sif err != nil {\n%s\treturn err\n%s}\n", indent, key, indent, indent, indent)
+			g.printf("%s%s = &%s{}\n", indent, target, f.nested)
+			g.printf("%sif err := %s.FromSFSObject(value); err != nil {\n%s\treturn err\n%s}\n", indent, target, indent, indent)
+		case kindObjectArray:
+			g.printf("%sarray, err := sfsobject.GetSFSArray(%s)\n%sif err != nil {\n%s\treturn err\n%s}\n", indent, key, indent, indent, indent)
+			g.printf("%s%s = make(%s, array.Size())\n", indent, target, f.goType)
+			g.printf("%sfor i := range %s {\n", indent, target)
+			g.printf("%s\tvalue, err := array.GetSFSObject(i)\n%s\tif err != nil {\n%s\t\treturn err\n%s\t}\n", indent, indent, indent, indent)
+			g.printf("%s\t%s[i] = &%s{}\n", indent, target, f.nested)
+			g.printf("%s\tif err := %s[i].FromSFSObject(value); err != nil {\n%s\t\treturn err\n%s\t}\n%s}\n", indent, target, indent, indent, indent)
+		case kindValueArray:
+			g.printf("%sarray, err := sfsobject.GetSFSArray(%s)\n%sif err != nil {\n%s\treturn err\n%s}\n", indent, key, indent, indent, indent)
+			g.printf("%s%s = make(%s, array.Size())\n", indent, target, f.goType)
+			g.printf("%sfor i := range %s {\n", indent, target)
+			g.printf("%s\tif %s[i], err = array.Get%s(i); err != nil {\n%s\t\treturn err\n%s\t}\n%s}\n", indent, target, f.value.method, indent, indent, indent)
+		}
+		if f.schema.Nullable {
+			g.printf("\t\t}\n")
+		}
+		if f.schema.Required {
+			g.printf("\t} else {\n\t\treturn &sfstypes.ErrKeyNotFound{Key: %s}\n", key)
+		}
+		g.printf("\t}\n")
+	}
+	g.printf("\treturn nil\n}\n")
+}
+
+// goName turns a key like "user_name" or "user-name" into an exported Go
+// identifier like "UserName".
+func goName(key string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if name.Len() == 0 && unicode.IsDigit(r) {
+			name.WriteString("F")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
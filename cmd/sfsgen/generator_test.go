@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestGenerateGolden(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "messages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := &schemaFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		t.Fatal(err)
+	}
+	source, err := generate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "messages.go.golden")
+	if *update {
+		if err := os.WriteFile(golden, source, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, want) {
+		t.Errorf("generated code differs from %s; run go test -update after checking the change:\n%s", golden, source)
+	}
+}
+
+func TestGenerateRejectsNull(t *testing.T) {
+	file := &schemaFile{}
+	err := json.Unmarshal([]byte(`{"package": "messages", "messages": {"Ping": {"fields": {"nothing": {"type": "NULL"}}}}}`), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generate(file); err == nil || !strings.Contains(err.Error(), "unsupported type NULL") {
+		t.Fatalf("got error %v, want unsupported type NULL", err)
+	}
+}
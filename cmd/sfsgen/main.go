@@ -0,0 +1,73 @@
+// Command sfsgen generates Go structs with ToSFSObject and FromSFSObject
+// methods from a message schema file.
+//
+// The schema file maps message names to sfstypes schemas:
+//
+//	{
+//	    "package": "messages",
+//	    "messages": {
+//	        "Login": {
+//	            "fields": {
+//	                "user": {"type": "UTF_STRING", "required": true},
+//	                "zone": {"type": "UTF_STRING"}
+//	            }
+//	        }
+//	    }
+//	}
+//
+// Usage:
+//
+//	sfsgen -in messages.json -out messages_gen.go [-package name]
+//
+// Optional fields become pointers or nil slices. Fields of type NULL or CLASS
+// have no Go counterpart and are rejected; to allow null for another type,
+// mark the field "nullable".
+//
+// The output only depends on the schema file, so it can be checked in and
+// diffed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	in := flag.String("in", "", "schema file to read")
+	out := flag.String("out", "", "Go file to write (default: stdout)")
+	packageName := flag.String("package", "", "package name of the generated file (overrides the schema file)")
+	flag.Parse()
+
+	if err := run(*in, *out, *packageName); err != nil {
+		fmt.Fprintln(os.Stderr, "sfsgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in string, out string, packageName string) error {
+	if in == "" {
+		return fmt.Errorf("missing -in")
+	}
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	file := &schemaFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+	if packageName != "" {
+		file.Package = packageName
+	}
+	source, err := generate(file)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(out, source, 0o644)
+}
@@ -0,0 +1,162 @@
+package sfstypes
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DefaultCompressionThreshold is the size in bytes above which SFS2X
+// compresses payloads unless configured otherwise.
+const DefaultCompressionThreshold = 1024
+
+// DefaultMaxDecompressedSize limits decompressed payloads when a
+// ZlibCompressor doesn't set MaxDecompressedSize.
+const DefaultMaxDecompressedSize = 10 * 1024 * 1024
+
+// Compressor compresses binary payloads. Compressed data must not start with
+// the type id of an SFSObject or SFSArray (0x12 or 0x11), so that
+// NewSFSObjectFromCompressedDataWith can tell it from uncompressed data;
+// zlib data never does.
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// ZlibCompressor compresses with zlib (RFC 1950), which is what SFS2X uses.
+// The zero value compresses with zlib.DefaultCompression; NewZlibCompressor
+// selects another level.
+type ZlibCompressor struct {
+	level    int
+	hasLevel bool
+	// MaxDecompressedSize limits the size of decompressed data, so a small
+	// corrupt or malicious payload can't exhaust memory. Zero selects
+	// DefaultMaxDecompressedSize.
+	MaxDecompressedSize int
+}
+
+// NewZlibCompressor returns a ZlibCompressor that compresses with level, one
+// of the compress/zlib levels from zlib.HuffmanOnly to zlib.BestCompression,
+// including zlib.NoCompression. Compress reports other levels as an error.
+func NewZlibCompressor(level int) ZlibCompressor {
+	return ZlibCompressor{level: level, hasLevel: true}
+}
+
+// zlibWriterPools holds reusable writers for the levels
+// zlib.HuffmanOnly (-2) to zlib.BestCompression (9).
+var zlibWriterPools [12]sync.Pool
+
+func (compressor ZlibCompressor) Compress(data []byte) ([]byte, error) {
+	level := compressor.level
+	if !compressor.hasLevel {
+		level = zlib.DefaultCompression
+	}
+	if level < zlib.HuffmanOnly || level > zlib.BestCompression {
+		return nil, fmt.Errorf("invalid zlib compression level %d", level)
+	}
+	var buffer bytes.Buffer
+	pool := &zlibWriterPools[level-zlib.HuffmanOnly]
+	writer, pooled := pool.Get().(*zlib.Writer)
+	if pooled {
+		writer.Reset(&buffer)
+	} else {
+		writer, _ = zlib.NewWriterLevel(&buffer, level)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	pool.Put(writer)
+	return buffer.Bytes(), nil
+}
+
+func (compressor ZlibCompressor) Decompress(data []byte) ([]byte, error) {
+	limit := compressor.MaxDecompressedSize
+	if limit <= 0 {
+		limit = DefaultMaxDecompressedSize
+	}
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	result, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > limit {
+		return nil, &ErrDecompressedSizeExceeded{Limit: limit}
+	}
+	return result, nil
+}
+
+// CompressedBinary returns the binary encoding of sfsobject and whether it
+// was compressed. Like SFS2X, it compresses with zlib if the encoding is
+// longer than threshold bytes; the caller marks compressed payloads in the
+// packet header.
+func (sfsobject *SFSObject) CompressedBinary(threshold int) ([]byte, bool, error) {
+	return sfsobject.CompressedBinaryWith(ZlibCompressor{}, threshold)
+}
+
+// CompressedBinaryWith is CompressedBinary with another compressor.
+func (sfsobject *SFSObject) CompressedBinaryWith(compressor Compressor, threshold int) ([]byte, bool, error) {
+	return compressBinary(sfsobject.ToBinary(), compressor, threshold)
+}
+
+func (sfsarray *SFSArray) CompressedBinary(threshold int) ([]byte, bool, error) {
+	return sfsarray.CompressedBinaryWith(ZlibCompressor{}, threshold)
+}
+
+func (sfsarray *SFSArray) CompressedBinaryWith(compressor Compressor, threshold int) ([]byte, bool, error) {
+	return compressBinary(sfsarray.ToBinary(), compressor, threshold)
+}
+
+// NewSFSObjectFromCompressedData decodes a zlib compressed payload, or an
+// uncompressed one, which it recognizes by its leading type id.
+func NewSFSObjectFromCompressedData(data []byte) (*SFSObject, error) {
+	return NewSFSObjectFromCompressedDataWith(data, ZlibCompressor{})
+}
+
+// NewSFSObjectFromCompressedDataWith is NewSFSObjectFromCompressedData with
+// another compressor.
+func NewSFSObjectFromCompressedDataWith(data []byte, compressor Compressor) (*SFSObject, error) {
+	data, err := decompressBinary(data, TypeSFSObject, compressor)
+	if err != nil {
+		return nil, err
+	}
+	return NewSFSObjectFromBinaryData(data)
+}
+
+func NewSFSArrayFromCompressedData(data []byte) (*SFSArray, error) {
+	return NewSFSArrayFromCompressedDataWith(data, ZlibCompressor{})
+}
+
+func NewSFSArrayFromCompressedDataWith(data []byte, compressor Compressor) (*SFSArray, error) {
+	data, err := decompressBinary(data, TypeSFSArray, compressor)
+	if err != nil {
+		return nil, err
+	}
+	return NewSFSArrayFromBinaryData(data)
+}
+
+func compressBinary(data []byte, compressor Compressor, threshold int) ([]byte, bool, error) {
+	if len(data) <= threshold {
+		return data, false, nil
+	}
+	compressed, err := compressor.Compress(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return compressed, true, nil
+}
+
+func decompressBinary(data []byte, typeId DataType, compressor Compressor) ([]byte, error) {
+	if len(data) == 0 || DataType(data[0]) == typeId {
+		return data, nil
+	}
+	return compressor.Decompress(data)
+}
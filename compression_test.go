@@ -0,0 +1,187 @@
+package sfstypes
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompressedBinaryThreshold(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutUtfString("s", strings.Repeat("a", 100))
+	encoded := sfsobject.ToBinary()
+
+	for _, test := range []struct {
+		threshold      int
+		wantCompressed bool
+	}{
+		{len(encoded) + 1, false},
+		{len(encoded), false},
+		{len(encoded) - 1, true},
+		{0, true},
+	} {
+		data, compressed, err := sfsobject.CompressedBinary(test.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if compressed != test.wantCompressed {
+			t.Errorf("threshold %d for %d bytes: compressed = %v", test.threshold, len(encoded), compressed)
+		}
+		if !compressed && !bytes.Equal(data, encoded) {
+			t.Errorf("threshold %d: uncompressed data differs from ToBinary", test.threshold)
+		}
+		decoded, err := NewSFSObjectFromCompressedData(data)
+		if err != nil {
+			t.Fatalf("threshold %d: %v", test.threshold, err)
+		}
+		if !bytes.Equal(decoded.ToBinary(), encoded) {
+			t.Errorf("threshold %d: round trip changed the payload", test.threshold)
+		}
+	}
+
+	sfsarray := NewSFSArray()
+	sfsarray.AddUtfString(strings.Repeat("a", 100))
+	data, compressed, err := sfsarray.CompressedBinary(DefaultCompressionThreshold)
+	if err != nil || compressed {
+		t.Fatalf("small SFSArray: compressed = %v, err = %v", compressed, err)
+	}
+	if data, compressed, err = sfsarray.CompressedBinary(0); err != nil || !compressed {
+		t.Fatalf("SFSArray over threshold: compressed = %v, err = %v", compressed, err)
+	}
+	decoded, err := NewSFSArrayFromCompressedData(data)
+	if err != nil || !bytes.Equal(decoded.ToBinary(), sfsarray.ToBinary()) {
+		t.Fatalf("SFSArray round trip: %v", err)
+	}
+}
+
+func TestZlibCompressorLevels(t *testing.T) {
+	data := bytes.Repeat([]byte("sfs2x "), 500)
+	compressors := []ZlibCompressor{{}}
+	for level := zlib.HuffmanOnly; level <= zlib.BestCompression; level++ {
+		compressors = append(compressors, NewZlibCompressor(level))
+	}
+	sizes := make(map[int]int)
+	for _, compressor := range compressors {
+		// Twice, so the second run uses a pooled writer.
+		for i := 0; i < 2; i++ {
+			compressed, err := compressor.Compress(data)
+			if err != nil {
+				t.Fatalf("level %d: %v", compressor.level, err)
+			}
+			if compressed[0] == byte(TypeSFSObject) || compressed[0] == byte(TypeSFSArray) {
+				t.Errorf("level %d: compressed data starts with a type id", compressor.level)
+			}
+			decompressed, err := compressor.Decompress(compressed)
+			if err != nil || !bytes.Equal(decompressed, data) {
+				t.Fatalf("level %d: round trip failed: %v", compressor.level, err)
+			}
+			if compressor.hasLevel {
+				sizes[compressor.level] = len(compressed)
+			}
+		}
+	}
+	if sizes[zlib.NoCompression] <= len(data) {
+		t.Errorf("NoCompression gave %d bytes for %d bytes of input", sizes[zlib.NoCompression], len(data))
+	}
+	if sizes[zlib.BestCompression] >= len(data)/10 {
+		t.Errorf("BestCompression gave %d bytes for %d bytes of input", sizes[zlib.BestCompression], len(data))
+	}
+
+	for _, level := range []int{zlib.HuffmanOnly - 1, zlib.BestCompression + 1} {
+		if _, err := NewZlibCompressor(level).Compress(data); err == nil {
+			t.Errorf("level %d was accepted", level)
+		}
+	}
+}
+
+func TestDecompressedSizeLimit(t *testing.T) {
+	data := make([]byte, 1000)
+	compressed, err := ZlibCompressor{}.Compress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressor := ZlibCompressor{MaxDecompressedSize: len(data)}
+	if _, err := compressor.Decompress(compressed); err != nil {
+		t.Errorf("data at the limit: %v", err)
+	}
+	compressor.MaxDecompressedSize = len(data) - 1
+	var exceeded *ErrDecompressedSizeExceeded
+	if _, err := compressor.Decompress(compressed); !errors.As(err, &exceeded) || exceeded.Limit != len(data)-1 {
+		t.Errorf("data over the limit: got %v, want ErrDecompressedSizeExceeded", err)
+	}
+
+	// 20 MB of zeros compress to about 20 KB.
+	bomb, err := ZlibCompressor{}.Compress(make([]byte, DefaultMaxDecompressedSize+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSFSObjectFromCompressedData(bomb); !errors.As(err, &exceeded) || exceeded.Limit != DefaultMaxDecompressedSize {
+		t.Errorf("default limit: got %v, want ErrDecompressedSizeExceeded", err)
+	}
+}
+
+func TestCompressedDataDetection(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("k", 1)
+	sfsarray := NewSFSArray()
+	sfsarray.AddInt(1)
+
+	// Uncompressed data is recognized by its first byte and never handed to
+	// the compressor.
+	if _, err := NewSFSObjectFromCompressedDataWith(sfsobject.ToBinary(), failingCompressor{}); err != nil {
+		t.Errorf("uncompressed SFSObject: %v", err)
+	}
+	if _, err := NewSFSArrayFromCompressedDataWith(sfsarray.ToBinary(), failingCompressor{}); err != nil {
+		t.Errorf("uncompressed SFSArray: %v", err)
+	}
+	// An SFSArray isn't an uncompressed SFSObject, so it goes to the
+	// compressor, and the other way round.
+	if _, err := NewSFSObjectFromCompressedDataWith(sfsarray.ToBinary(), failingCompressor{}); !errors.Is(err, errDecompressFailed) {
+		t.Errorf("SFSArray as SFSObject: got %v", err)
+	}
+	if _, err := NewSFSArrayFromCompressedDataWith(sfsobject.ToBinary(), failingCompressor{}); !errors.Is(err, errDecompressFailed) {
+		t.Errorf("SFSObject as SFSArray: got %v", err)
+	}
+	if _, err := NewSFSObjectFromCompressedData([]byte{0x78, 0x9c, 0x00}); err == nil {
+		t.Error("corrupt zlib data was accepted")
+	}
+}
+
+var errDecompressFailed = errors.New("decompress called")
+
+type failingCompressor struct{}
+
+func (failingCompressor) Compress([]byte) ([]byte, error) {
+	return nil, errDecompressFailed
+}
+
+func (failingCompressor) Decompress([]byte) ([]byte, error) {
+	return nil, errDecompressFailed
+}
+
+// TestDecompressesCZlibPayload decodes a payload compressed by the C zlib
+// library that Java's Deflater uses; see testdata/compressed/README.md.
+func TestDecompressesCZlibPayload(t *testing.T) {
+	compressed, err := os.ReadFile(filepath.Join("testdata", "compressed", "room_list.zlib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "compressed", "room_list.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfsobject, err := NewSFSObjectFromCompressedData(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sfsobject.ToBinary(), want) {
+		t.Fatal("decoded payload differs from room_list.bin")
+	}
+	if rooms, _ := sfsobject.GetSFSArray("rooms"); rooms.Size() != 40 {
+		t.Errorf("got %d rooms, want 40", rooms.Size())
+	}
+}
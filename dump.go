@@ -0,0 +1,115 @@
+package sfstypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetDump returns a typed, indented listing of sfsobject in sorted key order,
+// one value per line:
+//
+//	(int) id: 7
+//	(sfs_object) pos:
+//		(float) x: 1.5
+//
+// Strings are quoted and BYTE_ARRAYs are written as hex, so the dump can be
+// compared across implementations.
+func (sfsobject *SFSObject) GetDump() string {
+	var dump strings.Builder
+	dumpSFSObject(&dump, sfsobject, 0, LogOptions{})
+	return dump.String()
+}
+
+// GetDump returns a typed, indented listing of sfsarray like
+// SFSObject.GetDump, without keys.
+func (sfsarray *SFSArray) GetDump() string {
+	var dump strings.Builder
+	dumpSFSArray(&dump, sfsarray, 0, LogOptions{})
+	return dump.String()
+}
+
+// The dump functions apply the truncation and redaction of options, which
+// GetDump leaves empty, for the %+v format.
+
+func dumpSFSObject(dump *strings.Builder, sfsobject *SFSObject, depth int, options LogOptions) {
+	for _, key := range sfsobject.sortedKeys() {
+		wrapper := sfsobject.dataHolder[key]
+		if options.redacts(key) {
+			dump.WriteString(strings.Repeat("\t", depth))
+			dump.WriteString("(" + strings.ToLower(wrapper.typeId.Name()) + ") " + key + ": " + redactedValue + "\n")
+			continue
+		}
+		dumpValue(dump, key+":", wrapper, depth, options)
+	}
+}
+
+func dumpSFSArray(dump *strings.Builder, sfsarray *SFSArray, depth int, options LogOptions) {
+	for index, value := range sfsarray.All() {
+		if options.MaxArrayLength > 0 && index == options.MaxArrayLength {
+			dump.WriteString(strings.Repeat("\t", depth))
+			dump.WriteString(truncationMarker(sfsarray.Size()-index, nil))
+			dump.WriteString("\n")
+			return
+		}
+		dumpValue(dump, "", value.wrapper, depth, options)
+	}
+}
+
+func dumpValue(dump *strings.Builder, label string, wrapper sfsDataWrapper, depth int, options LogOptions) {
+	dump.WriteString(strings.Repeat("\t", depth))
+	dump.WriteString("(" + strings.ToLower(wrapper.typeId.Name()) + ")")
+	if label != "" {
+		dump.WriteString(" " + label)
+	}
+	switch data := wrapper.data.(type) {
+	case *SFSObject:
+		dump.WriteString("\n")
+		dumpSFSObject(dump, data, depth+1, options)
+		return
+	case *SFSArray:
+		dump.WriteString("\n")
+		dumpSFSArray(dump, data, depth+1, options)
+		return
+	case nil:
+	default:
+		data, omitted := options.truncate(data)
+		dump.WriteString(" " + dumpData(data))
+		dump.WriteString(truncationMarker(omitted, data))
+	}
+	dump.WriteString("\n")
+}
+
+func dumpData(data interface{}) string {
+	switch data := data.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(data), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(data, 'g', -1, 64)
+	case string:
+		return strconv.Quote(data)
+	case []byte:
+		return "[" + hex.EncodeToString(data) + "]"
+	case []float32:
+		elements := make([]string, len(data))
+		for i, element := range data {
+			elements[i] = dumpData(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	case []float64:
+		elements := make([]string, len(data))
+		for i, element := range data {
+			elements[i] = dumpData(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	case []string:
+		elements := make([]string, len(data))
+		for i, element := range data {
+			elements[i] = dumpData(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	default:
+		return fmt.Sprint(data)
+	}
+}
@@ -9,67 +9,77 @@ var (
 	ErrKeyEmpty     = errors.New("key is empty")
 	ErrDataNull     = errors.New("input data is null")
 	ErrDecodingBool = errors.New("error decoding bool")
+	ErrFrozen       = errors.New("sfs data is frozen")
 )
 
 type ErrDecodingUnsupportedType struct {
-	sfsType sfsDataType
-	Len     int
-	Cap     int
+	Type DataType
+	Len  int
+	Cap  int
 }
 
 func (err *ErrDecodingUnsupportedType) Error() string {
-	return fmt.Sprintf("can't decode type %s: len: %d cap: %d", sfsTypeToString(err.sfsType), err.Len, err.Cap)
+	return fmt.Sprintf("can't decode type %s: len: %d cap: %d", err.Type.String(), err.Len, err.Cap)
 }
 
 type ErrInsufficientByteData struct {
-	sfsType sfsDataType
-	size    int
+	Type DataType
+	Size int
 }
 
 func (err *ErrInsufficientByteData) Error() string {
-	return fmt.Sprintf("can't decode an %s, byte data is insufficient: size: %d", sfsTypeToString(err.sfsType), err.size)
+	return fmt.Sprintf("can't decode an %s, byte data is insufficient: size: %d", err.Type.String(), err.Size)
 }
 
 type ErrUnsupportedType struct {
-	value interface{}
+	Value interface{}
 }
 
 func (err *ErrUnsupportedType) Error() string {
-	return fmt.Sprintf("value %v, is of unsupported type %t", err.value, err.value)
+	return fmt.Sprintf("value %v, is of unsupported type %T", err.Value, err.Value)
+}
+
+type ErrNumericOverflow struct {
+	Value interface{}
+	Type  DataType
+}
+
+func (err *ErrNumericOverflow) Error() string {
+	return fmt.Sprintf("value %v overflows %s", err.Value, err.Type.String())
 }
 
 type ErrWrongType struct {
-	actualType sfsDataType
-	wantedType sfsDataType
+	ActualType DataType
+	WantedType DataType
 }
 
 func (err *ErrWrongType) Error() string {
-	return fmt.Sprintf("found %s but expected type %s", sfsTypeToString(err.actualType), sfsTypeToString(err.wantedType))
+	return fmt.Sprintf("found %s but expected type %s", err.ActualType.String(), err.WantedType.String())
 }
 
 type ErrKeyNotFound struct {
-	key string
+	Key string
 }
 
 func (err *ErrKeyNotFound) Error() string {
-	return fmt.Sprintf("key \"%s\" not found", err.key)
+	return fmt.Sprintf("key \"%s\" not found", err.Key)
 }
 
 type ErrInvalidKeySize struct {
-	key    string
-	length int
+	Key    string
+	Length int
 }
 
 func (err *ErrInvalidKeySize) Error() string {
-	return fmt.Sprintf("invalid length of key \"%s\" (%d) (key must be >0 and <256)", err.key, err.length)
+	return fmt.Sprintf("invalid length of key \"%s\" (%d) (key must be >0 and <256)", err.Key, err.Length)
 }
 
 type ErrIndexNotInRange struct {
-	index int
+	Index int
 }
 
 func (err *ErrIndexNotInRange) Error() string {
-	return fmt.Sprintf("index %d not in range", err.index)
+	return fmt.Sprintf("index %d not in range", err.Index)
 }
 
 type ErrReadingData struct {
@@ -82,3 +92,19 @@ type ErrReadingData struct {
 func (err *ErrReadingData) Error() string {
 	return fmt.Sprintf("error while reading %s: len: %d cap: %d, ioError: %s", err.TypeToRead, err.Len, err.Cap, err.IoErr)
 }
+
+type ErrMaxDepthExceeded struct {
+	Depth int
+}
+
+func (err *ErrMaxDepthExceeded) Error() string {
+	return fmt.Sprintf("data is nested deeper than %d levels", err.Depth)
+}
+
+type ErrDecompressedSizeExceeded struct {
+	Limit int
+}
+
+func (err *ErrDecompressedSizeExceeded) Error() string {
+	return fmt.Sprintf("decompressed data exceeds %d bytes", err.Limit)
+}
@@ -0,0 +1,364 @@
+package sfstypes
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// FrozenSFSObject is a read-only view of an SFSObject. Nested containers
+// reached through it are frozen as well and array values are returned as
+// copies, so holders of the view cannot change the underlying data. The view
+// does not copy anything up front: the owner must stop modifying the frozen
+// object, after which the view is safe for concurrent use.
+type FrozenSFSObject struct {
+	object *SFSObject
+}
+
+// FrozenSFSArray is the SFSArray counterpart of FrozenSFSObject.
+type FrozenSFSArray struct {
+	array *SFSArray
+}
+
+func (sfsobject *SFSObject) Freeze() *FrozenSFSObject {
+	return &FrozenSFSObject{object: sfsobject}
+}
+
+func (sfsarray *SFSArray) Freeze() *FrozenSFSArray {
+	return &FrozenSFSArray{array: sfsarray}
+}
+
+func frozenData(wrapper *sfsDataWrapper) interface{} {
+	switch data := wrapper.data.(type) {
+	case *SFSObject:
+		return data.Freeze()
+	case *SFSArray:
+		return data.Freeze()
+	default:
+		copied := wrapper.deepCopy()
+		return copied.interfaceValue()
+	}
+}
+
+func (frozen *FrozenSFSObject) ToBinary() []byte {
+	return frozen.object.ToBinary()
+}
+
+func (frozen *FrozenSFSObject) ToJson() string {
+	return frozen.object.ToJson()
+}
+
+func (frozen *FrozenSFSObject) GetHexDump() string {
+	return frozen.object.GetHexDump()
+}
+
+func (frozen *FrozenSFSObject) LogValue() slog.Value {
+	return frozen.object.LogValue()
+}
+
+func (frozen *FrozenSFSObject) Size() int {
+	return frozen.object.Size()
+}
+
+func (frozen *FrozenSFSObject) GetKeys() []string {
+	return frozen.object.GetKeys()
+}
+
+func (frozen *FrozenSFSObject) ContainsKey(key string) bool {
+	return frozen.object.ContainsKey(key)
+}
+
+func (frozen *FrozenSFSObject) IsNull(key string) (bool, error) {
+	return frozen.object.IsNull(key)
+}
+
+func (frozen *FrozenSFSObject) TypeOf(key string) (DataType, error) {
+	return frozen.object.TypeOf(key)
+}
+
+// Copy returns a mutable deep copy of the frozen object.
+func (frozen *FrozenSFSObject) Copy() *SFSObject {
+	return frozen.object.deepCopy()
+}
+
+func (frozen *FrozenSFSObject) Put(key string, value interface{}) error {
+	return ErrFrozen
+}
+
+func (frozen *FrozenSFSObject) RemoveElement(key string) error {
+	return ErrFrozen
+}
+
+func (frozen *FrozenSFSObject) Get(key string) (interface{}, error) {
+	wrapper, err := frozen.object.getWrapper(key)
+	if err != nil {
+		return nil, err
+	}
+	return frozenData(wrapper), nil
+}
+
+func (frozen *FrozenSFSObject) GetBool(key string) (bool, error) {
+	return frozen.object.GetBool(key)
+}
+
+func (frozen *FrozenSFSObject) GetBoolArray(key string) ([]bool, error) {
+	value, err := frozen.object.GetBoolArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetByte(key string) (int8, error) {
+	return frozen.object.GetByte(key)
+}
+
+func (frozen *FrozenSFSObject) GetByteArray(key string) ([]int8, error) {
+	value, err := frozen.object.GetByteArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetDouble(key string) (float64, error) {
+	return frozen.object.GetDouble(key)
+}
+
+func (frozen *FrozenSFSObject) GetDoubleArray(key string) ([]float64, error) {
+	value, err := frozen.object.GetDoubleArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetFloat(key string) (float32, error) {
+	return frozen.object.GetFloat(key)
+}
+
+func (frozen *FrozenSFSObject) GetFloatArray(key string) ([]float32, error) {
+	value, err := frozen.object.GetFloatArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetInt(key string) (int32, error) {
+	return frozen.object.GetInt(key)
+}
+
+func (frozen *FrozenSFSObject) GetIntArray(key string) ([]int32, error) {
+	value, err := frozen.object.GetIntArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetLong(key string) (int64, error) {
+	return frozen.object.GetLong(key)
+}
+
+func (frozen *FrozenSFSObject) GetLongArray(key string) ([]int64, error) {
+	value, err := frozen.object.GetLongArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetSFSArray(key string) (*FrozenSFSArray, error) {
+	value, err := frozen.object.GetSFSArray(key)
+	if err != nil {
+		return nil, err
+	}
+	return value.Freeze(), nil
+}
+
+func (frozen *FrozenSFSObject) GetSFSArrayCopy(key string) (*SFSArray, error) {
+	return frozen.object.GetSFSArrayCopy(key)
+}
+
+func (frozen *FrozenSFSObject) GetSFSObject(key string) (*FrozenSFSObject, error) {
+	value, err := frozen.object.GetSFSObject(key)
+	if err != nil {
+		return nil, err
+	}
+	return value.Freeze(), nil
+}
+
+func (frozen *FrozenSFSObject) GetSFSObjectCopy(key string) (*SFSObject, error) {
+	return frozen.object.GetSFSObjectCopy(key)
+}
+
+func (frozen *FrozenSFSObject) GetShort(key string) (int16, error) {
+	return frozen.object.GetShort(key)
+}
+
+func (frozen *FrozenSFSObject) GetShortArray(key string) ([]int16, error) {
+	value, err := frozen.object.GetShortArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetUnsignedByte(key string) (uint8, error) {
+	return frozen.object.GetUnsignedByte(key)
+}
+
+func (frozen *FrozenSFSObject) GetUnsignedByteArray(key string) ([]uint8, error) {
+	value, err := frozen.object.GetUnsignedByteArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSObject) GetUtfString(key string) (string, error) {
+	return frozen.object.GetUtfString(key)
+}
+
+func (frozen *FrozenSFSObject) GetText(key string) (string, error) {
+	return frozen.object.GetText(key)
+}
+
+func (frozen *FrozenSFSObject) GetUtfStringArray(key string) ([]string, error) {
+	value, err := frozen.object.GetUtfStringArray(key)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) ToBinary() []byte {
+	return frozen.array.ToBinary()
+}
+
+func (frozen *FrozenSFSArray) ToJson() string {
+	return frozen.array.ToJson()
+}
+
+func (frozen *FrozenSFSArray) GetHexDump() string {
+	return frozen.array.GetHexDump()
+}
+
+func (frozen *FrozenSFSArray) LogValue() slog.Value {
+	return frozen.array.LogValue()
+}
+
+func (frozen *FrozenSFSArray) Size() int {
+	return frozen.array.Size()
+}
+
+func (frozen *FrozenSFSArray) IsNull(index int) (bool, error) {
+	return frozen.array.IsNull(index)
+}
+
+func (frozen *FrozenSFSArray) TypeOf(index int) (DataType, error) {
+	return frozen.array.TypeOf(index)
+}
+
+// Copy returns a mutable deep copy of the frozen array.
+func (frozen *FrozenSFSArray) Copy() *SFSArray {
+	return frozen.array.deepCopy()
+}
+
+func (frozen *FrozenSFSArray) Add(value interface{}) error {
+	return ErrFrozen
+}
+
+func (frozen *FrozenSFSArray) RemoveElementAt(index int) error {
+	return ErrFrozen
+}
+
+func (frozen *FrozenSFSArray) Get(index int) (interface{}, error) {
+	wrapper, err := frozen.array.getWrapper(index)
+	if err != nil {
+		return nil, err
+	}
+	return frozenData(wrapper), nil
+}
+
+func (frozen *FrozenSFSArray) GetBool(index int) (bool, error) {
+	return frozen.array.GetBool(index)
+}
+
+func (frozen *FrozenSFSArray) GetBoolArray(index int) ([]bool, error) {
+	value, err := frozen.array.GetBoolArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetByte(index int) (int8, error) {
+	return frozen.array.GetByte(index)
+}
+
+func (frozen *FrozenSFSArray) GetByteArray(index int) ([]int8, error) {
+	value, err := frozen.array.GetByteArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetDouble(index int) (float64, error) {
+	return frozen.array.GetDouble(index)
+}
+
+func (frozen *FrozenSFSArray) GetDoubleArray(index int) ([]float64, error) {
+	value, err := frozen.array.GetDoubleArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetFloat(index int) (float32, error) {
+	return frozen.array.GetFloat(index)
+}
+
+func (frozen *FrozenSFSArray) GetFloatArray(index int) ([]float32, error) {
+	value, err := frozen.array.GetFloatArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetInt(index int) (int32, error) {
+	return frozen.array.GetInt(index)
+}
+
+func (frozen *FrozenSFSArray) GetIntArray(index int) ([]int32, error) {
+	value, err := frozen.array.GetIntArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetLong(index int) (int64, error) {
+	return frozen.array.GetLong(index)
+}
+
+func (frozen *FrozenSFSArray) GetLongArray(index int) ([]int64, error) {
+	value, err := frozen.array.GetLongArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetSFSArray(index int) (*FrozenSFSArray, error) {
+	value, err := frozen.array.GetSFSArray(index)
+	if err != nil {
+		return nil, err
+	}
+	return value.Freeze(), nil
+}
+
+func (frozen *FrozenSFSArray) GetSFSArrayCopy(index int) (*SFSArray, error) {
+	return frozen.array.GetSFSArrayCopy(index)
+}
+
+func (frozen *FrozenSFSArray) GetSFSObject(index int) (*FrozenSFSObject, error) {
+	value, err := frozen.array.GetSFSObject(index)
+	if err != nil {
+		return nil, err
+	}
+	return value.Freeze(), nil
+}
+
+func (frozen *FrozenSFSArray) GetSFSObjectCopy(index int) (*SFSObject, error) {
+	return frozen.array.GetSFSObjectCopy(index)
+}
+
+func (frozen *FrozenSFSArray) GetShort(index int) (int16, error) {
+	return frozen.array.GetShort(index)
+}
+
+func (frozen *FrozenSFSArray) GetShortArray(index int) ([]int16, error) {
+	value, err := frozen.array.GetShortArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetUnsignedByte(index int) (uint8, error) {
+	return frozen.array.GetUnsignedByte(index)
+}
+
+func (frozen *FrozenSFSArray) GetUnsignedByteArray(index int) ([]uint8, error) {
+	value, err := frozen.array.GetUnsignedByteArray(index)
+	return slices.Clone(value), err
+}
+
+func (frozen *FrozenSFSArray) GetUtfString(index int) (string, error) {
+	return frozen.array.GetUtfString(index)
+}
+
+func (frozen *FrozenSFSArray) GetText(index int) (string, error) {
+	return frozen.array.GetText(index)
+}
+
+func (frozen *FrozenSFSArray) GetUtfStringArray(index int) ([]string, error) {
+	value, err := frozen.array.GetUtfStringArray(index)
+	return slices.Clone(value), err
+}
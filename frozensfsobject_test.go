@@ -0,0 +1,85 @@
+package sfstypes
+
+import (
+	"errors"
+	"testing"
+)
+
+func frozenTestObject() *SFSObject {
+	nested := NewSFSObject()
+	nested.PutInt("score", 42)
+	array := NewSFSArray()
+	array.AddSFSObject(NewSFSObject())
+	sfsobject := NewSFSObject()
+	sfsobject.PutSFSObject("nested", nested)
+	sfsobject.PutSFSArray("array", array)
+	sfsobject.PutIntArray("ints", []int32{1, 2, 3})
+	return sfsobject
+}
+
+func TestFrozenMutatorsReturnErrFrozen(t *testing.T) {
+	frozen := frozenTestObject().Freeze()
+	nested, err := frozen.GetSFSObject("nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	array, err := frozen.GetSFSArray("array")
+	if err != nil {
+		t.Fatal(err)
+	}
+	element, err := array.GetSFSObject(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mutators := map[string]func() error{
+		"Put":                   func() error { return frozen.Put("key", int32(1)) },
+		"RemoveElement":         func() error { return frozen.RemoveElement("nested") },
+		"nested Put":            func() error { return nested.Put("score", int32(0)) },
+		"nested RemoveElement":  func() error { return nested.RemoveElement("score") },
+		"array Add":             func() error { return array.Add(int32(1)) },
+		"array RemoveElementAt": func() error { return array.RemoveElementAt(0) },
+		"array element Put":     func() error { return element.Put("key", int32(1)) },
+	}
+	for name, mutate := range mutators {
+		if err := mutate(); !errors.Is(err, ErrFrozen) {
+			t.Errorf("%s: got %v, want ErrFrozen", name, err)
+		}
+	}
+}
+
+func TestFrozenGetReturnsFrozenContainers(t *testing.T) {
+	frozen := frozenTestObject().Freeze()
+	value, err := frozen.Get("nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := value.(*FrozenSFSObject); !ok {
+		t.Fatalf("Get returned %T, want *FrozenSFSObject", value)
+	}
+	value, err = frozen.Get("array")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := value.(*FrozenSFSArray); !ok {
+		t.Fatalf("Get returned %T, want *FrozenSFSArray", value)
+	}
+}
+
+func TestFrozenArraysAreCopies(t *testing.T) {
+	sfsobject := frozenTestObject()
+	frozen := sfsobject.Freeze()
+	ints, err := frozen.GetIntArray("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ints[0] = 100
+	if original, _ := sfsobject.GetIntArray("ints"); original[0] != 1 {
+		t.Fatal("change to a returned array is visible in the frozen object")
+	}
+
+	copied := frozen.Copy()
+	copied.PutInt("key", 1)
+	if frozen.ContainsKey("key") {
+		t.Fatal("change to Copy is visible in the frozen object")
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/jannikdc/sfstypes
 
-go 1.21.5
+go 1.23
+
+require google.golang.org/protobuf v1.36.9
@@ -0,0 +1,115 @@
+package sfstypes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readGoldenCorpus returns the payloads in testdata/golden by name.
+func readGoldenCorpus(tb testing.TB) map[string][]byte {
+	tb.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "golden", "*.bin"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if len(paths) == 0 {
+		tb.Fatal("no golden payloads found")
+	}
+	corpus := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		corpus[strings.TrimSuffix(filepath.Base(path), ".bin")] = data
+	}
+	return corpus
+}
+
+// TestGolden checks the properties listed in testdata/golden/README.md.
+func TestGolden(t *testing.T) {
+	for name, data := range readGoldenCorpus(t) {
+		t.Run(name, func(t *testing.T) {
+			dump, err := os.ReadFile(filepath.Join("testdata", "golden", name+".dump"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sfsobject, err := NewSFSObjectFromBinaryData(data)
+			if err != nil {
+				t.Fatalf("decoding: %v", err)
+			}
+			if got := sfsobject.GetDump(); got != string(dump) {
+				t.Errorf("GetDump differs from %s.dump:\ngot:\n%s\nwant:\n%s", name, got, dump)
+			}
+			hexDump, err := os.ReadFile(filepath.Join("testdata", "golden", name+".hexdump"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got strings.Builder
+			if err := WriteHexDump(&got, data); err != nil || got.String() != string(hexDump) {
+				t.Errorf("WriteHexDump differs from %s.hexdump:\ngot (%v):\n%s\nwant:\n%s", name, err, got.String(), hexDump)
+			}
+			if got := sfsobject.ToBinary(); !bytes.Equal(got, data) {
+				t.Errorf("ToBinary differs from %s.bin:\ngot  %x\nwant %x", name, got, data)
+			}
+			if got := sfsobject.deepCopy().ToBinary(); !bytes.Equal(got, data) {
+				t.Errorf("ToBinary of a copy differs from %s.bin:\ngot  %x\nwant %x", name, got, data)
+			}
+
+			lazy, err := NewLazySFSObject(data)
+			if err != nil {
+				t.Fatalf("lazy decoding: %v", err)
+			}
+			for _, key := range lazy.GetKeys() {
+				if _, err := lazy.Get(key); err != nil {
+					t.Fatalf("lazy Get(%q): %v", key, err)
+				}
+			}
+			if got, err := lazy.ToBinary(); err != nil || !bytes.Equal(got, data) {
+				t.Errorf("lazy ToBinary differs from %s.bin:\ngot  %x, %v\nwant %x", name, got, err, data)
+			}
+		})
+	}
+}
+
+func TestEncodingIsCanonical(t *testing.T) {
+	keys := []string{"b", "a", "ab", "B", "é"}
+	forward, backward := NewSFSObject(), NewSFSObject()
+	for i := range keys {
+		forward.PutInt(keys[i], int32(i))
+		backward.PutInt(keys[len(keys)-1-i], int32(len(keys)-1-i))
+	}
+	encoded := forward.ToBinary()
+	if !bytes.Equal(backward.ToBinary(), encoded) {
+		t.Fatalf("insertion order changed the encoding:\n%x\n%x", encoded, backward.ToBinary())
+	}
+	want := []byte{0x12, 0x00, 0x05,
+		0x00, 0x01, 'B', 0x04, 0x00, 0x00, 0x00, 0x03,
+		0x00, 0x01, 'a', 0x04, 0x00, 0x00, 0x00, 0x01,
+		0x00, 0x02, 'a', 'b', 0x04, 0x00, 0x00, 0x00, 0x02,
+		0x00, 0x01, 'b', 0x04, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x02, 0xc3, 0xa9, 0x04, 0x00, 0x00, 0x00, 0x04,
+	}
+	if !bytes.Equal(encoded, want) {
+		t.Fatalf("keys aren't sorted by their bytes:\ngot  %x\nwant %x", encoded, want)
+	}
+}
+
+func TestJsonImportIsDeterministic(t *testing.T) {
+	const input = `{"z": 1, "a": {"y": [1, 2], "b": "x"}, "m": [{"k": true, "c": null}]}`
+	var first []byte
+	for i := 0; i < 20; i++ {
+		sfsobject, err := NewSFSObjectFromJsonData(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded := sfsobject.ToBinary(); first == nil {
+			first = encoded
+		} else if !bytes.Equal(encoded, first) {
+			t.Fatalf("import %d encoded differently:\n%x\n%x", i, first, encoded)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package sfstypes
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The hex dump lists binary data in spans, one value each, with the offset,
+// up to 16 bytes of hex and ASCII per line and what the span means:
+//
+//	00000000  12 00 02                                          |...             |  type=SFS_OBJECT size=2
+//	00000003  00 05 73 63 6f 72 65 04  00 00 00 2a              |..score....*    |    type=INT key='score' value=42
+//	0000000f  00 04 6e 61 6d 65 08 00  06 50 6c 61 79 65 72     |..name...Player |    type=UTF_STRING key='name' length=6 value="Player"
+//
+// Values of an entry include its key. Long strings and arrays are shortened
+// in the annotation but every byte is listed. Annotations of values under the
+// keys LogValue redacts show [REDACTED] instead of the value; the bytes are
+// still listed.
+
+const hexDigits = "0123456789abcdef"
+
+// hexDumpValueOptions shortens values in annotations.
+var hexDumpValueOptions = LogOptions{MaxStringLength: 32, MaxArrayLength: 8}
+
+// GetHexDump returns the annotated hex dump of the binary encoding of
+// sfsobject.
+func (sfsobject *SFSObject) GetHexDump() string {
+	var dump strings.Builder
+	sfsobject.WriteHexDump(&dump)
+	return dump.String()
+}
+
+// WriteHexDump writes the annotated hex dump of the binary encoding of
+// sfsobject to w.
+func (sfsobject *SFSObject) WriteHexDump(w io.Writer) error {
+	return WriteHexDump(w, sfsobject.ToBinary())
+}
+
+func (sfsarray *SFSArray) GetHexDump() string {
+	var dump strings.Builder
+	sfsarray.WriteHexDump(&dump)
+	return dump.String()
+}
+
+func (sfsarray *SFSArray) WriteHexDump(w io.Writer) error {
+	return WriteHexDump(w, sfsarray.ToBinary())
+}
+
+// WriteHexDump writes an annotated hex dump of SFS binary data to w. Corrupt
+// data is annotated up to the first value that can't be decoded; the error is
+// noted and the remaining bytes are listed without annotations. It returns
+// the first error of w, or else the decoding error.
+func WriteHexDump(w io.Writer, data []byte) error {
+	dumper := &hexDumper{w: w, decoder: sfsDecoder{data: data}}
+	err := dumper.dumpValue("", false, 0)
+	if err == nil && dumper.decoder.remaining() > 0 {
+		err = fmt.Errorf("%d trailing bytes", dumper.decoder.remaining())
+	}
+	if err != nil {
+		dumper.writeSpan(len(data), "error: "+err.Error(), 0)
+	}
+	if dumper.err != nil {
+		return dumper.err
+	}
+	return err
+}
+
+type hexDumper struct {
+	w       io.Writer
+	decoder sfsDecoder
+	// written is the offset up to which spans have been written.
+	written int
+	line    []byte
+	err     error
+}
+
+// dumpValue annotates the value at the decoder offset. Its span starts where
+// the last one ended, which includes the key of an entry. The values of
+// redacted containers are redacted as well.
+func (dumper *hexDumper) dumpValue(label string, redacted bool, depth int) error {
+	decoder := &dumper.decoder
+	if decoder.remaining() == 0 {
+		return decoder.readError("value header")
+	}
+	typeId := DataType(decoder.data[decoder.offset])
+	if typeId != TypeSFSObject && typeId != TypeSFSArray {
+		wrapper, err := decoder.decodeData()
+		if err != nil {
+			return err
+		}
+		dumper.writeSpan(decoder.offset, hexDumpAnnotation(typeId, label, wrapper, redacted), depth)
+		return nil
+	}
+	decoder.offset++
+	size, err := decoder.readUint16(typeId.String() + " size")
+	if err != nil {
+		return err
+	}
+	dumper.writeSpan(decoder.offset, fmt.Sprintf("type=%s%s size=%d", typeId.Name(), label, size), depth)
+	if err := decoder.enter(); err != nil {
+		return err
+	}
+	defer decoder.leave()
+	for i := 0; i < int(size); i++ {
+		label := " index=" + strconv.Itoa(i)
+		redactEntry := redacted
+		if typeId == TypeSFSObject {
+			keySize, err := decoder.readUint16("key size")
+			if err != nil {
+				return err
+			}
+			if keySize > 255 {
+				return &ErrInvalidKeySize{Key: "", Length: int(keySize)}
+			}
+			key, err := decoder.read(int(keySize), "key")
+			if err != nil {
+				return err
+			}
+			quoted := strconv.Quote(string(key))
+			label = " key='" + quoted[1:len(quoted)-1] + "'"
+			redactEntry = redacted || defaultLogOptions.redacts(string(key))
+		}
+		if err := dumper.dumpValue(label, redactEntry, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hexDumpAnnotation(typeId DataType, label string, wrapper sfsDataWrapper, redacted bool) string {
+	annotation := "type=" + typeId.Name() + label
+	if wrapper.data == nil {
+		return annotation
+	}
+	if redacted {
+		return annotation + " value=" + redactedValue
+	}
+	if value := reflect.ValueOf(wrapper.data); value.Kind() == reflect.String {
+		annotation += " length=" + strconv.Itoa(value.Len())
+	} else if value.Kind() == reflect.Slice {
+		annotation += " count=" + strconv.Itoa(value.Len())
+	}
+	data, omitted := hexDumpValueOptions.truncate(wrapper.data)
+	return annotation + " value=" + dumpData(data) + truncationMarker(omitted, data)
+}
+
+// writeSpan writes the bytes from the end of the last span up to end, with
+// the annotation on the first line.
+func (dumper *hexDumper) writeSpan(end int, annotation string, depth int) {
+	data := dumper.decoder.data
+	for offset := dumper.written; offset < end || offset == dumper.written; offset += 16 {
+		chunk := data[offset:min(offset+16, end)]
+		line := fmt.Appendf(dumper.line[:0], "%08x  ", offset)
+		for i := 0; i < 16; i++ {
+			if i == 8 {
+				line = append(line, ' ')
+			}
+			if i < len(chunk) {
+				line = append(line, hexDigits[chunk[i]>>4], hexDigits[chunk[i]&0x0f], ' ')
+			} else {
+				line = append(line, "   "...)
+			}
+		}
+		line = append(line, " |"...)
+		for i := 0; i < 16; i++ {
+			switch {
+			case i >= len(chunk):
+				line = append(line, ' ')
+			case chunk[i] >= 0x20 && chunk[i] < 0x7f:
+				line = append(line, chunk[i])
+			default:
+				line = append(line, '.')
+			}
+		}
+		line = append(line, '|')
+		if offset == dumper.written && annotation != "" {
+			line = append(line, "  "...)
+			line = append(line, strings.Repeat("  ", depth)...)
+			line = append(line, annotation...)
+		}
+		line = append(line, '\n')
+		dumper.line = line
+		if dumper.err == nil {
+			_, dumper.err = dumper.w.Write(line)
+		}
+		if len(chunk) < 16 {
+			break
+		}
+	}
+	dumper.written = end
+}
@@ -0,0 +1,124 @@
+package sfstypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// hexDumpBytes reads the bytes back from the hex columns of a hex dump.
+func hexDumpBytes(tb testing.TB, dump string) []byte {
+	tb.Helper()
+	var data []byte
+	for _, line := range strings.Split(strings.TrimSuffix(dump, "\n"), "\n") {
+		columns := strings.ReplaceAll(line[10:59], " ", "")
+		decoded, err := hex.DecodeString(columns)
+		if err != nil {
+			tb.Fatalf("%q: %v", line, err)
+		}
+		data = append(data, decoded...)
+	}
+	return data
+}
+
+func TestHexDump(t *testing.T) {
+	nested := NewSFSObject()
+	nested.PutUtfString("pw", "x")
+	sfsarray := NewSFSArray()
+	sfsarray.AddByte(1)
+	sfsarray.AddSFSObject(nested)
+
+	want := "" +
+		"00000000  11 00 02                                          |...             |  type=SFS_ARRAY size=2\n" +
+		"00000003  02 01                                             |..              |    type=BYTE index=0 value=1\n" +
+		"00000005  12 00 01                                          |...             |    type=SFS_OBJECT index=1 size=1\n" +
+		"00000008  00 02 70 77 08 00 01 78                           |..pw...x        |      type=UTF_STRING key='pw' value=[REDACTED]\n"
+	if got := sfsarray.GetHexDump(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	var output strings.Builder
+	if err := sfsarray.WriteHexDump(&output); err != nil || output.String() != want {
+		t.Fatalf("WriteHexDump: %v\n%s", err, output.String())
+	}
+}
+
+func TestHexDumpRedactsNestedValues(t *testing.T) {
+	token := NewSFSObject()
+	token.PutIntArray("a", []int32{1})
+	sfsobject := NewSFSObject()
+	sfsobject.PutUtfString("pw", "secret")
+	sfsobject.PutSFSObject("Token", token)
+
+	want := "" +
+		"00000000  12 00 02                                          |...             |  type=SFS_OBJECT size=2\n" +
+		"00000003  00 05 54 6f 6b 65 6e 12  00 01                    |..Token...      |    type=SFS_OBJECT key='Token' size=1\n" +
+		"0000000d  00 01 61 0c 00 01 00 00  00 01                    |..a.......      |      type=INT_ARRAY key='a' value=[REDACTED]\n" +
+		"00000017  00 02 70 77 08 00 06 73  65 63 72 65 74           |..pw...secret   |    type=UTF_STRING key='pw' value=[REDACTED]\n"
+	if got := sfsobject.GetHexDump(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHexDumpOfCorruptData(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			"truncated value",
+			[]byte{0x12, 0, 1, 0, 1, 'k', 4, 0, 0},
+			"00000000  12 00 01                                          |...             |  type=SFS_OBJECT size=1\n" +
+				"00000003  00 01 6b 04 00 00                                 |..k...          |  error: error while reading int: len: 2 cap: 9, ioError: unexpected EOF\n",
+		},
+		{
+			"trailing bytes",
+			[]byte{0x12, 0, 0, 0xaa, 0xbb},
+			"00000000  12 00 00                                          |...             |  type=SFS_OBJECT size=0\n" +
+				"00000003  aa bb                                             |..              |  error: 2 trailing bytes\n",
+		},
+		{
+			"unsupported type",
+			[]byte{0x12, 0, 1, 0, 1, 'k', 19, 0},
+			"00000000  12 00 01                                          |...             |  type=SFS_OBJECT size=1\n" +
+				"00000003  00 01 6b 13 00                                    |..k..           |  error: can't decode type CLASS (unsupported): len: 1 cap: 8\n",
+		},
+	}
+	for _, test := range tests {
+		var output strings.Builder
+		err := WriteHexDump(&output, test.data)
+		if err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+		if output.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, output.String(), test.want)
+		}
+	}
+
+	data := readGoldenCorpus(t)["message"]
+	for length := 0; length < len(data); length++ {
+		var output strings.Builder
+		if err := WriteHexDump(&output, data[:length]); err == nil {
+			t.Fatalf("truncated to %d of %d bytes: no error", length, len(data))
+		}
+		if got := hexDumpBytes(t, output.String()); !bytes.Equal(got, data[:length]) {
+			t.Fatalf("truncated to %d bytes: dump lists %x", length, got)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+var errWriteFailed = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWriteFailed
+}
+
+func TestHexDumpReturnsWriteErrors(t *testing.T) {
+	if err := WriteHexDump(failingWriter{}, []byte{0x12, 0, 0, 0xff}); !errors.Is(err, errWriteFailed) {
+		t.Fatalf("got %v, want the error of the writer", err)
+	}
+}
@@ -0,0 +1,78 @@
+package sfstypes
+
+import (
+	"iter"
+	"strconv"
+)
+
+// All returns an iterator over the keys and values of sfsobject. The object
+// must not be modified while iterating.
+func (sfsobject *SFSObject) All() iter.Seq2[string, Value] {
+	return func(yield func(string, Value) bool) {
+		for key, wrapper := range sfsobject.dataHolder {
+			if !yield(key, Value{wrapper: wrapper}) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the indexes and values of sfsarray. The array
+// must not be modified while iterating.
+func (sfsarray *SFSArray) All() iter.Seq2[int, Value] {
+	return func(yield func(int, Value) bool) {
+		for index, wrapper := range sfsarray.dataHolder {
+			if !yield(index, Value{wrapper: wrapper}) {
+				return
+			}
+		}
+	}
+}
+
+// WalkFunc is called by Walk for every value. path names the value relative
+// to the walked container, e.g. "player.items[2].id". Returning an error stops
+// the walk and makes Walk return that error.
+type WalkFunc func(path string, value Value) error
+
+// Walk visits every value of sfsobject recursively. Containers are visited
+// before their contents.
+func (sfsobject *SFSObject) Walk(fn WalkFunc) error {
+	return walkSFSObject("", sfsobject, fn)
+}
+
+// Walk visits every value of sfsarray recursively. Containers are visited
+// before their contents.
+func (sfsarray *SFSArray) Walk(fn WalkFunc) error {
+	return walkSFSArray("", sfsarray, fn)
+}
+
+func walkSFSObject(prefix string, sfsobject *SFSObject, fn WalkFunc) error {
+	for key, value := range sfsobject.All() {
+		if err := walkValue(joinPath(prefix, key), value, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkSFSArray(prefix string, sfsarray *SFSArray, fn WalkFunc) error {
+	for index, value := range sfsarray.All() {
+		if err := walkValue(prefix+"["+strconv.Itoa(index)+"]", value, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkValue(path string, value Value, fn WalkFunc) error {
+	if err := fn(path, value); err != nil {
+		return err
+	}
+	switch data := value.wrapper.data.(type) {
+	case *SFSObject:
+		return walkSFSObject(path, data, fn)
+	case *SFSArray:
+		return walkSFSArray(path, data, fn)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package sfstypes
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("a", 1)
+	sfsobject.PutUtfString("b", "two")
+	got := map[string]interface{}{}
+	for key, value := range sfsobject.All() {
+		got[key] = value.Interface()
+	}
+	if len(got) != 2 || got["a"] != int32(1) || got["b"] != "two" {
+		t.Fatalf("got %v", got)
+	}
+
+	sfsarray := NewSFSArray()
+	sfsarray.AddInt(10)
+	sfsarray.AddInt(20)
+	var indexes []int
+	for index := range sfsarray.All() {
+		indexes = append(indexes, index)
+		break
+	}
+	if !slices.Equal(indexes, []int{0}) {
+		t.Fatalf("iteration didn't stop at break: %v", indexes)
+	}
+}
+
+func TestWalkPaths(t *testing.T) {
+	item := NewSFSObject()
+	item.PutInt("c", 3)
+	items := NewSFSArray()
+	items.AddInt(0)
+	items.AddNull()
+	items.AddSFSObject(item)
+	b := NewSFSObject()
+	b.PutSFSArray("b", items)
+	sfsobject := NewSFSObject()
+	sfsobject.PutSFSObject("a", b)
+
+	var paths []string
+	err := sfsobject.Walk(func(path string, value Value) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "a.b", "a.b[0]", "a.b[1]", "a.b[2]", "a.b[2].c"}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("got paths %q, want %q", paths, want)
+	}
+
+	paths = nil
+	err = items.Walk(func(path string, value Value) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"[0]", "[1]", "[2]", "[2].c"}; !slices.Equal(paths, want) {
+		t.Fatalf("got paths %q, want %q", paths, want)
+	}
+}
+
+func TestWalkStops(t *testing.T) {
+	sfsarray := NewSFSArray()
+	sfsarray.AddInt(1)
+	sfsarray.AddInt(2)
+	stop := errors.New("stop")
+	visited := 0
+	err := sfsarray.Walk(func(path string, value Value) error {
+		visited++
+		return stop
+	})
+	if err != stop || visited != 1 {
+		t.Fatalf("got %v after %d values, want stop after 1", err, visited)
+	}
+}
@@ -0,0 +1,203 @@
+package sfstypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// JsonOptions controls how JSON values are mapped onto SFS types by
+// NewSFSObjectFromJsonDataWithOptions and NewSFSArrayFromJsonDataWithOptions.
+// Numbers are always read as json.Number, so no precision is lost before the
+// target type is chosen.
+type JsonOptions struct {
+	// IntegerNumbers stores integral numbers as INT, or LONG if they don't
+	// fit, instead of DOUBLE. Numbers with a fraction or exponent stay DOUBLE.
+	IntegerNumbers bool
+	// TypedArrays stores non-empty JSON arrays whose elements are all
+	// booleans, all strings or all numbers as BOOL_ARRAY, UTF_STRING_ARRAY or
+	// INT_ARRAY/LONG_ARRAY/DOUBLE_ARRAY instead of an SFSArray.
+	TypedArrays bool
+	// AutoText stores strings longer than a UTF_STRING can hold as TEXT.
+	AutoText bool
+}
+
+// maxUtfStringLength is the longest string the SFS2X API accepts as
+// UTF_STRING, whose length prefix is a signed 16 bit integer.
+const maxUtfStringLength = math.MaxInt16
+
+func sfsObjectFromJsonWithOptions(input string, options JsonOptions) (*SFSObject, error) {
+	result := make(map[string]interface{})
+	if err := unmarshalJsonNumbers(input, &result); err != nil {
+		return nil, err
+	}
+	return convertJsonMapWithOptions(result, options)
+}
+
+func sfsArrayFromJsonWithOptions(input string, options JsonOptions) (*SFSArray, error) {
+	result := make([]interface{}, 0)
+	if err := unmarshalJsonNumbers(input, &result); err != nil {
+		return nil, err
+	}
+	return convertJsonSliceWithOptions(result, options)
+}
+
+func unmarshalJsonNumbers(input string, result interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+	if err := decoder.Decode(result); err != nil {
+		return err
+	}
+	// Decode stops after the first value; json.Unmarshal rejects anything
+	// but whitespace after it, and so do we.
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid data after top-level JSON value")
+	}
+	return nil
+}
+
+func convertJsonMapWithOptions(input map[string]interface{}, options JsonOptions) (*SFSObject, error) {
+	sfsObject := NewSFSObject()
+	// Sorted, so that a document with several invalid values always reports
+	// the same error.
+	for _, key := range slices.Sorted(maps.Keys(input)) {
+		wrapper, err := convertJsonValueWithOptions(input[key], options)
+		if err != nil {
+			return nil, err
+		}
+		if err := sfsObject.putsfsDataWrapper(key, &wrapper); err != nil {
+			return nil, err
+		}
+	}
+	return sfsObject, nil
+}
+
+func convertJsonSliceWithOptions(input []interface{}, options JsonOptions) (*SFSArray, error) {
+	sfsArray := NewSFSArray()
+	for _, element := range input {
+		wrapper, err := convertJsonValueWithOptions(element, options)
+		if err != nil {
+			return nil, err
+		}
+		sfsArray.addsfsDataWrapper(wrapper)
+	}
+	return sfsArray, nil
+}
+
+func convertJsonValueWithOptions(value interface{}, options JsonOptions) (sfsDataWrapper, error) {
+	switch v := value.(type) {
+	case nil:
+		return sfsDataWrapper{typeId: TypeNull}, nil
+	case bool:
+		return sfsDataWrapper{typeId: TypeBool, data: v}, nil
+	case string:
+		if options.AutoText && len(v) > maxUtfStringLength {
+			return sfsDataWrapper{typeId: TypeText, data: v}, nil
+		}
+		return sfsDataWrapper{typeId: TypeUtfString, data: v}, nil
+	case json.Number:
+		return convertJsonNumber(v, options)
+	case map[string]interface{}:
+		obj, err := convertJsonMapWithOptions(v, options)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeSFSObject, data: obj}, nil
+	case []interface{}:
+		if options.TypedArrays {
+			if wrapper, ok := convertJsonTypedArray(v, options); ok {
+				return wrapper, nil
+			}
+		}
+		arr, err := convertJsonSliceWithOptions(v, options)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeSFSArray, data: arr}, nil
+	}
+	return sfsDataWrapper{}, &ErrUnsupportedType{Value: value}
+}
+
+func convertJsonNumber(number json.Number, options JsonOptions) (sfsDataWrapper, error) {
+	if options.IntegerNumbers {
+		if integer, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+			if integerFits(integer, TypeInt) {
+				return sfsDataWrapper{typeId: TypeInt, data: int32(integer)}, nil
+			}
+			return sfsDataWrapper{typeId: TypeLong, data: integer}, nil
+		}
+	}
+	double, err := number.Float64()
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	return sfsDataWrapper{typeId: TypeDouble, data: double}, nil
+}
+
+// convertJsonTypedArray returns the typed array for a homogeneous JSON array,
+// or false if the array is empty, mixed or too long for a typed array, whose
+// count is a signed 16 bit integer.
+func convertJsonTypedArray(input []interface{}, options JsonOptions) (sfsDataWrapper, bool) {
+	if len(input) == 0 || len(input) > math.MaxInt16 {
+		return sfsDataWrapper{}, false
+	}
+	switch input[0].(type) {
+	case bool:
+		result := make([]bool, len(input))
+		for i, element := range input {
+			value, ok := element.(bool)
+			if !ok {
+				return sfsDataWrapper{}, false
+			}
+			result[i] = value
+		}
+		return sfsDataWrapper{typeId: TypeBoolArray, data: result}, true
+	case string:
+		result := make([]string, len(input))
+		for i, element := range input {
+			value, ok := element.(string)
+			if !ok || len(value) > maxUtfStringLength {
+				return sfsDataWrapper{}, false
+			}
+			result[i] = value
+		}
+		return sfsDataWrapper{typeId: TypeUtfStringArray, data: result}, true
+	case json.Number:
+		integers := make([]int64, len(input))
+		integral := options.IntegerNumbers
+		for i, element := range input {
+			value, ok := element.(json.Number)
+			if !ok {
+				return sfsDataWrapper{}, false
+			}
+			if integral {
+				var err error
+				integers[i], err = strconv.ParseInt(value.String(), 10, 64)
+				integral = err == nil
+			}
+		}
+		if integral {
+			for _, integer := range integers {
+				if !integerFits(integer, TypeInt) {
+					return sfsDataWrapper{typeId: TypeLongArray, data: integers}, true
+				}
+			}
+			return integerArray(TypeInt, integers), true
+		}
+		result := make([]float64, len(input))
+		for i, element := range input {
+			value, err := element.(json.Number).Float64()
+			if err != nil {
+				return sfsDataWrapper{}, false
+			}
+			result[i] = value
+		}
+		return sfsDataWrapper{typeId: TypeDoubleArray, data: result}, true
+	}
+	return sfsDataWrapper{}, false
+}
@@ -0,0 +1,107 @@
+package sfstypes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJsonOptions(t *testing.T) {
+	all := JsonOptions{IntegerNumbers: true, TypedArrays: true, AutoText: true}
+	tests := []struct {
+		name     string
+		options  JsonOptions
+		json     string
+		wantType DataType
+		wantData interface{}
+	}{
+		{"default number", JsonOptions{}, `5`, TypeDouble, 5.0},
+		{"int", JsonOptions{IntegerNumbers: true}, `-5`, TypeInt, int32(-5)},
+		{"int max", JsonOptions{IntegerNumbers: true}, `2147483647`, TypeInt, int32(2147483647)},
+		{"long", JsonOptions{IntegerNumbers: true}, `2147483648`, TypeLong, int64(2147483648)},
+		{"long above 2^53", JsonOptions{IntegerNumbers: true}, `9007199254740993`, TypeLong, int64(9007199254740993)},
+		{"fraction", JsonOptions{IntegerNumbers: true}, `1.5`, TypeDouble, 1.5},
+		{"exponent", JsonOptions{IntegerNumbers: true}, `1e3`, TypeDouble, 1000.0},
+		{"beyond int64", JsonOptions{IntegerNumbers: true}, `9223372036854775808`, TypeDouble, 9223372036854775808.0},
+
+		{"default array", JsonOptions{}, `[true]`, TypeSFSArray, nil},
+		{"bool array", JsonOptions{TypedArrays: true}, `[true, false]`, TypeBoolArray, []bool{true, false}},
+		{"string array", JsonOptions{TypedArrays: true}, `["a", "b"]`, TypeUtfStringArray, []string{"a", "b"}},
+		{"double array", JsonOptions{TypedArrays: true}, `[1, 2]`, TypeDoubleArray, []float64{1, 2}},
+		{"int array", all, `[1, -2]`, TypeIntArray, []int32{1, -2}},
+		{"long array", all, `[1, 2147483648]`, TypeLongArray, []int64{1, 2147483648}},
+		{"mixed integral", all, `[1, 2.5]`, TypeDoubleArray, []float64{1, 2.5}},
+		{"mixed types", all, `[1, "a"]`, TypeSFSArray, nil},
+		{"null element", all, `[null]`, TypeSFSArray, nil},
+		{"empty", all, `[]`, TypeSFSArray, nil},
+		{"long string element", all, `["` + strings.Repeat("x", maxUtfStringLength+1) + `"]`, TypeSFSArray, nil},
+
+		{"default string", JsonOptions{}, `"` + strings.Repeat("x", maxUtfStringLength+1) + `"`, TypeUtfString, strings.Repeat("x", maxUtfStringLength+1)},
+		{"short string", JsonOptions{AutoText: true}, `"` + strings.Repeat("x", maxUtfStringLength) + `"`, TypeUtfString, strings.Repeat("x", maxUtfStringLength)},
+		{"text", JsonOptions{AutoText: true}, `"` + strings.Repeat("x", maxUtfStringLength+1) + `"`, TypeText, strings.Repeat("x", maxUtfStringLength+1)},
+	}
+	for _, test := range tests {
+		sfsobject, err := NewSFSObjectFromJsonDataWithOptions(`{"key": `+test.json+`}`, test.options)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		wrapper := sfsobject.dataHolder["key"]
+		if wrapper.typeId != test.wantType {
+			t.Errorf("%s: got %v, want %v", test.name, wrapper.typeId, test.wantType)
+		} else if test.wantData != nil && !reflect.DeepEqual(wrapper.data, test.wantData) {
+			t.Errorf("%s: got %#v, want %#v", test.name, wrapper.data, test.wantData)
+		}
+	}
+}
+
+func TestJsonTypedArrayLengthLimit(t *testing.T) {
+	array := func(length int) string {
+		return `{"key": [` + strings.TrimSuffix(strings.Repeat("1,", length), ",") + `]}`
+	}
+	options := JsonOptions{IntegerNumbers: true, TypedArrays: true}
+	for length, want := range map[int]DataType{
+		maxUtfStringLength:     TypeIntArray,
+		maxUtfStringLength + 1: TypeSFSArray,
+	} {
+		sfsobject, err := NewSFSObjectFromJsonDataWithOptions(array(length), options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typeId, _ := sfsobject.TypeOf("key"); typeId != want {
+			t.Errorf("%d elements: got %v, want %v", length, typeId, want)
+		}
+	}
+}
+
+func TestJsonNestedOptions(t *testing.T) {
+	sfsobject, err := NewSFSObjectFromJsonDataWithOptions(`{"a": {"b": [{"c": 1}]}}`, JsonOptions{IntegerNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := sfsobject.GetSFSObject("a")
+	b, _ := a.GetSFSArray("b")
+	element, _ := b.GetSFSObject(0)
+	if c, err := element.GetInt("c"); err != nil || c != 1 {
+		t.Fatalf("a.b[0].c = %v, %v; options don't reach nested values", c, err)
+	}
+
+	sfsarray, err := NewSFSArrayFromJsonDataWithOptions(`[1, [2]]`, JsonOptions{IntegerNumbers: true, TypedArrays: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typeId, _ := sfsarray.TypeOf(1); typeId != TypeIntArray {
+		t.Fatalf("got %v, want INT_ARRAY", typeId)
+	}
+}
+
+func TestJsonRejectsInvalidInput(t *testing.T) {
+	for _, input := range []string{``, `{`, `[]`, `{"a": 1} {}`, `{"a": 1} x`, `{"": 1}`} {
+		if _, err := NewSFSObjectFromJsonDataWithOptions(input, JsonOptions{}); err == nil {
+			t.Errorf("%q was accepted", input)
+		}
+	}
+	if _, err := NewSFSArrayFromJsonDataWithOptions(`[1] [2]`, JsonOptions{}); err == nil {
+		t.Error("trailing array was accepted")
+	}
+}
@@ -0,0 +1,254 @@
+package sfstypes
+
+import (
+	"encoding/binary"
+)
+
+// LazySFSObject is a view over the binary form of an SFSObject that decodes
+// values only when they are accessed. The key offsets are indexed on first
+// access; untouched values are never materialized and are copied verbatim by
+// ToBinary. The view references the given bytes without copying them, so the
+// caller must not modify them while the view is in use.
+type LazySFSObject struct {
+	data    []byte
+	indexed bool
+	keys    []string
+	entries map[string]lazyEntry
+	changes map[string]sfsDataWrapper
+}
+
+type lazyEntry struct {
+	start int
+	end   int
+}
+
+func NewLazySFSObject(data []byte) (*LazySFSObject, error) {
+	if len(data) < 3 {
+		return nil, &ErrInsufficientByteData{Type: TypeSFSObject, Size: len(data)}
+	}
+	if DataType(data[0]) != TypeSFSObject {
+		return nil, &ErrWrongType{ActualType: DataType(data[0]), WantedType: TypeSFSObject}
+	}
+	return &LazySFSObject{data: data}, nil
+}
+
+// Index parses the key offsets if that has not happened yet. Accessors call
+// it implicitly; calling it directly surfaces corrupt input early.
+func (lazy *LazySFSObject) Index() error {
+	if lazy.indexed {
+		return nil
+	}
+	decoder := sfsDecoder{data: lazy.data, offset: 1}
+	size, err := decoder.readUint16("SFSObject size")
+	if err != nil {
+		return err
+	}
+	// Every entry takes at least 3 bytes, so a corrupt count can't make us
+	// allocate more than the data could hold.
+	capacity := min(int(size), decoder.remaining()/3)
+	keys := make([]string, 0, capacity)
+	entries := make(map[string]lazyEntry, capacity)
+	for i := uint16(0); i < size; i++ {
+		keySize, err := decoder.readUint16("key size")
+		if err != nil {
+			return err
+		}
+		keyBytes, err := decoder.read(int(keySize), "key")
+		if err != nil {
+			return err
+		}
+		key := string(keyBytes)
+		start := decoder.offset
+		if err := decoder.skipData(); err != nil {
+			return err
+		}
+		if _, exists := entries[key]; !exists {
+			keys = append(keys, key)
+		}
+		entries[key] = lazyEntry{start: start, end: decoder.offset}
+	}
+	lazy.data = lazy.data[:decoder.offset]
+	lazy.keys = keys
+	lazy.entries = entries
+	lazy.changes = make(map[string]sfsDataWrapper)
+	lazy.indexed = true
+	return nil
+}
+
+// Size returns the number of keys, or 0 if the data can't be indexed.
+func (lazy *LazySFSObject) Size() int {
+	if lazy.Index() != nil {
+		return 0
+	}
+	return len(lazy.keys)
+}
+
+// GetKeys returns the keys in wire order, or nil if the data can't be indexed.
+func (lazy *LazySFSObject) GetKeys() []string {
+	if lazy.Index() != nil {
+		return nil
+	}
+	keys := make([]string, len(lazy.keys))
+	copy(keys, lazy.keys)
+	return keys
+}
+
+func (lazy *LazySFSObject) ContainsKey(key string) bool {
+	if lazy.Index() != nil {
+		return false
+	}
+	_, exists := lazy.entries[key]
+	return exists
+}
+
+func (lazy *LazySFSObject) TypeOf(key string) (DataType, error) {
+	if err := lazy.Index(); err != nil {
+		return TypeNull, err
+	}
+	if wrapper, changed := lazy.changes[key]; changed {
+		return wrapper.typeId, nil
+	}
+	entry, exists := lazy.entries[key]
+	if !exists {
+		return TypeNull, &ErrKeyNotFound{Key: key}
+	}
+	return DataType(lazy.data[entry.start]), nil
+}
+
+// GetValue decodes the value stored under key.
+func (lazy *LazySFSObject) GetValue(key string) (Value, error) {
+	if err := lazy.Index(); err != nil {
+		return Value{}, err
+	}
+	if wrapper, changed := lazy.changes[key]; changed {
+		return Value{wrapper: wrapper}, nil
+	}
+	entry, exists := lazy.entries[key]
+	if !exists {
+		return Value{}, &ErrKeyNotFound{Key: key}
+	}
+	decoder := sfsDecoder{data: lazy.data[entry.start:entry.end]}
+	wrapper, err := decoder.decodeData()
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{wrapper: wrapper}, nil
+}
+
+func (lazy *LazySFSObject) Get(key string) (interface{}, error) {
+	value, err := lazy.GetValue(key)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// GetRawByteArray returns the contents of the BYTE_ARRAY stored under key as
+// a sub-slice of the underlying data, without copying.
+func (lazy *LazySFSObject) GetRawByteArray(key string) ([]byte, error) {
+	entry, err := lazy.rawEntry(key, TypeByteArray)
+	if err != nil {
+		return nil, err
+	}
+	return lazy.data[entry.start+5 : entry.end], nil
+}
+
+// GetLazySFSObject returns a lazy view of the nested SFSObject stored under
+// key, sharing the underlying data.
+func (lazy *LazySFSObject) GetLazySFSObject(key string) (*LazySFSObject, error) {
+	entry, err := lazy.rawEntry(key, TypeSFSObject)
+	if err != nil {
+		return nil, err
+	}
+	return NewLazySFSObject(lazy.data[entry.start:entry.end])
+}
+
+func (lazy *LazySFSObject) rawEntry(key string, typeId DataType) (lazyEntry, error) {
+	if err := lazy.Index(); err != nil {
+		return lazyEntry{}, err
+	}
+	if wrapper, changed := lazy.changes[key]; changed {
+		return lazyEntry{}, &ErrWrongType{ActualType: wrapper.typeId, WantedType: typeId}
+	}
+	entry, exists := lazy.entries[key]
+	if !exists {
+		return lazyEntry{}, &ErrKeyNotFound{Key: key}
+	}
+	if actualType := DataType(lazy.data[entry.start]); actualType != typeId {
+		return lazyEntry{}, &ErrWrongType{ActualType: actualType, WantedType: typeId}
+	}
+	return entry, nil
+}
+
+// Put stores value under key, with the same conversions as SFSObject.Put.
+// Other values keep their encoded form.
+func (lazy *LazySFSObject) Put(key string, value interface{}) error {
+	if err := lazy.Index(); err != nil {
+		return err
+	}
+	converted := NewSFSObject()
+	if err := converted.Put(key, value); err != nil {
+		return err
+	}
+	if _, exists := lazy.entries[key]; !exists {
+		lazy.keys = append(lazy.keys, key)
+		lazy.entries[key] = lazyEntry{}
+	}
+	lazy.changes[key] = converted.dataHolder[key]
+	return nil
+}
+
+func (lazy *LazySFSObject) RemoveElement(key string) error {
+	if err := lazy.Index(); err != nil {
+		return err
+	}
+	if _, exists := lazy.entries[key]; !exists {
+		return &ErrKeyNotFound{Key: key}
+	}
+	delete(lazy.entries, key)
+	delete(lazy.changes, key)
+	for i, k := range lazy.keys {
+		if k == key {
+			lazy.keys = append(lazy.keys[:i], lazy.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// ToBinary encodes the object. Values that were not changed through Put are
+// copied from the underlying data as they are.
+func (lazy *LazySFSObject) ToBinary() ([]byte, error) {
+	if err := lazy.Index(); err != nil {
+		return nil, err
+	}
+	dst := make([]byte, 0, len(lazy.data))
+	dst = append(dst, byte(TypeSFSObject))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(lazy.keys)))
+	for _, key := range lazy.keys {
+		dst = appendSFSObjectKey(dst, key)
+		if wrapper, changed := lazy.changes[key]; changed {
+			dst = appendData(dst, wrapper.typeId, wrapper.data)
+			continue
+		}
+		entry := lazy.entries[key]
+		dst = append(dst, lazy.data[entry.start:entry.end]...)
+	}
+	return dst, nil
+}
+
+// ToSFSObject decodes the whole object.
+func (lazy *LazySFSObject) ToSFSObject() (*SFSObject, error) {
+	if err := lazy.Index(); err != nil {
+		return nil, err
+	}
+	sfsobject := NewSFSObject()
+	for _, key := range lazy.keys {
+		value, err := lazy.GetValue(key)
+		if err != nil {
+			return nil, err
+		}
+		sfsobject.putsfsDataWrapper(key, &value.wrapper)
+	}
+	return sfsobject, nil
+}
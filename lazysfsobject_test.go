@@ -0,0 +1,128 @@
+package sfstypes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func lazyTestPayload() []byte {
+	nested := NewSFSObject()
+	nested.PutUtfString("name", "lazy")
+	nested.PutInt("level", 3)
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("id", 7)
+	sfsobject.PutByteArray("blob", []int8{1, 2, 3, 4})
+	sfsobject.PutSFSObject("nested", nested)
+	return sfsobject.ToBinary()
+}
+
+func TestLazySFSObjectAccess(t *testing.T) {
+	lazy, err := NewLazySFSObject(lazyTestPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lazy.Size() != 3 || !lazy.ContainsKey("nested") || lazy.ContainsKey("missing") {
+		t.Fatalf("got keys %q", lazy.GetKeys())
+	}
+	if id, err := lazy.Get("id"); err != nil || id != int32(7) {
+		t.Fatalf("Get(id) = %v, %v", id, err)
+	}
+	if typeId, err := lazy.TypeOf("blob"); err != nil || typeId != TypeByteArray {
+		t.Fatalf("TypeOf(blob) = %v, %v", typeId, err)
+	}
+	nested, err := lazy.GetLazySFSObject("nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := nested.Get("name"); err != nil || name != "lazy" {
+		t.Fatalf("nested Get(name) = %v, %v", name, err)
+	}
+	var wrongType *ErrWrongType
+	if _, err := lazy.GetRawByteArray("id"); !errors.As(err, &wrongType) {
+		t.Fatalf("GetRawByteArray(id): got %v, want ErrWrongType", err)
+	}
+	var notFound *ErrKeyNotFound
+	if _, err := lazy.Get("missing"); !errors.As(err, &notFound) {
+		t.Fatalf("Get(missing): got %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestLazySFSObjectRawByteArrayIsNotCopied(t *testing.T) {
+	data := lazyTestPayload()
+	lazy, err := NewLazySFSObject(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := lazy.GetRawByteArray("blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, []byte{1, 2, 3, 4}) {
+		t.Fatalf("got %v", raw)
+	}
+	raw[0] = 9
+	if !bytes.Contains(data, []byte{9, 2, 3, 4}) {
+		t.Fatal("GetRawByteArray returned a copy")
+	}
+}
+
+func TestLazySFSObjectCopiesUntouchedValues(t *testing.T) {
+	// The nested object lists "b" before "a". Decoding and encoding it would
+	// not keep that order, copying its bytes does.
+	nested := []byte{
+		0x12, 0x00, 0x02,
+		0x00, 0x01, 'b', 0x04, 0x00, 0x00, 0x00, 0x02,
+		0x00, 0x01, 'a', 0x04, 0x00, 0x00, 0x00, 0x01,
+	}
+	data := append([]byte{0x12, 0x00, 0x01, 0x00, 0x01, 'n'}, nested...)
+	lazy, err := NewLazySFSObject(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := lazy.ToBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, data) {
+		t.Fatalf("untouched object changed:\n%x\n%x", data, encoded)
+	}
+
+	if err := lazy.Put("id", int32(5)); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err = lazy.ToBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(encoded, nested) {
+		t.Fatalf("untouched value wasn't copied verbatim: %x", encoded)
+	}
+	decoded, err := NewSFSObjectFromBinaryData(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := decoded.GetInt("id"); err != nil || id != 5 {
+		t.Fatalf("GetInt(id) = %v, %v", id, err)
+	}
+}
+
+func TestLazySFSObjectRejectsCorruptInput(t *testing.T) {
+	inputs := [][]byte{
+		// Claims 65535 keys but holds none.
+		{0x12, 0xff, 0xff},
+		// Key longer than the data.
+		{0x12, 0x00, 0x01, 0x00, 0x05, 'k'},
+		// Truncated INT.
+		{0x12, 0x00, 0x01, 0x00, 0x01, 'k', 0x04, 0x00},
+	}
+	for _, input := range inputs {
+		lazy, err := NewLazySFSObject(input)
+		if err != nil {
+			continue
+		}
+		if err := lazy.Index(); err == nil {
+			t.Errorf("%x: Index succeeded", input)
+		}
+	}
+}
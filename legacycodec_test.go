@@ -0,0 +1,402 @@
+package sfstypes
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// The binary.Write and binary.Read based codec that AppendBinary and the
+// slice decoder replaced, kept as a reference for the benchmarks.
+
+func legacyEncodeSFSObject(object *SFSObject) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, byte(TypeSFSObject))
+	binary.Write(&buf, binary.BigEndian, int16(object.Size()))
+
+	keys := object.GetKeys()
+	for _, key := range keys {
+		legacyEncodeSFSObjectKey(&buf, key)
+		wrapper, _ := object.getWrapper(key)
+		dataObj := wrapper.data
+		legacyEncodeData(&buf, wrapper.typeId, dataObj)
+	}
+	return buf.Bytes()
+}
+
+func legacyEncodeSFSObjectKey(buf *bytes.Buffer, value string) {
+	binary.Write(buf, binary.BigEndian, int16(len(value)))
+	binary.Write(buf, binary.BigEndian, []byte(value))
+}
+
+func legacyEncodeSFSArray(array *SFSArray) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, byte(TypeSFSArray))
+	binary.Write(&buf, binary.BigEndian, int16(array.Size()))
+
+	for i := 0; i < array.Size(); i++ {
+		wrapper, _ := array.getWrapper(i)
+		legacyEncodeData(&buf, wrapper.typeId, wrapper.data)
+	}
+	return buf.Bytes()
+}
+
+func legacyEncodeData(buf *bytes.Buffer, typeId DataType, object interface{}) {
+	binary.Write(buf, binary.BigEndian, typeId)
+	switch typeId {
+	case TypeNull:
+	case TypeBool:
+		if object.(bool) {
+			binary.Write(buf, binary.BigEndian, byte(1))
+		} else {
+			binary.Write(buf, binary.BigEndian, byte(0))
+		}
+	case TypeByte:
+		binary.Write(buf, binary.BigEndian, object.(int8))
+	case TypeShort:
+		binary.Write(buf, binary.BigEndian, object.(int16))
+	case TypeInt:
+		binary.Write(buf, binary.BigEndian, object.(int32))
+	case TypeLong:
+		binary.Write(buf, binary.BigEndian, object.(int64))
+	case TypeFloat:
+		binary.Write(buf, binary.BigEndian, object.(float32))
+	case TypeDouble:
+		binary.Write(buf, binary.BigEndian, object.(float64))
+	case TypeUtfString:
+		str := object.(string)
+		binary.Write(buf, binary.BigEndian, int16(len(str)))
+		binary.Write(buf, binary.BigEndian, []byte(str))
+	case TypeText:
+		str := object.(string)
+		binary.Write(buf, binary.BigEndian, int32(len(str)))
+		binary.Write(buf, binary.BigEndian, []byte(str))
+	case TypeBoolArray:
+		array := object.([]bool)
+		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		for _, element := range array {
+			if element {
+				binary.Write(buf, binary.BigEndian, byte(1))
+			} else {
+				binary.Write(buf, binary.BigEndian, byte(0))
+			}
+		}
+	case TypeByteArray:
+		array := object.([]int8)
+		binary.Write(buf, binary.BigEndian, int32(len(array)))
+		for _, element := range array {
+			binary.Write(buf, binary.BigEndian, element)
+		}
+	case TypeShortArray:
+		array := object.([]int16)
+		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		for _, element := range array {
+			binary.Write(buf, binary.BigEndian, element)
+		}
+	case TypeIntArray:
+		array := object.([]int32)
+		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		for _, element := range array {
+			binary.Write(buf, binary.BigEndian, element)
+		}
+	case TypeLongArray:
+		array := object.([]int64)
+		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		for _, element := range array {
+			binary.Write(buf, binary.BigEndian, element)
+		}
+	case TypeFloatArray:
+		array := object.([]float32)
+		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		for _, element := range array {
+			binary.Write(buf, binary.BigEndian, element)
+		}
+	case TypeDoubleArray:
+		array := object.([]float64)
+		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		for _, element := range array {
+			binary.Write(buf, binary.BigEndian, element)
+		}
+	case TypeUtfStringArray:
+		array := object.([]string)
+		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		for _, element := range array {
+			binary.Write(buf, binary.BigEndian, int16(len(element)))
+			binary.Write(buf, binary.BigEndian, []byte(element))
+		}
+	case TypeSFSArray:
+		buf.Truncate(buf.Len() - 1)
+		binary.Write(buf, binary.BigEndian, legacyEncodeSFSArray(object.(*SFSArray)))
+	case TypeSFSObject:
+		buf.Truncate(buf.Len() - 1)
+		binary.Write(buf, binary.BigEndian, legacyEncodeSFSObject(object.(*SFSObject)))
+	}
+}
+
+func legacyDecodeSFSObject(buf *bytes.Buffer) (*SFSObject, error) {
+	sfsObject := NewSFSObject()
+
+	var header byte
+	if err := binary.Read(buf, binary.BigEndian, &header); err != nil {
+		return nil, &ErrReadingData{TypeToRead: "value header", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+	} else if header != byte(TypeSFSObject) {
+		return nil, &ErrWrongType{ActualType: DataType(header), WantedType: TypeSFSObject}
+	}
+
+	var size uint16
+	if err := binary.Read(buf, binary.BigEndian, &size); err != nil {
+		return nil, &ErrReadingData{TypeToRead: "SFSObject size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+	}
+	for i := uint16(0); i < size; i++ {
+
+		var keySize uint16
+		if err := binary.Read(buf, binary.BigEndian, &keySize); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "key size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		if keySize > 255 {
+			return nil, &ErrInvalidKeySize{Key: "", Length: int(keySize)}
+		}
+		keyStringBytes := make([]byte, keySize)
+		if err := binary.Read(buf, binary.BigEndian, &keyStringBytes); err != nil {
+			return nil, err
+		}
+		key := string(keyStringBytes)
+
+		decodedObject, decodeError := legacyDecodeData(buf)
+		if decodeError != nil {
+			return nil, decodeError
+		}
+		sfsObject.putsfsDataWrapper(key, decodedObject)
+	}
+	return sfsObject, nil
+}
+
+func legacyDecodeSFSArray(buf *bytes.Buffer) (*SFSArray, error) {
+	sfsArray := NewSFSArray()
+
+	var header byte
+	if err := binary.Read(buf, binary.BigEndian, &header); err != nil {
+		return nil, &ErrReadingData{TypeToRead: "SFSArry header", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+	} else if header != byte(TypeSFSArray) {
+		return nil, &ErrWrongType{ActualType: DataType(header), WantedType: TypeSFSArray}
+	}
+
+	var size uint16
+	if err := binary.Read(buf, binary.BigEndian, &size); err != nil {
+		return nil, &ErrReadingData{TypeToRead: "SFSObject size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+	}
+
+	for i := uint16(0); i < size; i++ {
+		wrapper, err := legacyDecodeData(buf)
+		if err != nil {
+			return nil, err
+		}
+		sfsArray.addsfsDataWrapper(*wrapper)
+	}
+
+	return sfsArray, nil
+}
+
+func legacyDecodeData(buf *bytes.Buffer) (*sfsDataWrapper, error) {
+	var header byte
+	if err := binary.Read(buf, binary.BigEndian, &header); err != nil {
+		return nil, &ErrReadingData{TypeToRead: "value header", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+	}
+	switch DataType(header) {
+	case TypeNull:
+		return newsfsDataWrapper(TypeNull, nil), nil
+	case TypeBool:
+		var input byte
+		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "bool", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		switch input {
+		case 0:
+			return newsfsDataWrapper(TypeBool, false), nil
+		case 1:
+			return newsfsDataWrapper(TypeBool, true), nil
+		default:
+			return nil, ErrDecodingBool
+		}
+	case TypeByte:
+		var input int8
+		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "byte", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeByte, input), nil
+	case TypeShort:
+		var input int16
+		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "short", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeShort, input), nil
+	case TypeInt:
+		var input int32
+		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "int", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeInt, input), nil
+	case TypeLong:
+		var input int64
+		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "long", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeLong, input), nil
+	case TypeFloat:
+		var input float32
+		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "float", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeFloat, input), nil
+	case TypeDouble:
+		var input float64
+		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "double", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeDouble, input), nil
+	case TypeUtfString:
+		var len uint16
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "string length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		stringBytes := make([]byte, len)
+		if err := binary.Read(buf, binary.BigEndian, &stringBytes); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "string bytes", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		decodedString := string(stringBytes)
+		return newsfsDataWrapper(TypeUtfString, decodedString), nil
+	case TypeText:
+		var len uint32
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "text length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		stringBytes := make([]byte, len)
+		if err := binary.Read(buf, binary.BigEndian, &stringBytes); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "text bytes", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		decodedString := string(stringBytes)
+		return newsfsDataWrapper(TypeText, decodedString), nil
+	case TypeBoolArray:
+		var length uint16
+		if err := binary.Read(buf, binary.BigEndian, &length); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "bool array length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]bool, length)
+		for i := uint16(0); i < length; i++ {
+			var tempValue byte
+			if err := binary.Read(buf, binary.BigEndian, &tempValue); err != nil {
+				return nil, &ErrReadingData{TypeToRead: "bool array element", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+			}
+			switch tempValue {
+			case 0:
+				results[i] = false
+			case 1:
+				results[i] = true
+			default:
+				return nil, ErrDecodingBool
+			}
+		}
+		return newsfsDataWrapper(TypeBoolArray, results), nil
+	case TypeByteArray:
+		var len uint32
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "byte array length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]int8, len)
+		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "byte array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeByteArray, results), nil
+	case TypeShortArray:
+		var len uint16
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "short array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]int16, len)
+		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "short array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeShortArray, results), nil
+	case TypeIntArray:
+		var len uint16
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "int array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]int32, len)
+		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "int array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeIntArray, results), nil
+	case TypeLongArray:
+		var len uint16
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "long array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]int64, len)
+		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "long array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeLongArray, results), nil
+	case TypeFloatArray:
+		var len uint16
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "float array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]float32, len)
+		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "float array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeFloatArray, results), nil
+	case TypeDoubleArray:
+		var len uint16
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "double array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]float64, len)
+		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "double array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+		return newsfsDataWrapper(TypeDoubleArray, results), nil
+	case TypeUtfStringArray:
+		var len uint16
+		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
+			return nil, &ErrReadingData{TypeToRead: "string array length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		}
+
+		results := make([]string, 0)
+		for i := uint16(0); i < len; i++ {
+			var strLen uint16
+			if err := binary.Read(buf, binary.BigEndian, &strLen); err != nil {
+				return nil, &ErrReadingData{TypeToRead: "string array element length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+			}
+			stringBytes := make([]byte, strLen)
+			if err := binary.Read(buf, binary.BigEndian, &stringBytes); err != nil {
+				return nil, &ErrReadingData{TypeToRead: "string array element bytes", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+			}
+			results = append(results, string(stringBytes))
+		}
+		return newsfsDataWrapper(TypeUtfStringArray, results), nil
+	case TypeSFSArray:
+		buf.UnreadByte()
+		obj, err := legacyDecodeSFSArray(buf)
+		if err != nil {
+			return nil, err
+		}
+		return newsfsDataWrapper(TypeSFSArray, obj), nil
+	case TypeSFSObject:
+		buf.UnreadByte()
+		obj, err := legacyDecodeSFSObject(buf)
+		if err != nil {
+			return nil, err
+		}
+		return newsfsDataWrapper(TypeSFSObject, obj), nil
+	default: // DataType(header)
+		return nil, &ErrDecodingUnsupportedType{Type: DataType(header), Len: buf.Len(), Cap: buf.Cap()}
+	}
+}
@@ -0,0 +1,296 @@
+package sfstypes
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LogOptions controls how SFSObjects and SFSArrays appear in log output,
+// through slog.LogValuer and the %v and %+v formats.
+type LogOptions struct {
+	// MaxStringLength cuts strings longer than this many bytes. Zero keeps
+	// strings whole.
+	MaxStringLength int
+	// MaxArrayLength cuts SFSArrays, typed arrays and BYTE_ARRAYs with more
+	// elements. Zero keeps arrays whole.
+	MaxArrayLength int
+	// RedactKeys lists keys, compared case-insensitively, whose values are
+	// replaced by [REDACTED] at any depth.
+	RedactKeys []string
+}
+
+// defaultLogOptions are used by LogValue and Format. Callers that need other
+// limits or keys pass their own LogOptions to LogValueWithOptions.
+var defaultLogOptions = LogOptions{
+	MaxStringLength: 256,
+	MaxArrayLength:  32,
+	RedactKeys:      []string{"pw", "password", "token"},
+}
+
+const redactedValue = "[REDACTED]"
+
+// LogValue implements slog.LogValuer. Objects become groups and SFSArrays
+// groups keyed by index, so handlers print nested values as e.g.
+// "data.items.0.id=7". Strings are cut after 256 bytes, arrays after 32
+// elements, and the values of the keys pw, password and token are redacted.
+func (sfsobject *SFSObject) LogValue() slog.Value {
+	return sfsobject.LogValueWithOptions(defaultLogOptions)
+}
+
+// LogValueWithOptions returns the LogValue for options instead of the
+// defaults of LogValue, for use with slog.Any, e.g.
+// slog.Any("data", data.LogValueWithOptions(options)).
+func (sfsobject *SFSObject) LogValueWithOptions(options LogOptions) slog.Value {
+	if sfsobject == nil {
+		return slog.AnyValue(nil)
+	}
+	return logSFSObject(sfsobject, options)
+}
+
+func (sfsarray *SFSArray) LogValue() slog.Value {
+	return sfsarray.LogValueWithOptions(defaultLogOptions)
+}
+
+func (sfsarray *SFSArray) LogValueWithOptions(options LogOptions) slog.Value {
+	if sfsarray == nil {
+		return slog.AnyValue(nil)
+	}
+	return logSFSArray(sfsarray, options)
+}
+
+// Format implements fmt.Formatter: %v and %s print a compact single line,
+// %+v the typed listing of GetDump, both with the defaults of LogValue, and
+// %x and %X the binary encoding in hex, of a copy in which redacted values are
+// replaced by the UTF_STRING "[REDACTED]".
+func (sfsobject *SFSObject) Format(state fmt.State, verb rune) {
+	if sfsobject == nil {
+		fmt.Fprint(state, "<nil>")
+		return
+	}
+	var output strings.Builder
+	switch {
+	case verb == 'x' || verb == 'X':
+		redacted := sfsobject.deepCopy()
+		redactSFSObject(redacted, defaultLogOptions)
+		fmt.Fprintf(state, fmt.FormatString(state, verb), redacted.ToBinary())
+		return
+	case verb == 'v' && state.Flag('+'):
+		dumpSFSObject(&output, sfsobject, 0, defaultLogOptions)
+	case verb == 'v' || verb == 's':
+		compactSFSObject(&output, sfsobject, defaultLogOptions)
+	default:
+		fmt.Fprintf(state, "%%!%c(*sfstypes.SFSObject)", verb)
+		return
+	}
+	state.Write([]byte(output.String()))
+}
+
+func (sfsarray *SFSArray) Format(state fmt.State, verb rune) {
+	if sfsarray == nil {
+		fmt.Fprint(state, "<nil>")
+		return
+	}
+	var output strings.Builder
+	switch {
+	case verb == 'x' || verb == 'X':
+		redacted := sfsarray.deepCopy()
+		redactSFSArray(redacted, defaultLogOptions)
+		fmt.Fprintf(state, fmt.FormatString(state, verb), redacted.ToBinary())
+		return
+	case verb == 'v' && state.Flag('+'):
+		dumpSFSArray(&output, sfsarray, 0, defaultLogOptions)
+	case verb == 'v' || verb == 's':
+		compactSFSArray(&output, sfsarray, defaultLogOptions)
+	default:
+		fmt.Fprintf(state, "%%!%c(*sfstypes.SFSArray)", verb)
+		return
+	}
+	state.Write([]byte(output.String()))
+}
+
+func (options LogOptions) redacts(key string) bool {
+	for _, redactKey := range options.RedactKeys {
+		if strings.EqualFold(key, redactKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate cuts strings and typed arrays to the configured length and returns
+// the number of bytes or elements it left out.
+func (options LogOptions) truncate(data interface{}) (interface{}, int) {
+	if str, isString := data.(string); isString {
+		if options.MaxStringLength <= 0 || len(str) <= options.MaxStringLength {
+			return str, 0
+		}
+		cut := options.MaxStringLength
+		for cut > 0 && !utf8.RuneStart(str[cut]) {
+			cut--
+		}
+		return str[:cut], len(str) - cut
+	}
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice || options.MaxArrayLength <= 0 || value.Len() <= options.MaxArrayLength {
+		return data, 0
+	}
+	return value.Slice(0, options.MaxArrayLength).Interface(), value.Len() - options.MaxArrayLength
+}
+
+// truncationMarker describes what truncate left out of data.
+func truncationMarker(omitted int, data interface{}) string {
+	if omitted == 0 {
+		return ""
+	}
+	if _, isString := data.(string); isString {
+		return fmt.Sprintf("...(%d more bytes)", omitted)
+	}
+	return fmt.Sprintf("...(%d more)", omitted)
+}
+
+// redactSFSObject replaces the values of redacted keys in sfsobject and its
+// nested containers, which must not be shared with the caller.
+func redactSFSObject(sfsobject *SFSObject, options LogOptions) {
+	for key, wrapper := range sfsobject.dataHolder {
+		if options.redacts(key) {
+			sfsobject.dataHolder[key] = sfsDataWrapper{typeId: TypeUtfString, data: redactedValue}
+			continue
+		}
+		redactValue(wrapper, options)
+	}
+}
+
+func redactSFSArray(sfsarray *SFSArray, options LogOptions) {
+	for _, wrapper := range sfsarray.dataHolder {
+		redactValue(wrapper, options)
+	}
+}
+
+func redactValue(wrapper sfsDataWrapper, options LogOptions) {
+	switch data := wrapper.data.(type) {
+	case *SFSObject:
+		redactSFSObject(data, options)
+	case *SFSArray:
+		redactSFSArray(data, options)
+	}
+}
+
+func logSFSObject(sfsobject *SFSObject, options LogOptions) slog.Value {
+	attrs := make([]slog.Attr, 0, sfsobject.Size())
+	for _, key := range sfsobject.sortedKeys() {
+		if options.redacts(key) {
+			attrs = append(attrs, slog.String(key, redactedValue))
+			continue
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: logValue(sfsobject.dataHolder[key], options)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func logSFSArray(sfsarray *SFSArray, options LogOptions) slog.Value {
+	attrs := make([]slog.Attr, 0, sfsarray.Size())
+	for index, value := range sfsarray.All() {
+		if options.MaxArrayLength > 0 && index == options.MaxArrayLength {
+			attrs = append(attrs, slog.String("...", fmt.Sprintf("%d more", sfsarray.Size()-index)))
+			break
+		}
+		attrs = append(attrs, slog.Attr{Key: strconv.Itoa(index), Value: logValue(value.wrapper, options)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func logValue(wrapper sfsDataWrapper, options LogOptions) slog.Value {
+	switch data := wrapper.data.(type) {
+	case *SFSObject:
+		return logSFSObject(data, options)
+	case *SFSArray:
+		return logSFSArray(data, options)
+	case bool:
+		return slog.BoolValue(data)
+	case int8:
+		return slog.Int64Value(int64(data))
+	case int16:
+		return slog.Int64Value(int64(data))
+	case int32:
+		return slog.Int64Value(int64(data))
+	case int64:
+		return slog.Int64Value(data)
+	case float32:
+		// Go through the shortest representation so 0.1 doesn't log as
+		// 0.10000000149011612.
+		double, _ := strconv.ParseFloat(dumpData(data), 64)
+		return slog.Float64Value(double)
+	case float64:
+		return slog.Float64Value(data)
+	case nil:
+		return slog.AnyValue(nil)
+	}
+	data, omitted := options.truncate(wrapper.data)
+	switch data := data.(type) {
+	case string:
+		return slog.StringValue(data + truncationMarker(omitted, data))
+	case []byte:
+		return slog.StringValue(dumpData(data) + truncationMarker(omitted, data))
+	}
+	if omitted == 0 {
+		return slog.AnyValue(data)
+	}
+	value := reflect.ValueOf(data)
+	elements := make([]interface{}, value.Len(), value.Len()+1)
+	for i := range elements {
+		elements[i] = value.Index(i).Interface()
+	}
+	return slog.AnyValue(append(elements, truncationMarker(omitted, data)))
+}
+
+// compactSFSObject writes sfsobject on one line, e.g.
+// {id:7 name:"Player" pos:{x:1.5 y:-2} scores:[1 2 3]}.
+func compactSFSObject(output *strings.Builder, sfsobject *SFSObject, options LogOptions) {
+	output.WriteString("{")
+	for index, key := range sfsobject.sortedKeys() {
+		if index > 0 {
+			output.WriteString(" ")
+		}
+		output.WriteString(key + ":")
+		if options.redacts(key) {
+			output.WriteString(redactedValue)
+			continue
+		}
+		compactValue(output, sfsobject.dataHolder[key], options)
+	}
+	output.WriteString("}")
+}
+
+func compactSFSArray(output *strings.Builder, sfsarray *SFSArray, options LogOptions) {
+	output.WriteString("[")
+	for index, value := range sfsarray.All() {
+		if index > 0 {
+			output.WriteString(" ")
+		}
+		if options.MaxArrayLength > 0 && index == options.MaxArrayLength {
+			output.WriteString(truncationMarker(sfsarray.Size()-index, nil))
+			break
+		}
+		compactValue(output, value.wrapper, options)
+	}
+	output.WriteString("]")
+}
+
+func compactValue(output *strings.Builder, wrapper sfsDataWrapper, options LogOptions) {
+	switch data := wrapper.data.(type) {
+	case *SFSObject:
+		compactSFSObject(output, data, options)
+	case *SFSArray:
+		compactSFSArray(output, data, options)
+	case nil:
+		output.WriteString("null")
+	default:
+		data, omitted := options.truncate(data)
+		output.WriteString(dumpData(data))
+		output.WriteString(truncationMarker(omitted, data))
+	}
+}
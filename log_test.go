@@ -0,0 +1,164 @@
+package sfstypes
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func logTestObject() *SFSObject {
+	pos := NewSFSObject()
+	pos.PutFloat("y", -2)
+	pos.PutFloat("x", 0.1)
+	items := NewSFSArray()
+	items.AddInt(7)
+	items.AddNull()
+	sfsobject := NewSFSObject()
+	sfsobject.PutUtfString("name", "Player")
+	sfsobject.PutSFSObject("pos", pos)
+	sfsobject.PutSFSArray("items", items)
+	sfsobject.PutIntArray("scores", []int32{1, 2, 3})
+	sfsobject.PutUtfString("Password", "hunter2")
+	return sfsobject
+}
+
+func TestLogValue(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+	logger.Info("login", "data", logTestObject())
+	want := `level=INFO msg=login data.Password=[REDACTED] data.items.0=7 data.items.1=<nil> ` +
+		`data.name=Player data.pos.x=0.1 data.pos.y=-2 data.scores="[1 2 3]"` + "\n"
+	if got := output.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	output.Reset()
+	options := LogOptions{MaxStringLength: 2, MaxArrayLength: 1, RedactKeys: []string{"NAME"}}
+	logger.Info("login", "data", logTestObject().LogValueWithOptions(options))
+	want = `level=INFO msg=login data.Password="hu...(5 more bytes)" data.items.0=7 data.items....="1 more" ` +
+		`data.name=[REDACTED] data.pos.x=0.1 data.pos.y=-2 data.scores="[1 ...(2 more)]"` + "\n"
+	if got := output.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	var nilObject *SFSObject
+	var nilArray *SFSArray
+	if nilObject.LogValue().Any() != nil || nilArray.LogValue().Any() != nil {
+		t.Error("nil containers should log as nil")
+	}
+}
+
+func TestLogValueOfWrappers(t *testing.T) {
+	sfsobject := logTestObject()
+	want := sfsobject.LogValue().String()
+	if got := sfsobject.Freeze().LogValue().String(); got != want {
+		t.Errorf("FrozenSFSObject: got %s, want %s", got, want)
+	}
+	if got := NewSyncSFSObjectFrom(sfsobject).LogValue().String(); got != want {
+		t.Errorf("SyncSFSObject: got %s, want %s", got, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	sfsobject := logTestObject()
+	items, _ := sfsobject.GetSFSArray("items")
+	compact := `{Password:[REDACTED] items:[7 null] name:"Player" pos:{x:0.1 y:-2} scores:[1 2 3]}`
+	dump := "(utf_string) Password: [REDACTED]\n" +
+		"(sfs_array) items:\n" +
+		"\t(int) 7\n" +
+		"\t(null)\n" +
+		"(utf_string) name: \"Player\"\n" +
+		"(sfs_object) pos:\n" +
+		"\t(float) x: 0.1\n" +
+		"\t(float) y: -2\n" +
+		"(int_array) scores: [1 2 3]\n"
+	tests := []struct {
+		format string
+		value  interface{}
+		want   string
+	}{
+		{"%v", sfsobject, compact},
+		{"%s", sfsobject, compact},
+		{"%+v", sfsobject, dump},
+		{"%d", sfsobject, "%!d(*sfstypes.SFSObject)"},
+		{"%v", items, "[7 null]"},
+		{"%+v", items, "(int) 7\n(null)\n"},
+		{"%d", NewSFSArray(), "%!d(*sfstypes.SFSArray)"},
+		{"%v", (*SFSObject)(nil), "<nil>"},
+		{"%v", (*SFSArray)(nil), "<nil>"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprintf(test.format, test.value); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.format, got, test.want)
+		}
+	}
+}
+
+func TestFormatTruncates(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutUtfString("long", strings.Repeat("é", 200))
+	sfsobject.PutByteArray("bytes", make([]int8, 40))
+	sfsarray := NewSFSArray()
+	for i := 0; i < 40; i++ {
+		sfsarray.AddInt(int32(i))
+	}
+	sfsobject.PutSFSArray("list", sfsarray)
+
+	got := fmt.Sprintf("%v", sfsobject)
+	wantLong := `long:"` + strings.Repeat("é", 128) + `"...(144 more bytes)`
+	if !strings.Contains(got, wantLong) {
+		t.Errorf("string wasn't cut at a rune boundary after 256 bytes:\n%s", got)
+	}
+	if !strings.Contains(got, "bytes:["+strings.Repeat("00", 32)+"]...(8 more)") {
+		t.Errorf("byte array wasn't cut after 32 elements:\n%s", got)
+	}
+	if !strings.Contains(got, " 31 ...(8 more)]") {
+		t.Errorf("SFSArray wasn't cut after 32 elements:\n%s", got)
+	}
+	if dump := fmt.Sprintf("%+v", sfsobject); !strings.Contains(dump, "\t...(8 more)\n") {
+		t.Errorf("%%+v didn't cut the SFSArray:\n%s", dump)
+	}
+	if full := sfsobject.GetDump(); strings.Contains(full, "more") {
+		t.Errorf("GetDump shouldn't truncate:\n%s", full)
+	}
+}
+
+func TestFormatHexRedacts(t *testing.T) {
+	nested := NewSFSObject()
+	nested.PutUtfString("token", "secret")
+	sfsarray := NewSFSArray()
+	sfsarray.AddSFSObject(nested)
+	sfsobject := NewSFSObject()
+	sfsobject.PutUtfString("pw", "secret")
+	sfsobject.PutSFSArray("list", sfsarray)
+
+	want := NewSFSObject()
+	want.PutUtfString("pw", redactedValue)
+	wantNested := NewSFSObject()
+	wantNested.PutUtfString("token", redactedValue)
+	wantArray := NewSFSArray()
+	wantArray.AddSFSObject(wantNested)
+	want.PutSFSArray("list", wantArray)
+
+	if got := fmt.Sprintf("%x", sfsobject); got != fmt.Sprintf("%x", want.ToBinary()) {
+		t.Errorf("%%x: got %s", got)
+	}
+	if got := fmt.Sprintf("%X", sfsarray); got != fmt.Sprintf("%X", wantArray.ToBinary()) {
+		t.Errorf("%%X: got %s", got)
+	}
+	if strings.Contains(fmt.Sprintf("% x", sfsobject), fmt.Sprintf("% x", "secret")) {
+		t.Error("% x leaked a redacted value")
+	}
+	if value, _ := nested.GetUtfString("token"); value != "secret" {
+		t.Error("formatting modified the object")
+	}
+}
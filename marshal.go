@@ -0,0 +1,128 @@
+package sfstypes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// The standard marshaling interfaces let SFSObjects and SFSArrays be embedded
+// in values handled by encoding/json, encoding/gob and similar packages.
+// Binary, gob and text forms carry the SFS2X binary encoding and keep every
+// type; the JSON form is the one of ToJson and loses the distinction between
+// numeric and array types, like NewSFSObjectFromJsonData.
+//
+// The Unmarshal and Decode methods replace the contents of the receiver and
+// keep its numeric policy and SQL format.
+
+func (sfsobject *SFSObject) MarshalBinary() ([]byte, error) {
+	return sfsobject.ToBinary(), nil
+}
+
+func (sfsobject *SFSObject) UnmarshalBinary(data []byte) error {
+	decoded, err := NewSFSObjectFromBinaryData(data)
+	if err != nil {
+		return err
+	}
+	sfsobject.replace(decoded)
+	return nil
+}
+
+func (sfsobject *SFSObject) GobEncode() ([]byte, error) {
+	return sfsobject.MarshalBinary()
+}
+
+func (sfsobject *SFSObject) GobDecode(data []byte) error {
+	return sfsobject.UnmarshalBinary(data)
+}
+
+// MarshalText returns the binary encoding in standard base64.
+func (sfsobject *SFSObject) MarshalText() ([]byte, error) {
+	return marshalBase64(sfsobject.ToBinary()), nil
+}
+
+func (sfsobject *SFSObject) UnmarshalText(text []byte) error {
+	data, err := base64.StdEncoding.AppendDecode(nil, text)
+	if err != nil {
+		return err
+	}
+	return sfsobject.UnmarshalBinary(data)
+}
+
+func (sfsobject *SFSObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(convertSFSObjectToMap(sfsobject))
+}
+
+// UnmarshalJSON decodes a JSON object like NewSFSObjectFromJsonData. A JSON
+// null leaves the receiver unchanged.
+func (sfsobject *SFSObject) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	decoded, err := NewSFSObjectFromJsonData(string(data))
+	if err != nil {
+		return err
+	}
+	sfsobject.replace(decoded)
+	return nil
+}
+
+func (sfsobject *SFSObject) replace(decoded *SFSObject) {
+	sfsobject.dataHolder = decoded.dataHolder
+	sfsobject.peakSize = decoded.peakSize
+}
+
+func (sfsarray *SFSArray) MarshalBinary() ([]byte, error) {
+	return sfsarray.ToBinary(), nil
+}
+
+func (sfsarray *SFSArray) UnmarshalBinary(data []byte) error {
+	decoded, err := NewSFSArrayFromBinaryData(data)
+	if err != nil {
+		return err
+	}
+	sfsarray.dataHolder = decoded.dataHolder
+	return nil
+}
+
+func (sfsarray *SFSArray) GobEncode() ([]byte, error) {
+	return sfsarray.MarshalBinary()
+}
+
+func (sfsarray *SFSArray) GobDecode(data []byte) error {
+	return sfsarray.UnmarshalBinary(data)
+}
+
+// MarshalText returns the binary encoding in standard base64.
+func (sfsarray *SFSArray) MarshalText() ([]byte, error) {
+	return marshalBase64(sfsarray.ToBinary()), nil
+}
+
+func (sfsarray *SFSArray) UnmarshalText(text []byte) error {
+	data, err := base64.StdEncoding.AppendDecode(nil, text)
+	if err != nil {
+		return err
+	}
+	return sfsarray.UnmarshalBinary(data)
+}
+
+func (sfsarray *SFSArray) MarshalJSON() ([]byte, error) {
+	return json.Marshal(convertSFSArrayToSlice(sfsarray))
+}
+
+// UnmarshalJSON decodes a JSON array like NewSFSArrayFromJsonData. A JSON
+// null leaves the receiver unchanged.
+func (sfsarray *SFSArray) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	decoded, err := NewSFSArrayFromJsonData(string(data))
+	if err != nil {
+		return err
+	}
+	sfsarray.dataHolder = decoded.dataHolder
+	return nil
+}
+
+func marshalBase64(data []byte) []byte {
+	return base64.StdEncoding.AppendEncode(nil, data)
+}
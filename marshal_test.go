@@ -0,0 +1,148 @@
+package sfstypes
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+func marshalTestObject() *SFSObject {
+	nested := NewSFSArray()
+	nested.AddShort(1)
+	nested.AddUtfString("a")
+	sfsobject := NewSFSObject()
+	sfsobject.PutLong("id", 1<<40)
+	sfsobject.PutFloatArray("pos", []float32{1.5, -2})
+	sfsobject.PutSFSArray("nested", nested)
+	return sfsobject
+}
+
+func TestBinaryAndTextMarshaling(t *testing.T) {
+	sfsobject := marshalTestObject()
+	want := sfsobject.ToBinary()
+
+	data, err := sfsobject.MarshalBinary()
+	if err != nil || !bytes.Equal(data, want) {
+		t.Fatalf("MarshalBinary = %x, %v", data, err)
+	}
+	var decoded SFSObject
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.ToBinary(); !bytes.Equal(got, want) {
+		t.Fatalf("UnmarshalBinary: got %x, want %x", got, want)
+	}
+
+	text, err := sfsobject.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromText SFSObject
+	if err := fromText.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if got := fromText.ToBinary(); !bytes.Equal(got, want) {
+		t.Fatalf("UnmarshalText: got %x, want %x", got, want)
+	}
+
+	sfsarray := NewSFSArray()
+	sfsarray.AddSFSObject(sfsobject)
+	text, err = sfsarray.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var array SFSArray
+	if err := array.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if got := array.ToBinary(); !bytes.Equal(got, sfsarray.ToBinary()) {
+		t.Fatalf("SFSArray UnmarshalText: got %x, want %x", got, sfsarray.ToBinary())
+	}
+
+	if err := decoded.UnmarshalText([]byte("not base64!")); err == nil {
+		t.Error("UnmarshalText accepted invalid base64")
+	}
+	if err := decoded.UnmarshalBinary([]byte{0x12, 0x00}); err == nil {
+		t.Error("UnmarshalBinary accepted truncated data")
+	}
+	if got := decoded.ToBinary(); !bytes.Equal(got, want) {
+		t.Error("failed unmarshaling changed the receiver")
+	}
+}
+
+func TestGobKeepsTypes(t *testing.T) {
+	type message struct {
+		Name    string
+		Payload *SFSObject
+		Items   *SFSArray
+	}
+	in := message{Name: "m", Payload: marshalTestObject(), Items: NewSFSArray()}
+	in.Items.AddByte(-1)
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(in); err != nil {
+		t.Fatal(err)
+	}
+	var out message
+	if err := gob.NewDecoder(&buffer).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Payload.ToBinary(), in.Payload.ToBinary()) {
+		t.Errorf("payload: got %x, want %x", out.Payload.ToBinary(), in.Payload.ToBinary())
+	}
+	if !bytes.Equal(out.Items.ToBinary(), in.Items.ToBinary()) {
+		t.Errorf("items: got %x, want %x", out.Items.ToBinary(), in.Items.ToBinary())
+	}
+}
+
+func TestJsonMarshaling(t *testing.T) {
+	type message struct {
+		Payload *SFSObject `json:"payload"`
+		Items   *SFSArray  `json:"items"`
+	}
+	in := message{Payload: NewSFSObject(), Items: NewSFSArray()}
+	in.Payload.PutInt("id", 7)
+	in.Items.AddUtfString("a")
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"payload":{"id":7},"items":["a"]}`; string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+
+	out := message{Payload: NewSFSObject(), Items: NewSFSArray()}
+	out.Payload.SetNumericPolicy(NumericLong)
+	out.Payload.PutBool("old", true)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Payload.ContainsKey("old") {
+		t.Error("UnmarshalJSON kept the old contents")
+	}
+	// The JSON form has no integer types, so numbers come back as DOUBLE.
+	if id, err := out.Payload.GetDouble("id"); err != nil || id != 7 {
+		t.Errorf("id = %v, %v", id, err)
+	}
+	if err := out.Payload.Put("n", 1); err != nil {
+		t.Fatal(err)
+	}
+	if typeId, _ := out.Payload.TypeOf("n"); typeId != TypeLong {
+		t.Errorf("numeric policy was lost: Put stored %v", typeId)
+	}
+	if s, err := out.Items.GetUtfString(0); err != nil || s != "a" {
+		t.Errorf("items[0] = %q, %v", s, err)
+	}
+
+	if err := out.Payload.UnmarshalJSON([]byte("null")); err != nil || !out.Payload.ContainsKey("id") {
+		t.Errorf("null changed the receiver: %v", err)
+	}
+	if err := out.Items.UnmarshalJSON([]byte("null")); err != nil || out.Items.Size() != 1 {
+		t.Errorf("null changed the array: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"payload": [1]}`), &out); err == nil {
+		t.Error("an array was accepted as an SFSObject")
+	}
+}
@@ -0,0 +1,347 @@
+package sfstypes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// MessagePack conversion maps every SFS type onto the closest MessagePack
+// type, so that converting back restores the same types:
+//
+//	NULL, BOOL              nil, bool
+//	BYTE, SHORT, INT, LONG  int 8, int 16, int 32, int 64 (never the compact forms)
+//	FLOAT, DOUBLE           float 32, float 64
+//	UTF_STRING              str
+//	BYTE_ARRAY              bin
+//	SFS_OBJECT, SFS_ARRAY   map, array
+//	TEXT and typed arrays   ext, with the SFS type id as extension type
+//
+// The payload of an extension is the SFS2X binary encoding of the value
+// without its leading type id. When reading MessagePack from other sources,
+// fixints map to BYTE, unsigned formats to the next wider signed type and str
+// longer than a UTF_STRING can hold to TEXT.
+
+func (sfsobject *SFSObject) ToMsgPack() []byte {
+	return appendMsgPack(nil, TypeSFSObject, sfsobject)
+}
+
+func (sfsarray *SFSArray) ToMsgPack() []byte {
+	return appendMsgPack(nil, TypeSFSArray, sfsarray)
+}
+
+func NewSFSObjectFromMsgPackData(data []byte) (*SFSObject, error) {
+	wrapper, err := decodeMsgPackContainer(data, TypeSFSObject)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSObject), nil
+}
+
+func NewSFSArrayFromMsgPackData(data []byte) (*SFSArray, error) {
+	wrapper, err := decodeMsgPackContainer(data, TypeSFSArray)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSArray), nil
+}
+
+func appendMsgPack(dst []byte, typeId DataType, data interface{}) []byte {
+	switch typeId {
+	case TypeNull:
+		return append(dst, 0xc0)
+	case TypeBool:
+		if data.(bool) {
+			return append(dst, 0xc3)
+		}
+		return append(dst, 0xc2)
+	case TypeByte:
+		return append(dst, 0xd0, byte(data.(int8)))
+	case TypeShort:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(data.(int16)))
+	case TypeInt:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(data.(int32)))
+	case TypeLong:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(data.(int64)))
+	case TypeFloat:
+		return binary.BigEndian.AppendUint32(append(dst, 0xca), math.Float32bits(data.(float32)))
+	case TypeDouble:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcb), math.Float64bits(data.(float64)))
+	case TypeUtfString:
+		return appendMsgPackString(dst, data.(string))
+	case TypeByteArray:
+		bytes := data.([]byte)
+		dst = appendMsgPackLength(dst, len(bytes), 0, 0xc4, 0xc5, 0xc6)
+		return append(dst, bytes...)
+	case TypeSFSObject:
+		sfsobject := data.(*SFSObject)
+		dst = appendMsgPackLength(dst, len(sfsobject.dataHolder), 0x80, 0, 0xde, 0xdf)
+		for _, key := range sfsobject.sortedKeys() {
+			wrapper := sfsobject.dataHolder[key]
+			dst = appendMsgPackString(dst, key)
+			dst = appendMsgPack(dst, wrapper.typeId, wrapper.data)
+		}
+		return dst
+	case TypeSFSArray:
+		sfsarray := data.(*SFSArray)
+		dst = appendMsgPackLength(dst, len(sfsarray.dataHolder), 0x90, 0, 0xdc, 0xdd)
+		for _, wrapper := range sfsarray.dataHolder {
+			dst = appendMsgPack(dst, wrapper.typeId, wrapper.data)
+		}
+		return dst
+	default:
+		// The extension type follows the length, so appendData writes it
+		// along with the payload.
+		length := dataSize(typeId, data) - 1
+		switch length {
+		case 1:
+			dst = append(dst, 0xd4)
+		case 2:
+			dst = append(dst, 0xd5)
+		case 4:
+			dst = append(dst, 0xd6)
+		case 8:
+			dst = append(dst, 0xd7)
+		case 16:
+			dst = append(dst, 0xd8)
+		default:
+			dst = appendMsgPackLength(dst, length, 0, 0xc7, 0xc8, 0xc9)
+		}
+		return appendData(dst, typeId, data)
+	}
+}
+
+func appendMsgPackString(dst []byte, str string) []byte {
+	if len(str) < 32 {
+		dst = append(dst, 0xa0|byte(len(str)))
+	} else {
+		dst = appendMsgPackLength(dst, len(str), 0, 0xd9, 0xda, 0xdb)
+	}
+	return append(dst, str...)
+}
+
+// appendMsgPackLength writes the header of a format family: a fix format with
+// the length in the low four bits if fix is set, or the 8, 16 or 32 bit
+// variant. Families without an 8 bit variant pass 0 for format8.
+func appendMsgPackLength(dst []byte, length int, fix byte, format8 byte, format16 byte, format32 byte) []byte {
+	switch {
+	case fix != 0 && length < 16:
+		return append(dst, fix|byte(length))
+	case format8 != 0 && length <= math.MaxUint8:
+		return append(dst, format8, byte(length))
+	case length <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, format16), uint16(length))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, format32), uint32(length))
+	}
+}
+
+func decodeMsgPackContainer(data []byte, typeId DataType) (sfsDataWrapper, error) {
+	decoder := sfsDecoder{data: data}
+	wrapper, err := decoder.decodeMsgPack()
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	if wrapper.typeId != typeId {
+		return sfsDataWrapper{}, &ErrWrongType{ActualType: wrapper.typeId, WantedType: typeId}
+	}
+	if decoder.remaining() > 0 {
+		return sfsDataWrapper{}, fmt.Errorf("%d bytes of trailing data after MessagePack value", decoder.remaining())
+	}
+	return wrapper, nil
+}
+
+// decodeMsgPack reads the next MessagePack value.
+func (decoder *sfsDecoder) decodeMsgPack() (sfsDataWrapper, error) {
+	header, err := decoder.readByte("MessagePack header")
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	switch {
+	case header <= 0x7f || header >= 0xe0:
+		return sfsDataWrapper{typeId: TypeByte, data: int8(header)}, nil
+	case header <= 0x8f:
+		return decoder.decodeMsgPackMap(int(header & 0x0f))
+	case header <= 0x9f:
+		return decoder.decodeMsgPackArray(int(header & 0x0f))
+	case header <= 0xbf:
+		return decoder.decodeMsgPackString(int(header & 0x1f))
+	}
+	switch header {
+	case 0xc0:
+		return sfsDataWrapper{typeId: TypeNull}, nil
+	case 0xc2, 0xc3:
+		return sfsDataWrapper{typeId: TypeBool, data: header == 0xc3}, nil
+	case 0xc4, 0xc5, 0xc6:
+		length, err := decoder.readMsgPackLength(header - 0xc4)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		bytes, err := decoder.read(length, "MessagePack bin")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		results := make([]byte, length)
+		copy(results, bytes)
+		return sfsDataWrapper{typeId: TypeByteArray, data: results}, nil
+	case 0xc7, 0xc8, 0xc9:
+		length, err := decoder.readMsgPackLength(header - 0xc7)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return decoder.decodeMsgPackExt(length)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return decoder.decodeMsgPackExt(1 << (header - 0xd4))
+	case 0xca:
+		input, err := decoder.readUint32("MessagePack float 32")
+		return sfsDataWrapper{typeId: TypeFloat, data: math.Float32frombits(input)}, err
+	case 0xcb:
+		input, err := decoder.readUint64("MessagePack float 64")
+		return sfsDataWrapper{typeId: TypeDouble, data: math.Float64frombits(input)}, err
+	case 0xcc:
+		input, err := decoder.readByte("MessagePack uint 8")
+		return sfsDataWrapper{typeId: TypeShort, data: int16(input)}, err
+	case 0xcd:
+		input, err := decoder.readUint16("MessagePack uint 16")
+		return sfsDataWrapper{typeId: TypeInt, data: int32(input)}, err
+	case 0xce:
+		input, err := decoder.readUint32("MessagePack uint 32")
+		return sfsDataWrapper{typeId: TypeLong, data: int64(input)}, err
+	case 0xcf:
+		input, err := decoder.readUint64("MessagePack uint 64")
+		if err == nil && input > math.MaxInt64 {
+			err = &ErrNumericOverflow{Value: input, Type: TypeLong}
+		}
+		return sfsDataWrapper{typeId: TypeLong, data: int64(input)}, err
+	case 0xd0:
+		input, err := decoder.readByte("MessagePack int 8")
+		return sfsDataWrapper{typeId: TypeByte, data: int8(input)}, err
+	case 0xd1:
+		input, err := decoder.readUint16("MessagePack int 16")
+		return sfsDataWrapper{typeId: TypeShort, data: int16(input)}, err
+	case 0xd2:
+		input, err := decoder.readUint32("MessagePack int 32")
+		return sfsDataWrapper{typeId: TypeInt, data: int32(input)}, err
+	case 0xd3:
+		input, err := decoder.readUint64("MessagePack int 64")
+		return sfsDataWrapper{typeId: TypeLong, data: int64(input)}, err
+	case 0xd9, 0xda, 0xdb:
+		length, err := decoder.readMsgPackLength(header - 0xd9)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return decoder.decodeMsgPackString(length)
+	case 0xdc, 0xdd:
+		length, err := decoder.readMsgPackLength(header - 0xdc + 1)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return decoder.decodeMsgPackArray(length)
+	case 0xde, 0xdf:
+		length, err := decoder.readMsgPackLength(header - 0xde + 1)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return decoder.decodeMsgPackMap(length)
+	}
+	return sfsDataWrapper{}, fmt.Errorf("unsupported MessagePack format 0x%02x", header)
+}
+
+// readMsgPackLength reads an 8, 16 or 32 bit length for size 0, 1 or 2.
+func (decoder *sfsDecoder) readMsgPackLength(size byte) (int, error) {
+	switch size {
+	case 0:
+		length, err := decoder.readByte("MessagePack length")
+		return int(length), err
+	case 1:
+		length, err := decoder.readUint16("MessagePack length")
+		return int(length), err
+	default:
+		length, err := decoder.readUint32("MessagePack length")
+		return int(length), err
+	}
+}
+
+func (decoder *sfsDecoder) decodeMsgPackString(length int) (sfsDataWrapper, error) {
+	bytes, err := decoder.read(length, "MessagePack str")
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	if length > maxUtfStringLength {
+		return sfsDataWrapper{typeId: TypeText, data: string(bytes)}, nil
+	}
+	return sfsDataWrapper{typeId: TypeUtfString, data: string(bytes)}, nil
+}
+
+// decodeMsgPackExt decodes an extension holding TEXT or a typed array.
+func (decoder *sfsDecoder) decodeMsgPackExt(length int) (sfsDataWrapper, error) {
+	// The extension type and payload together are the SFS2X encoding.
+	start := decoder.offset
+	extType, err := decoder.readByte("MessagePack ext type")
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	if _, err := decoder.read(length, "MessagePack ext data"); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	typeId := DataType(extType)
+	if typeId != TypeText && (typeId < TypeBoolArray || typeId > TypeUtfStringArray) {
+		return sfsDataWrapper{}, fmt.Errorf("unsupported MessagePack extension type %d", int8(extType))
+	}
+	extDecoder := sfsDecoder{data: decoder.data[start:decoder.offset]}
+	wrapper, err := extDecoder.decodeData()
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	if extDecoder.remaining() > 0 {
+		return sfsDataWrapper{}, fmt.Errorf("%d bytes of trailing data in MessagePack extension %s", extDecoder.remaining(), typeId.Name())
+	}
+	return wrapper, nil
+}
+
+func (decoder *sfsDecoder) decodeMsgPackMap(size int) (sfsDataWrapper, error) {
+	if size > math.MaxUint16 {
+		return sfsDataWrapper{}, fmt.Errorf("MessagePack map with %d entries is too large for an SFSObject", size)
+	}
+	if err := decoder.enter(); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	defer decoder.leave()
+	sfsobject := NewSFSObject()
+	for i := 0; i < size; i++ {
+		key, err := decoder.decodeMsgPack()
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		if key.typeId != TypeUtfString {
+			return sfsDataWrapper{}, fmt.Errorf("MessagePack map key of type %s is not a string", key.typeId.Name())
+		}
+		value, err := decoder.decodeMsgPack()
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		if err := sfsobject.putsfsDataWrapper(key.data.(string), &value); err != nil {
+			return sfsDataWrapper{}, err
+		}
+	}
+	return sfsDataWrapper{typeId: TypeSFSObject, data: sfsobject}, nil
+}
+
+func (decoder *sfsDecoder) decodeMsgPackArray(size int) (sfsDataWrapper, error) {
+	if size > math.MaxUint16 {
+		return sfsDataWrapper{}, fmt.Errorf("MessagePack array with %d elements is too large for an SFSArray", size)
+	}
+	if err := decoder.enter(); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	defer decoder.leave()
+	sfsarray := &SFSArray{dataHolder: make([]sfsDataWrapper, 0, min(size, decoder.remaining()))}
+	for i := 0; i < size; i++ {
+		value, err := decoder.decodeMsgPack()
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		sfsarray.addsfsDataWrapper(value)
+	}
+	return sfsDataWrapper{typeId: TypeSFSArray, data: sfsarray}, nil
+}
@@ -0,0 +1,156 @@
+package sfstypes
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMsgPackRoundTrip(t *testing.T) {
+	objects := fuzzSeedObjects()
+	for _, data := range readGoldenCorpus(t) {
+		sfsobject, err := NewSFSObjectFromBinaryData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, sfsobject)
+	}
+	for _, sfsobject := range objects {
+		decoded, err := NewSFSObjectFromMsgPackData(sfsobject.ToMsgPack())
+		if err != nil {
+			t.Fatalf("%x: %v", sfsobject.ToMsgPack(), err)
+		}
+		if got, want := decoded.ToBinary(), sfsobject.ToBinary(); !bytes.Equal(got, want) {
+			t.Fatalf("round trip changed the payload:\n%x\n%x", want, got)
+		}
+	}
+}
+
+func TestMsgPackEncoding(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("b", 1)
+	sfsobject.PutByte("a", 1)
+	sfsobject.PutNull("n")
+	want := []byte{
+		0x83,
+		0xa1, 'a', 0xd0, 0x01,
+		0xa1, 'b', 0xd2, 0x00, 0x00, 0x00, 0x01,
+		0xa1, 'n', 0xc0,
+	}
+	if got := sfsobject.ToMsgPack(); !bytes.Equal(got, want) {
+		t.Fatalf("got %x, want %x", got, want)
+	}
+}
+
+func TestMsgPackExtensions(t *testing.T) {
+	tests := []struct {
+		typeId DataType
+		data   interface{}
+		want   []byte
+	}{
+		{TypeText, "", []byte{0xd6, 20, 0, 0, 0, 0}},
+		{TypeText, "abc", []byte{0xc7, 7, 20, 0, 0, 0, 3, 'a', 'b', 'c'}},
+		{TypeBoolArray, []bool{true}, []byte{0xc7, 3, 9, 0, 1, 1}},
+		{TypeShortArray, []int16{-1}, []byte{0xd6, 11, 0, 1, 0xff, 0xff}},
+		{TypeIntArray, []int32{1}, []byte{0xc7, 6, 12, 0, 1, 0, 0, 0, 1}},
+		{TypeLongArray, []int64{}, []byte{0xd5, 13, 0, 0}},
+		{TypeFloatArray, []float32{1}, []byte{0xc7, 6, 14, 0, 1, 0x3f, 0x80, 0, 0}},
+		{TypeDoubleArray, []float64{1, 2, 3, 4, 5, 6, 7}, nil},
+		{TypeUtfStringArray, []string{"a"}, []byte{0xc7, 5, 16, 0, 1, 0, 1, 'a'}},
+	}
+	for _, test := range tests {
+		sfsarray := NewSFSArray()
+		sfsarray.addsfsDataWrapper(sfsDataWrapper{typeId: test.typeId, data: test.data})
+		encoded := sfsarray.ToMsgPack()
+		if test.want != nil && !bytes.Equal(encoded[1:], test.want) {
+			t.Errorf("%v: got %x, want %x", test.typeId, encoded[1:], test.want)
+		}
+		decoded, err := NewSFSArrayFromMsgPackData(encoded)
+		if err != nil {
+			t.Errorf("%v: %v", test.typeId, err)
+			continue
+		}
+		if !bytes.Equal(decoded.ToBinary(), sfsarray.ToBinary()) {
+			t.Errorf("%v: round trip changed %x to %x", test.typeId, sfsarray.ToBinary(), decoded.ToBinary())
+		}
+	}
+}
+
+func TestMsgPackFromOtherProducers(t *testing.T) {
+	long := strings.Repeat("x", maxUtfStringLength+1)
+	tests := []struct {
+		name     string
+		value    []byte
+		wantType DataType
+		wantData interface{}
+	}{
+		{"positive fixint", []byte{0x7f}, TypeByte, int8(127)},
+		{"negative fixint", []byte{0xe0}, TypeByte, int8(-32)},
+		{"uint 8", []byte{0xcc, 0xff}, TypeShort, int16(255)},
+		{"uint 16", []byte{0xcd, 0xff, 0xff}, TypeInt, int32(65535)},
+		{"uint 32", []byte{0xce, 0xff, 0xff, 0xff, 0xff}, TypeLong, int64(math.MaxUint32)},
+		{"uint 64", []byte{0xcf, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, TypeLong, int64(math.MaxInt64)},
+		{"str 8", append([]byte{0xd9, 3}, "abc"...), TypeUtfString, "abc"},
+		{"str 32", append([]byte{0xdb, 0, 0, 0x80, 0}, long...), TypeText, long},
+		{"bin 16", []byte{0xc5, 0, 2, 1, 2}, TypeByteArray, []byte{1, 2}},
+		{"array 16", []byte{0xdc, 0, 0}, TypeSFSArray, nil},
+		{"map 32", []byte{0xdf, 0, 0, 0, 0}, TypeSFSObject, nil},
+	}
+	for _, test := range tests {
+		data := append([]byte{0x81, 0xa1, 'k'}, test.value...)
+		sfsobject, err := NewSFSObjectFromMsgPackData(data)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		wrapper := sfsobject.dataHolder["k"]
+		if wrapper.typeId != test.wantType {
+			t.Errorf("%s: got %v, want %v", test.name, wrapper.typeId, test.wantType)
+		} else if test.wantData != nil && !reflect.DeepEqual(wrapper.data, test.wantData) {
+			t.Errorf("%s: got %#v, want %#v", test.name, wrapper.data, test.wantData)
+		}
+	}
+}
+
+func TestMsgPackRejectsCorruptInput(t *testing.T) {
+	valid := fuzzSeedObjects()[1].ToMsgPack()
+	for length := 0; length < len(valid); length++ {
+		if _, err := NewSFSObjectFromMsgPackData(valid[:length]); err == nil {
+			t.Fatalf("truncated to %d of %d bytes: accepted", length, len(valid))
+		}
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"trailing data", []byte{0x80, 0xc0}},
+		{"not a map", []byte{0x90}},
+		{"integer key", []byte{0x81, 0x01, 0xc0}},
+		{"uint 64 overflow", []byte{0x81, 0xa1, 'k', 0xcf, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{"unsupported format", []byte{0x81, 0xa1, 'k', 0xc1}},
+		{"unknown extension", []byte{0x81, 0xa1, 'k', 0xd4, 0x01, 0x00}},
+		{"negative extension", []byte{0x81, 0xa1, 'k', 0xd4, 0xff, 0x00}},
+		{"object extension", []byte{0x81, 0xa1, 'k', 0xd5, 18, 0, 0}},
+		{"extension with trailing data", []byte{0x81, 0xa1, 'k', 0xd7, 20, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{"extension length mismatch", []byte{0x81, 0xa1, 'k', 0xd6, 11, 0, 2, 0, 0}},
+		{"huge bin", []byte{0x81, 0xa1, 'k', 0xc6, 0xff, 0xff, 0xff, 0xff}},
+		{"huge map", []byte{0xdf, 0x00, 0x01, 0x00, 0x00}},
+		{"huge array", []byte{0x81, 0xa1, 'k', 0xdd, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, test := range tests {
+		if _, err := NewSFSObjectFromMsgPackData(test.data); err == nil {
+			t.Errorf("%s: accepted", test.name)
+		}
+	}
+
+	deep := bytes.Repeat([]byte{0x91}, maxDecodeDepth)
+	deep = append(append([]byte{0x81, 0xa1, 'k'}, deep...), 0x90)
+	var tooDeep *ErrMaxDepthExceeded
+	if _, err := NewSFSObjectFromMsgPackData(deep); !errors.As(err, &tooDeep) {
+		t.Errorf("deep nesting: got %v, want ErrMaxDepthExceeded", err)
+	}
+}
@@ -0,0 +1,237 @@
+package sfstypes
+
+import (
+	"math"
+	"reflect"
+)
+
+// NumericPolicy decides which SFS type Put and Add use for Go integer kinds
+// that have no SFS type of the same width (int, uint, uint16, uint32 and
+// uint64). Sized signed kinds always map to their exact SFS type, and uint8
+// maps to BYTE. Named types are treated like their underlying kind.
+type NumericPolicy int
+
+const (
+	// NumericStrict keeps the width of the Go kind (int→INT, uint16→SHORT,
+	// uint32→INT, uint/uint64→LONG) and fails with ErrNumericOverflow if a
+	// value doesn't fit.
+	NumericStrict NumericPolicy = iota
+	// NumericFit picks the smallest of BYTE, SHORT, INT and LONG that holds
+	// the value. Slices use the smallest of SHORT_ARRAY, INT_ARRAY and
+	// LONG_ARRAY that holds every element.
+	NumericFit
+	// NumericLong always uses LONG and LONG_ARRAY.
+	NumericLong
+)
+
+func (sfsobject *SFSObject) SetNumericPolicy(policy NumericPolicy) {
+	sfsobject.numericPolicy = policy
+}
+
+func (sfsarray *SFSArray) SetNumericPolicy(policy NumericPolicy) {
+	sfsarray.numericPolicy = policy
+}
+
+// convertValue converts values Put and Add have no exact case for: int and
+// unsigned kinds, named types and slices of those.
+func convertValue(value interface{}, policy NumericPolicy) (sfsDataWrapper, error) {
+	reflectValue := reflect.ValueOf(value)
+	switch reflectValue.Kind() {
+	case reflect.Bool:
+		return sfsDataWrapper{typeId: TypeBool, data: reflectValue.Bool()}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		kind := reflectValue.Kind()
+		typeId, fits := integerType(reflectValue.Int(), true, kindType(kind), kindPolicy(kind, policy))
+		if !fits {
+			return sfsDataWrapper{}, &ErrNumericOverflow{Value: value, Type: typeId}
+		}
+		return sfsDataWrapper{typeId: typeId, data: integerData(typeId, reflectValue.Int())}, nil
+	case reflect.Uint8:
+		return sfsDataWrapper{typeId: TypeByte, data: int8(reflectValue.Uint())}, nil
+	case reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		unsigned := reflectValue.Uint()
+		typeId, fits := integerType(int64(unsigned), unsigned <= math.MaxInt64, kindType(reflectValue.Kind()), policy)
+		if !fits {
+			return sfsDataWrapper{}, &ErrNumericOverflow{Value: value, Type: typeId}
+		}
+		return sfsDataWrapper{typeId: typeId, data: integerData(typeId, int64(unsigned))}, nil
+	case reflect.Float32:
+		return sfsDataWrapper{typeId: TypeFloat, data: float32(reflectValue.Float())}, nil
+	case reflect.Float64:
+		return sfsDataWrapper{typeId: TypeDouble, data: reflectValue.Float()}, nil
+	case reflect.String:
+		return sfsDataWrapper{typeId: TypeUtfString, data: reflectValue.String()}, nil
+	case reflect.Slice:
+		return convertSlice(value, reflectValue, policy)
+	}
+	return sfsDataWrapper{}, &ErrUnsupportedType{Value: value}
+}
+
+func convertSlice(value interface{}, reflectValue reflect.Value, policy NumericPolicy) (sfsDataWrapper, error) {
+	length := reflectValue.Len()
+	switch kind := reflectValue.Type().Elem().Kind(); kind {
+	case reflect.Bool:
+		result := make([]bool, length)
+		for i := range result {
+			result[i] = reflectValue.Index(i).Bool()
+		}
+		return sfsDataWrapper{typeId: TypeBoolArray, data: result}, nil
+	case reflect.Uint8:
+		result := make([]byte, length)
+		for i := range result {
+			result[i] = byte(reflectValue.Index(i).Uint())
+		}
+		return sfsDataWrapper{typeId: TypeByteArray, data: result}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		elements := make([]int64, length)
+		policy = kindPolicy(kind, policy)
+		typeId := arrayElementType(kindType(kind), policy)
+		for i := range elements {
+			element := reflectValue.Index(i)
+			fits := true
+			if element.CanInt() {
+				elements[i] = element.Int()
+			} else {
+				elements[i] = int64(element.Uint())
+				fits = element.Uint() <= math.MaxInt64
+			}
+			elementType, fits := integerType(elements[i], fits, kindType(kind), policy)
+			if !fits {
+				return sfsDataWrapper{}, &ErrNumericOverflow{Value: value, Type: elementType}
+			}
+			if policy == NumericFit && elementType > typeId {
+				typeId = elementType
+			}
+		}
+		return integerArray(typeId, elements), nil
+	case reflect.Float32:
+		result := make([]float32, length)
+		for i := range result {
+			result[i] = float32(reflectValue.Index(i).Float())
+		}
+		return sfsDataWrapper{typeId: TypeFloatArray, data: result}, nil
+	case reflect.Float64:
+		result := make([]float64, length)
+		for i := range result {
+			result[i] = reflectValue.Index(i).Float()
+		}
+		return sfsDataWrapper{typeId: TypeDoubleArray, data: result}, nil
+	case reflect.String:
+		result := make([]string, length)
+		for i := range result {
+			result[i] = reflectValue.Index(i).String()
+		}
+		return sfsDataWrapper{typeId: TypeUtfStringArray, data: result}, nil
+	}
+	return sfsDataWrapper{}, &ErrUnsupportedType{Value: value}
+}
+
+// kindType returns the SFS type NumericStrict uses for an integer kind.
+func kindType(kind reflect.Kind) DataType {
+	switch kind {
+	case reflect.Int8:
+		return TypeByte
+	case reflect.Int16, reflect.Uint16:
+		return TypeShort
+	case reflect.Int32, reflect.Int, reflect.Uint32:
+		return TypeInt
+	default:
+		return TypeLong
+	}
+}
+
+// kindPolicy returns the policy that applies to an integer kind: sized signed
+// kinds always map to their exact SFS type.
+func kindPolicy(kind reflect.Kind, policy NumericPolicy) NumericPolicy {
+	switch kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NumericStrict
+	default:
+		return policy
+	}
+}
+
+// integerType returns the SFS type value is stored as. fitsInt64 is false for
+// unsigned values beyond math.MaxInt64. If the value doesn't fit, the type it
+// exceeds is returned along with false.
+func integerType(value int64, fitsInt64 bool, strictType DataType, policy NumericPolicy) (DataType, bool) {
+	if !fitsInt64 {
+		return TypeLong, false
+	}
+	switch policy {
+	case NumericFit:
+		for _, typeId := range []DataType{TypeByte, TypeShort, TypeInt} {
+			if integerFits(value, typeId) {
+				return typeId, true
+			}
+		}
+		return TypeLong, true
+	case NumericLong:
+		return TypeLong, true
+	default:
+		return strictType, integerFits(value, strictType)
+	}
+}
+
+func arrayElementType(strictType DataType, policy NumericPolicy) DataType {
+	switch policy {
+	case NumericFit:
+		return TypeShort
+	case NumericLong:
+		return TypeLong
+	default:
+		return strictType
+	}
+}
+
+func integerFits(value int64, typeId DataType) bool {
+	switch typeId {
+	case TypeByte:
+		return value >= math.MinInt8 && value <= math.MaxInt8
+	case TypeShort:
+		return value >= math.MinInt16 && value <= math.MaxInt16
+	case TypeInt:
+		return value >= math.MinInt32 && value <= math.MaxInt32
+	default:
+		return true
+	}
+}
+
+func integerData(typeId DataType, value int64) interface{} {
+	switch typeId {
+	case TypeByte:
+		return int8(value)
+	case TypeShort:
+		return int16(value)
+	case TypeInt:
+		return int32(value)
+	default:
+		return value
+	}
+}
+
+func integerArray(typeId DataType, elements []int64) sfsDataWrapper {
+	switch typeId {
+	case TypeByte:
+		result := make([]byte, len(elements))
+		for i, element := range elements {
+			result[i] = byte(element)
+		}
+		return sfsDataWrapper{typeId: TypeByteArray, data: result}
+	case TypeShort:
+		result := make([]int16, len(elements))
+		for i, element := range elements {
+			result[i] = int16(element)
+		}
+		return sfsDataWrapper{typeId: TypeShortArray, data: result}
+	case TypeInt:
+		result := make([]int32, len(elements))
+		for i, element := range elements {
+			result[i] = int32(element)
+		}
+		return sfsDataWrapper{typeId: TypeIntArray, data: result}
+	default:
+		return sfsDataWrapper{typeId: TypeLongArray, data: elements}
+	}
+}
@@ -0,0 +1,132 @@
+package sfstypes
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type (
+	playerID  int32
+	itemCount uint16
+	playerIDs []playerID
+)
+
+func TestNumericPolicies(t *testing.T) {
+	tests := []struct {
+		policy   NumericPolicy
+		value    interface{}
+		wantType DataType
+		wantData interface{}
+	}{
+		{NumericStrict, 5, TypeInt, int32(5)},
+		{NumericStrict, math.MaxInt32, TypeInt, int32(math.MaxInt32)},
+		{NumericStrict, math.MinInt32, TypeInt, int32(math.MinInt32)},
+		{NumericStrict, uint(7), TypeLong, int64(7)},
+		{NumericStrict, uint8(200), TypeByte, int8(-56)},
+		{NumericStrict, uint16(math.MaxInt16), TypeShort, int16(math.MaxInt16)},
+		{NumericStrict, uint32(math.MaxInt32), TypeInt, int32(math.MaxInt32)},
+		{NumericStrict, uint64(math.MaxInt64), TypeLong, int64(math.MaxInt64)},
+		{NumericStrict, playerID(9), TypeInt, int32(9)},
+		{NumericStrict, itemCount(3), TypeShort, int16(3)},
+		{NumericStrict, []int{1, 2}, TypeIntArray, []int32{1, 2}},
+		{NumericStrict, []uint16{1, 2}, TypeShortArray, []int16{1, 2}},
+		{NumericStrict, []uint64{1}, TypeLongArray, []int64{1}},
+		{NumericStrict, playerIDs{4, 5}, TypeIntArray, []int32{4, 5}},
+		{NumericStrict, []itemCount{6}, TypeShortArray, []int16{6}},
+
+		{NumericFit, 0, TypeByte, int8(0)},
+		{NumericFit, math.MaxInt8, TypeByte, int8(math.MaxInt8)},
+		{NumericFit, math.MinInt8, TypeByte, int8(math.MinInt8)},
+		{NumericFit, math.MaxInt8 + 1, TypeShort, int16(math.MaxInt8 + 1)},
+		{NumericFit, math.MinInt8 - 1, TypeShort, int16(math.MinInt8 - 1)},
+		{NumericFit, math.MaxInt16, TypeShort, int16(math.MaxInt16)},
+		{NumericFit, math.MaxInt16 + 1, TypeInt, int32(math.MaxInt16 + 1)},
+		{NumericFit, math.MinInt16 - 1, TypeInt, int32(math.MinInt16 - 1)},
+		{NumericFit, math.MaxInt32, TypeInt, int32(math.MaxInt32)},
+		{NumericFit, math.MaxInt32 + 1, TypeLong, int64(math.MaxInt32 + 1)},
+		{NumericFit, math.MinInt32 - 1, TypeLong, int64(math.MinInt32 - 1)},
+		{NumericFit, uint64(math.MaxInt64), TypeLong, int64(math.MaxInt64)},
+		{NumericFit, uint32(1), TypeByte, int8(1)},
+		{NumericFit, []int{1, 2}, TypeShortArray, []int16{1, 2}},
+		{NumericFit, []int{1, math.MaxInt16 + 1}, TypeIntArray, []int32{1, math.MaxInt16 + 1}},
+		{NumericFit, []uint{1, math.MaxInt32 + 1}, TypeLongArray, []int64{1, math.MaxInt32 + 1}},
+		// Sized signed kinds keep their exact type under every policy.
+		{NumericFit, int32(1), TypeInt, int32(1)},
+		{NumericFit, playerID(1), TypeInt, int32(1)},
+		{NumericFit, []int64{1}, TypeLongArray, []int64{1}},
+
+		{NumericLong, 5, TypeLong, int64(5)},
+		{NumericLong, uint16(5), TypeLong, int64(5)},
+		{NumericLong, itemCount(5), TypeLong, int64(5)},
+		{NumericLong, []uint32{5}, TypeLongArray, []int64{5}},
+		{NumericLong, int16(5), TypeShort, int16(5)},
+	}
+	for _, test := range tests {
+		sfsobject := NewSFSObject()
+		sfsobject.SetNumericPolicy(test.policy)
+		if err := sfsobject.Put("key", test.value); err != nil {
+			t.Errorf("policy %d, %T(%v): %v", test.policy, test.value, test.value, err)
+			continue
+		}
+		wrapper := sfsobject.dataHolder["key"]
+		if wrapper.typeId != test.wantType || !reflect.DeepEqual(wrapper.data, test.wantData) {
+			t.Errorf("policy %d, %T(%v): got %v %#v, want %v %#v", test.policy, test.value, test.value,
+				wrapper.typeId, wrapper.data, test.wantType, test.wantData)
+		}
+
+		sfsarray := NewSFSArray()
+		sfsarray.SetNumericPolicy(test.policy)
+		if err := sfsarray.Add(test.value); err != nil {
+			t.Errorf("array, policy %d, %T(%v): %v", test.policy, test.value, test.value, err)
+		} else if typeId, _ := sfsarray.TypeOf(0); typeId != test.wantType {
+			t.Errorf("array, policy %d, %T(%v): got %v, want %v", test.policy, test.value, test.value, typeId, test.wantType)
+		}
+	}
+}
+
+func TestNumericOverflow(t *testing.T) {
+	tests := []struct {
+		policy   NumericPolicy
+		value    interface{}
+		wantType DataType
+	}{
+		{NumericStrict, math.MaxInt32 + 1, TypeInt},
+		{NumericStrict, math.MinInt32 - 1, TypeInt},
+		{NumericStrict, uint16(math.MaxInt16 + 1), TypeShort},
+		{NumericStrict, itemCount(math.MaxUint16), TypeShort},
+		{NumericStrict, uint32(math.MaxInt32 + 1), TypeInt},
+		{NumericStrict, uint64(math.MaxInt64 + 1), TypeLong},
+		{NumericStrict, []int{0, math.MaxInt32 + 1}, TypeInt},
+		{NumericStrict, []uint16{math.MaxInt16 + 1}, TypeShort},
+		{NumericFit, uint64(math.MaxUint64), TypeLong},
+		{NumericFit, []uint64{1, math.MaxUint64}, TypeLong},
+		{NumericLong, uint(math.MaxUint64), TypeLong},
+	}
+	for _, test := range tests {
+		sfsobject := NewSFSObject()
+		sfsobject.SetNumericPolicy(test.policy)
+		err := sfsobject.Put("key", test.value)
+		var overflow *ErrNumericOverflow
+		if !errors.As(err, &overflow) {
+			t.Errorf("policy %d, %T(%v): got %v, want ErrNumericOverflow", test.policy, test.value, test.value, err)
+			continue
+		}
+		if overflow.Type != test.wantType || !reflect.DeepEqual(overflow.Value, test.value) {
+			t.Errorf("policy %d, %T(%v): got %+v, want type %v", test.policy, test.value, test.value, overflow, test.wantType)
+		}
+		if sfsobject.ContainsKey("key") {
+			t.Errorf("policy %d, %T(%v): value was stored despite the overflow", test.policy, test.value, test.value)
+		}
+	}
+}
+
+func TestUnsupportedKinds(t *testing.T) {
+	for _, value := range []interface{}{complex(1, 2), struct{}{}, map[string]int{}, [][]int{{1}}} {
+		var unsupported *ErrUnsupportedType
+		if err := NewSFSObject().Put("key", value); !errors.As(err, &unsupported) {
+			t.Errorf("%T: got %v, want ErrUnsupportedType", value, err)
+		}
+	}
+}
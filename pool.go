@@ -0,0 +1,94 @@
+package sfstypes
+
+import (
+	"io"
+	"sync"
+)
+
+// Containers and buffers that grew beyond these limits are dropped instead
+// of being pooled, so a single large message doesn't pin its memory forever.
+// Containers are measured by the most entries their storage was sized for,
+// since neither slices nor maps shrink when entries are removed.
+const (
+	maxPooledContainerSize = 1024
+	maxPooledBufferSize    = 64 * 1024
+)
+
+var (
+	sfsObjectPool = sync.Pool{
+		New: func() interface{} {
+			return NewSFSObject()
+		},
+	}
+	sfsArrayPool = sync.Pool{
+		New: func() interface{} {
+			return NewSFSArray()
+		},
+	}
+	encodeBufferPool = sync.Pool{
+		New: func() interface{} {
+			buf := make([]byte, 0, 1024)
+			return &buf
+		},
+	}
+)
+
+// AcquireSFSObject returns an empty SFSObject from the pool. It is an ordinary
+// SFSObject; handing it back with Release is optional.
+func AcquireSFSObject() *SFSObject {
+	return sfsObjectPool.Get().(*SFSObject)
+}
+
+// AcquireSFSArray returns an empty SFSArray from the pool. It is an ordinary
+// SFSArray; handing it back with Release is optional.
+func AcquireSFSArray() *SFSArray {
+	return sfsArrayPool.Get().(*SFSArray)
+}
+
+// Release resets sfsobject and returns it to the pool. The caller gives up
+// ownership: neither sfsobject nor any container or slice obtained from it may
+// be used afterwards. Nested containers are not released, since they may be
+// referenced elsewhere; release them first if they are owned as well.
+func (sfsobject *SFSObject) Release() {
+	if sfsobject.peakSize > maxPooledContainerSize {
+		return
+	}
+	sfsobject.Reset()
+	sfsobject.numericPolicy = NumericStrict
+	sfsobject.sqlFormat = SQLBinary
+	sfsObjectPool.Put(sfsobject)
+}
+
+// Release resets sfsarray and returns it to the pool, with the same ownership
+// rules as SFSObject.Release.
+func (sfsarray *SFSArray) Release() {
+	if cap(sfsarray.dataHolder) > maxPooledContainerSize {
+		return
+	}
+	sfsarray.Reset()
+	sfsarray.numericPolicy = NumericStrict
+	sfsarray.sqlFormat = SQLBinary
+	sfsArrayPool.Put(sfsarray)
+}
+
+// WriteTo encodes sfsobject into a pooled buffer and writes it to w.
+func (sfsobject *SFSObject) WriteTo(w io.Writer) (int64, error) {
+	buf := encodeBufferPool.Get().(*[]byte)
+	*buf = appendSFSObject((*buf)[:0], sfsobject)
+	return writePooledBuffer(w, buf)
+}
+
+// WriteTo encodes sfsarray into a pooled buffer and writes it to w.
+func (sfsarray *SFSArray) WriteTo(w io.Writer) (int64, error) {
+	buf := encodeBufferPool.Get().(*[]byte)
+	*buf = appendSFSArray((*buf)[:0], sfsarray)
+	return writePooledBuffer(w, buf)
+}
+
+func writePooledBuffer(w io.Writer, buf *[]byte) (int64, error) {
+	n, err := w.Write(*buf)
+	if cap(*buf) <= maxPooledBufferSize {
+		encodeBufferPool.Put(buf)
+	}
+	return int64(n), err
+}
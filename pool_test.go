@@ -0,0 +1,66 @@
+package sfstypes
+
+import (
+	"io"
+	"strconv"
+	"testing"
+)
+
+func TestPeakSizeSurvivesRemovalAndReset(t *testing.T) {
+	sfsobject := NewSFSObject()
+	for i := 0; i <= maxPooledContainerSize; i++ {
+		sfsobject.PutInt(strconv.Itoa(i), int32(i))
+	}
+	for _, key := range sfsobject.GetKeys() {
+		sfsobject.RemoveElement(key)
+	}
+	sfsobject.Reset()
+	if sfsobject.peakSize <= maxPooledContainerSize {
+		t.Fatalf("peakSize is %d after shrinking, want the size the map grew to", sfsobject.peakSize)
+	}
+
+	decoded, err := NewSFSObjectFromBinaryData(sfsobject.ToBinary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.peakSize != 0 {
+		t.Fatalf("decoded empty object has peakSize %d", decoded.peakSize)
+	}
+}
+
+// The pooling benchmarks build and encode the same message with and without
+// pooled containers and encode buffers.
+
+func fillBenchmarkMessage(sfsobject *SFSObject, position *SFSObject) {
+	position.PutFloat("x", 1.5)
+	position.PutFloat("y", -2)
+	sfsobject.PutInt("id", 7)
+	sfsobject.PutUtfString("name", "Player")
+	sfsobject.PutIntArray("scores", []int32{10, 20, 30, 40})
+	sfsobject.PutSFSObject("pos", position)
+}
+
+func BenchmarkMessageNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sfsobject := NewSFSObject()
+		fillBenchmarkMessage(sfsobject, NewSFSObject())
+		if _, err := io.Discard.Write(sfsobject.ToBinary()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMessagePooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sfsobject := AcquireSFSObject()
+		position := AcquireSFSObject()
+		fillBenchmarkMessage(sfsobject, position)
+		if _, err := sfsobject.WriteTo(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+		position.Release()
+		sfsobject.Release()
+	}
+}
@@ -0,0 +1,322 @@
+package sfstypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Schema describes the keys an SFSObject is expected to contain. It can be
+// built in Go or loaded from JSON, where types are given by their SFS2X name:
+//
+//	{
+//	    "fields": {
+//	        "id":   {"type": "INT", "required": true, "min": 1},
+//	        "name": {"type": "UTF_STRING", "maxLength": 32, "pattern": "^[a-z]+$"},
+//	        "pos":  {"type": "SFS_OBJECT", "object": {"fields": {"x": {"type": "FLOAT"}}}},
+//	        "tags": {"type": "UTF_STRING_ARRAY", "elements": {"type": "UTF_STRING", "maxLength": 8}}
+//	    }
+//	}
+type Schema struct {
+	Fields map[string]*FieldSchema `json:"fields"`
+	// AllowUnknown accepts keys that are not listed in Fields.
+	AllowUnknown bool `json:"allowUnknown,omitempty"`
+}
+
+// FieldSchema describes a single value. Min and Max apply to numeric values,
+// MinLength and MaxLength to the length of strings (in characters) and arrays
+// (in elements), and Pattern to strings. Object describes the contents of an
+// SFS_OBJECT; Elements describes every element of an SFS_ARRAY or typed array,
+// where the elements of a typed array have the matching scalar type (e.g. INT
+// for INT_ARRAY).
+type FieldSchema struct {
+	Type      DataType     `json:"type"`
+	Required  bool         `json:"required,omitempty"`
+	Nullable  bool         `json:"nullable,omitempty"`
+	Min       *float64     `json:"min,omitempty"`
+	Max       *float64     `json:"max,omitempty"`
+	MinLength *int         `json:"minLength,omitempty"`
+	MaxLength *int         `json:"maxLength,omitempty"`
+	Pattern   string       `json:"pattern,omitempty"`
+	Object    *Schema      `json:"object,omitempty"`
+	Elements  *FieldSchema `json:"elements,omitempty"`
+
+	compileOnce sync.Once
+	pattern     *regexp.Regexp
+	patternErr  error
+	// missingType is set for fields decoded from JSON without a type, which
+	// Compile rejects instead of taking them for NULL.
+	missingType bool
+}
+
+// fieldSchemaJson has the fields but not the methods of FieldSchema.
+type fieldSchemaJson FieldSchema
+
+func (field *FieldSchema) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Type *DataType `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*fieldSchemaJson)(field)); err != nil {
+		return err
+	}
+	field.missingType = header.Type == nil
+	return nil
+}
+
+// SchemaViolation is a single mismatch between an SFSObject and a Schema.
+// Path names the value like Walk does, e.g. "player.items[2].id".
+type SchemaViolation struct {
+	Path    string
+	Message string
+}
+
+func (violation SchemaViolation) String() string {
+	return violation.Path + ": " + violation.Message
+}
+
+type ErrSchemaValidation struct {
+	Violations []SchemaViolation
+}
+
+func (err *ErrSchemaValidation) Error() string {
+	messages := make([]string, len(err.Violations))
+	for i, violation := range err.Violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Sprintf("%d schema violation(s): %s", len(err.Violations), strings.Join(messages, "; "))
+}
+
+func NewSchemaFromJson(data []byte) (*Schema, error) {
+	schema := &Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+	if err := schema.Compile(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func LoadSchemaFile(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchemaFromJson(data)
+}
+
+// Compile checks that no field is nil or, if decoded from JSON, lacks a type,
+// and compiles the patterns of all fields. Validate calls it as well; calling
+// Compile surfaces an invalid schema early.
+func (schema *Schema) Compile() error {
+	for _, key := range sortedFieldKeys(schema.Fields) {
+		if err := schema.Fields[key].compile(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (field *FieldSchema) compile(path string) error {
+	if field == nil {
+		return fmt.Errorf("schema field %s is null", path)
+	}
+	if field.missingType {
+		return fmt.Errorf("schema field %s has no type", path)
+	}
+	if _, err := field.compiledPattern(); err != nil {
+		return fmt.Errorf("schema field %s: %w", path, err)
+	}
+	if field.Object != nil {
+		for _, key := range sortedFieldKeys(field.Object.Fields) {
+			if err := field.Object.Fields[key].compile(path + "." + key); err != nil {
+				return err
+			}
+		}
+	}
+	if field.Elements != nil {
+		return field.Elements.compile(path + "[]")
+	}
+	return nil
+}
+
+func (field *FieldSchema) compiledPattern() (*regexp.Regexp, error) {
+	field.compileOnce.Do(func() {
+		if field.Pattern != "" {
+			field.pattern, field.patternErr = regexp.Compile(field.Pattern)
+		}
+	})
+	return field.pattern, field.patternErr
+}
+
+// Validate checks sfsobject against the schema and returns an
+// *ErrSchemaValidation listing every violation, or nil. If the schema itself
+// is invalid it returns the error of Compile.
+func (schema *Schema) Validate(sfsobject *SFSObject) error {
+	if err := schema.Compile(); err != nil {
+		return err
+	}
+	var violations []SchemaViolation
+	schema.validateObject("", sfsobject, &violations)
+	if len(violations) > 0 {
+		return &ErrSchemaValidation{Violations: violations}
+	}
+	return nil
+}
+
+func (schema *Schema) validateObject(prefix string, sfsobject *SFSObject, violations *[]SchemaViolation) {
+	for _, key := range sortedFieldKeys(schema.Fields) {
+		field := schema.Fields[key]
+		path := joinPath(prefix, key)
+		value, err := sfsobject.GetValue(key)
+		if err != nil {
+			if field.Required {
+				*violations = append(*violations, SchemaViolation{Path: path, Message: "required key is missing"})
+			}
+			continue
+		}
+		field.validateValue(path, value, violations)
+	}
+	if schema.AllowUnknown {
+		return
+	}
+	keys := sfsobject.GetKeys()
+	slices.Sort(keys)
+	for _, key := range keys {
+		if _, known := schema.Fields[key]; !known {
+			*violations = append(*violations, SchemaViolation{Path: joinPath(prefix, key), Message: "unexpected key"})
+		}
+	}
+}
+
+func (field *FieldSchema) validateValue(path string, value Value, violations *[]SchemaViolation) {
+	violate := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if value.IsNull() && field.Nullable {
+		return
+	}
+	if value.Type() != field.Type {
+		violate("expected type %s, found %s", field.Type.Name(), value.Type().Name())
+		return
+	}
+
+	if number, isNumber := numericValue(value); isNumber {
+		if field.Min != nil && number < *field.Min {
+			violate("value %v is less than minimum %v", number, *field.Min)
+		}
+		if field.Max != nil && number > *field.Max {
+			violate("value %v is greater than maximum %v", number, *field.Max)
+		}
+	}
+
+	length := -1
+	if str, err := value.AsString(); err == nil {
+		length = utf8.RuneCountInString(str)
+		if pattern, err := field.compiledPattern(); err != nil {
+			violate("invalid pattern: %s", err)
+		} else if pattern != nil && !pattern.MatchString(str) {
+			violate("value %q does not match pattern %q", str, field.Pattern)
+		}
+	} else if elements, isArray := arrayElements(value); isArray {
+		length = len(elements)
+		if field.Elements != nil {
+			for i, element := range elements {
+				field.Elements.validateValue(path+"["+strconv.Itoa(i)+"]", element, violations)
+			}
+		}
+	}
+	if length >= 0 {
+		if field.MinLength != nil && length < *field.MinLength {
+			violate("length %d is less than minimum length %d", length, *field.MinLength)
+		}
+		if field.MaxLength != nil && length > *field.MaxLength {
+			violate("length %d is greater than maximum length %d", length, *field.MaxLength)
+		}
+	}
+
+	if nested, err := value.AsSFSObject(); err == nil && field.Object != nil {
+		field.Object.validateObject(path, nested, violations)
+	}
+}
+
+func numericValue(value Value) (float64, bool) {
+	switch data := value.wrapper.data.(type) {
+	case int8:
+		return float64(data), true
+	case int16:
+		return float64(data), true
+	case int32:
+		return float64(data), true
+	case int64:
+		return float64(data), true
+	case float32:
+		return float64(data), true
+	case float64:
+		return data, true
+	}
+	return 0, false
+}
+
+// arrayElements returns the elements of an SFS_ARRAY or typed array as
+// Values of the element type.
+func arrayElements(value Value) ([]Value, bool) {
+	var elements []Value
+	appendElements := func(typeId DataType, count int, element func(int) interface{}) {
+		elements = make([]Value, count)
+		for i := range elements {
+			elements[i] = Value{wrapper: sfsDataWrapper{typeId: typeId, data: element(i)}}
+		}
+	}
+	switch data := value.wrapper.data.(type) {
+	case *SFSArray:
+		elements = make([]Value, 0, data.Size())
+		for _, element := range data.All() {
+			elements = append(elements, element)
+		}
+	case []bool:
+		appendElements(TypeBool, len(data), func(i int) interface{} { return data[i] })
+	case []byte:
+		appendElements(TypeByte, len(data), func(i int) interface{} { return int8(data[i]) })
+	case []int16:
+		appendElements(TypeShort, len(data), func(i int) interface{} { return data[i] })
+	case []int32:
+		appendElements(TypeInt, len(data), func(i int) interface{} { return data[i] })
+	case []int64:
+		appendElements(TypeLong, len(data), func(i int) interface{} { return data[i] })
+	case []float32:
+		appendElements(TypeFloat, len(data), func(i int) interface{} { return data[i] })
+	case []float64:
+		appendElements(TypeDouble, len(data), func(i int) interface{} { return data[i] })
+	case []string:
+		appendElements(TypeUtfString, len(data), func(i int) interface{} { return data[i] })
+	default:
+		return nil, false
+	}
+	return elements, true
+}
+
+func sortedFieldKeys(fields map[string]*FieldSchema) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func joinPath(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
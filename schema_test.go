@@ -0,0 +1,135 @@
+package sfstypes
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDataTypeNames(t *testing.T) {
+	for typeId := TypeNull; typeId <= TypeText; typeId++ {
+		text, err := typeId.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseDataType(string(text))
+		if err != nil || parsed != typeId {
+			t.Errorf("%v: ParseDataType(%q) = %v, %v", typeId, text, parsed, err)
+		}
+		var unmarshaled DataType
+		if err := unmarshaled.UnmarshalText(text); err != nil || unmarshaled != typeId {
+			t.Errorf("%v: UnmarshalText(%q) = %v, %v", typeId, text, unmarshaled, err)
+		}
+	}
+	if name := DataType(21).Name(); name != "UNKNOWN(21)" {
+		t.Errorf("Name() of type 21 = %q", name)
+	}
+	for _, name := range []string{"", "int", "UNKNOWN(21)", "SFS_OBJECT "} {
+		if _, err := ParseDataType(name); err == nil {
+			t.Errorf("ParseDataType(%q) succeeded", name)
+		}
+	}
+	data, err := json.Marshal(map[string]DataType{"type": TypeUtfStringArray})
+	if err != nil || string(data) != `{"type":"UTF_STRING_ARRAY"}` {
+		t.Errorf("json.Marshal = %s, %v", data, err)
+	}
+}
+
+func TestInvalidSchemas(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`{"fields": {"id": {}}}`, "schema field id has no type"},
+		{`{"fields": {"id": null}}`, "schema field id is null"},
+		{`{"fields": {"pos": {"type": "SFS_OBJECT", "object": {"fields": {"x": {"required": true}}}}}}`, "schema field pos.x has no type"},
+		{`{"fields": {"tags": {"type": "SFS_ARRAY", "elements": {}}}}`, "schema field tags[] has no type"},
+		{`{"fields": {"name": {"type": "UTF_STRING", "pattern": "("}}}`, "schema field name: error parsing regexp"},
+		{`{"fields": {"id": {"type": "INTEGER"}}}`, `unknown SFS data type "INTEGER"`},
+		{`{"fields": []}`, "cannot unmarshal array"},
+	}
+	for _, test := range tests {
+		_, err := NewSchemaFromJson([]byte(test.json))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want %q", test.json, err, test.want)
+		}
+	}
+
+	// Schemas built in Go are checked by Validate.
+	schema := &Schema{Fields: map[string]*FieldSchema{"id": nil}}
+	var validation *ErrSchemaValidation
+	if err := schema.Validate(NewSFSObject()); err == nil || errors.As(err, &validation) {
+		t.Errorf("Validate with a nil field: got %v, want a schema error", err)
+	}
+
+	if _, err := LoadSchemaFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadSchemaFile of a missing file succeeded")
+	}
+}
+
+func TestSchemaViolations(t *testing.T) {
+	schema, err := NewSchemaFromJson([]byte(`{
+		"fields": {
+			"id":    {"type": "INT", "required": true, "min": 1, "max": 10},
+			"name":  {"type": "UTF_STRING", "minLength": 2, "maxLength": 4, "pattern": "^[a-z]+$"},
+			"owner": {"type": "UTF_STRING", "required": true},
+			"alias": {"type": "UTF_STRING", "nullable": true},
+			"pos":   {"type": "SFS_OBJECT", "object": {"fields": {"x": {"type": "FLOAT", "required": true}}}},
+			"ids":   {"type": "INT_ARRAY", "maxLength": 2, "elements": {"type": "INT", "min": 0}},
+			"items": {"type": "SFS_ARRAY", "elements": {"type": "SFS_OBJECT", "object": {"fields": {"id": {"type": "LONG"}}}}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := NewSFSObject()
+	item.PutInt("id", 1)
+	items := NewSFSArray()
+	items.AddSFSObject(NewSFSObject())
+	items.AddSFSObject(item)
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("id", 11)
+	sfsobject.PutUtfString("name", "ABCDE")
+	sfsobject.PutNull("alias")
+	sfsobject.PutSFSObject("pos", NewSFSObject())
+	sfsobject.PutIntArray("ids", []int32{1, -1, 2})
+	sfsobject.PutSFSArray("items", items)
+	sfsobject.PutBool("extra", true)
+
+	err = schema.Validate(sfsobject)
+	var validation *ErrSchemaValidation
+	if !errors.As(err, &validation) {
+		t.Fatalf("got %v, want ErrSchemaValidation", err)
+	}
+	var got []string
+	for _, violation := range validation.Violations {
+		got = append(got, violation.String())
+	}
+	want := []string{
+		"id: value 11 is greater than maximum 10",
+		"ids[1]: value -1 is less than minimum 0",
+		"ids: length 3 is greater than maximum length 2",
+		`items[1].id: expected type LONG, found INT`,
+		`name: value "ABCDE" does not match pattern "^[a-z]+$"`,
+		"name: length 5 is greater than maximum length 4",
+		"owner: required key is missing",
+		"pos.x: required key is missing",
+		"extra: unexpected key",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got violations\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	schema.AllowUnknown = true
+	valid := NewSFSObject()
+	valid.PutInt("id", 1)
+	valid.PutUtfString("owner", "me")
+	valid.PutBool("extra", true)
+	if err := schema.Validate(valid); err != nil {
+		t.Fatalf("valid object: %v", err)
+	}
+}
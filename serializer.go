@@ -1,140 +1,202 @@
 package sfstypes
 
 import (
-	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"io"
+	"math"
+	"slices"
 )
 
 func encodeSFSObject(object *SFSObject) []byte {
-	var buf bytes.Buffer
-	binary.Write(&buf, binary.BigEndian, byte(type_SFS_OBJECT))
-	binary.Write(&buf, binary.BigEndian, int16(object.Size()))
+	return appendSFSObject(make([]byte, 0, sfsObjectSize(object)), object)
+}
 
-	keys := object.GetKeys()
-	for _, key := range keys {
-		encodeSFSObjectKey(&buf, key)
-		wrapper, _ := object.getWrapper(key)
-		dataObj := wrapper.data
-		encodeData(&buf, wrapper.typeId, dataObj)
-	}
-	return buf.Bytes()
+func encodeSFSArray(array *SFSArray) []byte {
+	return appendSFSArray(make([]byte, 0, sfsArraySize(array)), array)
 }
 
-func encodeSFSObjectKey(buf *bytes.Buffer, value string) {
-	binary.Write(buf, binary.BigEndian, int16(len(value)))
-	binary.Write(buf, binary.BigEndian, []byte(value))
+// appendSFSObject writes the keys of object in canonical order, so encoding
+// the same values always gives the same bytes.
+func appendSFSObject(dst []byte, object *SFSObject) []byte {
+	dst = append(dst, byte(TypeSFSObject))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(object.Size()))
+	for _, key := range object.sortedKeys() {
+		wrapper := object.dataHolder[key]
+		dst = appendSFSObjectKey(dst, key)
+		dst = appendData(dst, wrapper.typeId, wrapper.data)
+	}
+	return dst
 }
 
-func encodeSFSArray(array *SFSArray) []byte {
-	var buf bytes.Buffer
-	binary.Write(&buf, binary.BigEndian, byte(type_SFS_ARRAY))
-	binary.Write(&buf, binary.BigEndian, int16(array.Size()))
+func appendSFSObjectKey(dst []byte, value string) []byte {
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(value)))
+	return append(dst, value...)
+}
 
-	for i := 0; i < array.Size(); i++ {
-		wrapper, _ := array.getWrapper(i)
-		encodeData(&buf, wrapper.typeId, wrapper.data)
+func appendSFSArray(dst []byte, array *SFSArray) []byte {
+	dst = append(dst, byte(TypeSFSArray))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(array.Size()))
+	for _, wrapper := range array.dataHolder {
+		dst = appendData(dst, wrapper.typeId, wrapper.data)
 	}
-	return buf.Bytes()
+	return dst
 }
 
-func encodeData(buf *bytes.Buffer, typeId sfsDataType, object interface{}) {
-	binary.Write(buf, binary.BigEndian, typeId)
+func appendData(dst []byte, typeId DataType, object interface{}) []byte {
 	switch typeId {
-	case type_NULL:
-		{
-
-		}
-	case type_BOOL:
-		if object.(bool) {
-			binary.Write(buf, binary.BigEndian, byte(1))
-		} else {
-			binary.Write(buf, binary.BigEndian, byte(0))
-		}
-	case type_BYTE:
-		binary.Write(buf, binary.BigEndian, object.(int8))
-	case type_SHORT:
-		binary.Write(buf, binary.BigEndian, object.(int16))
-	case type_INT:
-		binary.Write(buf, binary.BigEndian, object.(int32))
-	case type_LONG:
-		binary.Write(buf, binary.BigEndian, object.(int64))
-	case type_FLOAT:
-		binary.Write(buf, binary.BigEndian, object.(float32))
-	case type_DOUBLE:
-		binary.Write(buf, binary.BigEndian, object.(float64))
-	case type_UTF_STRING:
+	case TypeSFSArray:
+		return appendSFSArray(dst, object.(*SFSArray))
+	case TypeSFSObject:
+		return appendSFSObject(dst, object.(*SFSObject))
+	}
+	dst = append(dst, byte(typeId))
+	switch typeId {
+	case TypeBool:
+		dst = append(dst, boolToByte(object.(bool)))
+	case TypeByte:
+		dst = append(dst, byte(object.(int8)))
+	case TypeShort:
+		dst = binary.BigEndian.AppendUint16(dst, uint16(object.(int16)))
+	case TypeInt:
+		dst = binary.BigEndian.AppendUint32(dst, uint32(object.(int32)))
+	case TypeLong:
+		dst = binary.BigEndian.AppendUint64(dst, uint64(object.(int64)))
+	case TypeFloat:
+		dst = binary.BigEndian.AppendUint32(dst, math.Float32bits(object.(float32)))
+	case TypeDouble:
+		dst = binary.BigEndian.AppendUint64(dst, math.Float64bits(object.(float64)))
+	case TypeUtfString:
 		str := object.(string)
-		binary.Write(buf, binary.BigEndian, int16(len(str)))
-		binary.Write(buf, binary.BigEndian, []byte(str))
-	case type_TEXT:
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(str)))
+		dst = append(dst, str...)
+	case TypeText:
 		str := object.(string)
-		binary.Write(buf, binary.BigEndian, int32(len(str)))
-		binary.Write(buf, binary.BigEndian, []byte(str))
-	case type_BOOL_ARRAY:
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(str)))
+		dst = append(dst, str...)
+	case TypeBoolArray:
 		array := object.([]bool)
-		binary.Write(buf, binary.BigEndian, int16(len(array)))
-		for _, element := range array {
-			if element {
-				binary.Write(buf, binary.BigEndian, byte(1))
-			} else {
-				binary.Write(buf, binary.BigEndian, byte(0))
-			}
-		}
-	case type_BYTE_ARRAY:
-		array := object.([]int8)
-		binary.Write(buf, binary.BigEndian, int32(len(array)))
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(array)))
 		for _, element := range array {
-			binary.Write(buf, binary.BigEndian, element)
+			dst = append(dst, boolToByte(element))
 		}
-	case type_SHORT_ARRAY:
+	case TypeByteArray:
+		array := object.([]byte)
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(array)))
+		dst = append(dst, array...)
+	case TypeShortArray:
 		array := object.([]int16)
-		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(array)))
+		dst = slices.Grow(dst, len(array)*2)
 		for _, element := range array {
-			binary.Write(buf, binary.BigEndian, element)
+			dst = binary.BigEndian.AppendUint16(dst, uint16(element))
 		}
-	case type_INT_ARRAY:
+	case TypeIntArray:
 		array := object.([]int32)
-		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(array)))
+		dst = slices.Grow(dst, len(array)*4)
 		for _, element := range array {
-			binary.Write(buf, binary.BigEndian, element)
+			dst = binary.BigEndian.AppendUint32(dst, uint32(element))
 		}
-	case type_LONG_ARRAY:
+	case TypeLongArray:
 		array := object.([]int64)
-		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(array)))
+		dst = slices.Grow(dst, len(array)*8)
 		for _, element := range array {
-			binary.Write(buf, binary.BigEndian, element)
+			dst = binary.BigEndian.AppendUint64(dst, uint64(element))
 		}
-	case type_FLOAT_ARRAY:
+	case TypeFloatArray:
 		array := object.([]float32)
-		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(array)))
+		dst = slices.Grow(dst, len(array)*4)
 		for _, element := range array {
-			binary.Write(buf, binary.BigEndian, element)
+			dst = binary.BigEndian.AppendUint32(dst, math.Float32bits(element))
 		}
-	case type_DOUBLE_ARRAY:
+	case TypeDoubleArray:
 		array := object.([]float64)
-		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(array)))
+		dst = slices.Grow(dst, len(array)*8)
 		for _, element := range array {
-			binary.Write(buf, binary.BigEndian, element)
+			dst = binary.BigEndian.AppendUint64(dst, math.Float64bits(element))
 		}
-	case type_UTF_STRING_ARRAY:
+	case TypeUtfStringArray:
 		array := object.([]string)
-		binary.Write(buf, binary.BigEndian, int16(len(array)))
+		dst = binary.BigEndian.AppendUint16(dst, uint16(len(array)))
 		for _, element := range array {
-			binary.Write(buf, binary.BigEndian, int16(len(element)))
-			binary.Write(buf, binary.BigEndian, []byte(element))
-		}
-	case type_SFS_ARRAY:
-		buf.Truncate(buf.Len() - 1)
-		arr := object.(SFSArray)
-		binary.Write(buf, binary.BigEndian, encodeSFSArray(&arr))
-	case type_SFS_OBJECT:
-		buf.Truncate(buf.Len() - 1)
-		obj := object.(SFSObject)
-		binary.Write(buf, binary.BigEndian, encodeSFSObject(&obj))
+			dst = binary.BigEndian.AppendUint16(dst, uint16(len(element)))
+			dst = append(dst, element...)
+		}
 		//case CLASS:
 		//	addData(buffer, object2binary(pojo2sfs(object)));
 	}
+	return dst
+}
+
+func boolToByte(value bool) byte {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func sfsObjectSize(object *SFSObject) int {
+	size := 3
+	for key, wrapper := range object.dataHolder {
+		size += 2 + len(key) + dataSize(wrapper.typeId, wrapper.data)
+	}
+	return size
+}
+
+func sfsArraySize(array *SFSArray) int {
+	size := 3
+	for _, wrapper := range array.dataHolder {
+		size += dataSize(wrapper.typeId, wrapper.data)
+	}
+	return size
+}
+
+// dataSize returns the encoded size of a value including its type header.
+func dataSize(typeId DataType, object interface{}) int {
+	switch typeId {
+	case TypeBool, TypeByte:
+		return 2
+	case TypeShort:
+		return 3
+	case TypeInt, TypeFloat:
+		return 5
+	case TypeLong, TypeDouble:
+		return 9
+	case TypeUtfString:
+		return 3 + len(object.(string))
+	case TypeText:
+		return 5 + len(object.(string))
+	case TypeBoolArray:
+		return 3 + len(object.([]bool))
+	case TypeByteArray:
+		return 5 + len(object.([]byte))
+	case TypeShortArray:
+		return 3 + 2*len(object.([]int16))
+	case TypeIntArray:
+		return 3 + 4*len(object.([]int32))
+	case TypeLongArray:
+		return 3 + 8*len(object.([]int64))
+	case TypeFloatArray:
+		return 3 + 4*len(object.([]float32))
+	case TypeDoubleArray:
+		return 3 + 8*len(object.([]float64))
+	case TypeUtfStringArray:
+		size := 3
+		for _, element := range object.([]string) {
+			size += 2 + len(element)
+		}
+		return size
+	case TypeSFSArray:
+		return sfsArraySize(object.(*SFSArray))
+	case TypeSFSObject:
+		return sfsObjectSize(object.(*SFSObject))
+	default:
+		return 1
+	}
 }
 
 func sfsObjectToJson(sfsobject *SFSObject) string {
@@ -184,14 +246,12 @@ func convertSFSObjectToMap(sfsobject *SFSObject) map[string]interface{} {
 	for _, key := range keys {
 		currentData := sfsobject.dataHolder[key]
 		switch currentData.typeId {
-		case type_SFS_OBJECT:
-			obj := currentData.data.(SFSObject)
-			result[key] = convertSFSObjectToMap(&obj)
-		case type_SFS_ARRAY:
-			arr := currentData.data.(SFSArray)
-			result[key] = convertSFSArrayToSlice(&arr)
+		case TypeSFSObject:
+			result[key] = convertSFSObjectToMap(currentData.data.(*SFSObject))
+		case TypeSFSArray:
+			result[key] = convertSFSArrayToSlice(currentData.data.(*SFSArray))
 		default:
-			result[key] = currentData.data
+			result[key] = currentData.interfaceValue()
 		}
 	}
 	return result
@@ -203,14 +263,12 @@ func convertSFSArrayToSlice(sfsarray *SFSArray) []interface{} {
 		currentData := sfsarray.dataHolder[index]
 
 		switch currentData.typeId {
-		case type_SFS_OBJECT:
-			obj := currentData.data.(SFSObject)
-			result = append(result, convertSFSObjectToMap(&obj))
-		case type_SFS_ARRAY:
-			arr := currentData.data.(SFSArray)
-			result = append(result, convertSFSArrayToSlice(&arr))
+		case TypeSFSObject:
+			result = append(result, convertSFSObjectToMap(currentData.data.(*SFSObject)))
+		case TypeSFSArray:
+			result = append(result, convertSFSArrayToSlice(currentData.data.(*SFSArray)))
 		default:
-			result = append(result, currentData.data)
+			result = append(result, currentData.interfaceValue())
 		}
 	}
 	return result
@@ -282,285 +340,463 @@ func convertSliceToSFSArray(input []interface{}) (*SFSArray, error) {
 
 func newSFSObjectfromBinary(data []byte) (*SFSObject, error) {
 	if size := len(data); size < 3 {
-		return nil, &ErrInsufficientByteData{sfsType: type_SFS_OBJECT, size: len(data)}
+		return nil, &ErrInsufficientByteData{Type: TypeSFSObject, Size: len(data)}
 	}
-	buf := bytes.NewBuffer(data)
-	return decodeSFSObject(buf)
+	decoder := sfsDecoder{data: data}
+	return decoder.decodeSFSObject()
 }
 
 func newSFSArrayFromBinaryData(data []byte) (*SFSArray, error) {
 	if size := len(data); size < 3 {
-		return nil, &ErrInsufficientByteData{sfsType: type_SFS_ARRAY, size: len(data)}
+		return nil, &ErrInsufficientByteData{Type: TypeSFSArray, Size: len(data)}
 	}
-	buf := bytes.NewBuffer(data)
-	return decodeSFSArray(buf)
+	decoder := sfsDecoder{data: data}
+	return decoder.decodeSFSArray()
 }
 
-func decodeSFSObject(buf *bytes.Buffer) (*SFSObject, error) {
-	sfsObject := NewSFSObject()
+// maxDecodeDepth limits how deeply SFSObjects and SFSArrays may be nested in
+// binary data.
+const maxDecodeDepth = 128
 
-	var header byte
-	if err := binary.Read(buf, binary.BigEndian, &header); err != nil {
-		return nil, &ErrReadingData{TypeToRead: "value header", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-	} else if header != byte(type_SFS_OBJECT) {
-		return nil, &ErrWrongType{actualType: sfsDataType(header), wantedType: type_SFS_OBJECT}
+// sfsDecoder reads SFS binary data straight from a byte slice.
+type sfsDecoder struct {
+	data   []byte
+	offset int
+	depth  int
+}
+
+// enter is called before decoding a nested container; every call must be
+// paired with leave.
+func (decoder *sfsDecoder) enter() error {
+	if decoder.depth >= maxDecodeDepth {
+		return &ErrMaxDepthExceeded{Depth: maxDecodeDepth}
 	}
+	decoder.depth++
+	return nil
+}
 
-	var size uint16
-	if err := binary.Read(buf, binary.BigEndian, &size); err != nil {
-		return nil, &ErrReadingData{TypeToRead: "SFSObject size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+func (decoder *sfsDecoder) leave() {
+	decoder.depth--
+}
+
+func (decoder *sfsDecoder) remaining() int {
+	return len(decoder.data) - decoder.offset
+}
+
+func (decoder *sfsDecoder) readError(typeToRead string) error {
+	return &ErrReadingData{TypeToRead: typeToRead, Len: len(decoder.data) - decoder.offset, Cap: len(decoder.data), IoErr: io.ErrUnexpectedEOF}
+}
+
+func (decoder *sfsDecoder) read(size int, typeToRead string) ([]byte, error) {
+	if size < 0 || len(decoder.data)-decoder.offset < size {
+		return nil, decoder.readError(typeToRead)
 	}
-	for i := uint16(0); i < size; i++ {
+	result := decoder.data[decoder.offset : decoder.offset+size]
+	decoder.offset += size
+	return result, nil
+}
 
-		var keySize uint16
-		if err := binary.Read(buf, binary.BigEndian, &keySize); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "key size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+func (decoder *sfsDecoder) readByte(typeToRead string) (byte, error) {
+	if decoder.offset >= len(decoder.data) {
+		return 0, decoder.readError(typeToRead)
+	}
+	result := decoder.data[decoder.offset]
+	decoder.offset++
+	return result, nil
+}
+
+func (decoder *sfsDecoder) readUint16(typeToRead string) (uint16, error) {
+	bytes, err := decoder.read(2, typeToRead)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(bytes), nil
+}
+
+func (decoder *sfsDecoder) readUint32(typeToRead string) (uint32, error) {
+	bytes, err := decoder.read(4, typeToRead)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(bytes), nil
+}
+
+func (decoder *sfsDecoder) readUint64(typeToRead string) (uint64, error) {
+	bytes, err := decoder.read(8, typeToRead)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(bytes), nil
+}
+
+// readArray reads the 16 bit element count of a typed array followed by the
+// raw bytes of its elements.
+func (decoder *sfsDecoder) readArray(elementSize int, typeToRead string) (int, []byte, error) {
+	length, err := decoder.readUint16(typeToRead + " length")
+	if err != nil {
+		return 0, nil, err
+	}
+	bytes, err := decoder.read(int(length)*elementSize, typeToRead)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int(length), bytes, nil
+}
+
+func (decoder *sfsDecoder) decodeSFSObject() (*SFSObject, error) {
+	header, err := decoder.readByte("value header")
+	if err != nil {
+		return nil, err
+	} else if header != byte(TypeSFSObject) {
+		return nil, &ErrWrongType{ActualType: DataType(header), WantedType: TypeSFSObject}
+	}
+
+	size, err := decoder.readUint16("SFSObject size")
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.enter(); err != nil {
+		return nil, err
+	}
+	defer decoder.leave()
+	// Every entry takes at least three bytes, so a corrupt size can't make
+	// the map larger than the input.
+	capacity := min(int(size), decoder.remaining()/3)
+	sfsObject := &SFSObject{
+		dataHolder: make(map[string]sfsDataWrapper, capacity),
+		peakSize:   capacity,
+	}
+	for i := uint16(0); i < size; i++ {
+		keySize, err := decoder.readUint16("key size")
+		if err != nil {
+			return nil, err
 		}
 		if keySize > 255 {
-			return nil, &ErrInvalidKeySize{key: "", length: int(keySize)}
+			return nil, &ErrInvalidKeySize{Key: "", Length: int(keySize)}
 		}
-		keyStringBytes := make([]byte, keySize)
-		if err := binary.Read(buf, binary.BigEndian, &keyStringBytes); err != nil {
+		keyStringBytes, err := decoder.read(int(keySize), "key")
+		if err != nil {
 			return nil, err
 		}
 		key := string(keyStringBytes)
 
-		decodedObject, decodeError := decodeData(buf)
+		decodedObject, decodeError := decoder.decodeData()
 		if decodeError != nil {
 			return nil, decodeError
 		}
-		sfsObject.putsfsDataWrapper(key, decodedObject)
+		sfsObject.putsfsDataWrapper(key, &decodedObject)
 	}
 	return sfsObject, nil
 }
 
-func decodeSFSArray(buf *bytes.Buffer) (*SFSArray, error) {
-	sfsArray := NewSFSArray()
-
-	var header byte
-	if err := binary.Read(buf, binary.BigEndian, &header); err != nil {
-		return nil, &ErrReadingData{TypeToRead: "SFSArry header", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-	} else if header != byte(type_SFS_ARRAY) {
-		return nil, &ErrWrongType{actualType: sfsDataType(header), wantedType: type_SFS_ARRAY}
+func (decoder *sfsDecoder) decodeSFSArray() (*SFSArray, error) {
+	header, err := decoder.readByte("SFSArray header")
+	if err != nil {
+		return nil, err
+	} else if header != byte(TypeSFSArray) {
+		return nil, &ErrWrongType{ActualType: DataType(header), WantedType: TypeSFSArray}
 	}
 
-	var size uint16
-	if err := binary.Read(buf, binary.BigEndian, &size); err != nil {
-		return nil, &ErrReadingData{TypeToRead: "SFSObject size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+	size, err := decoder.readUint16("SFSArray size")
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.enter(); err != nil {
+		return nil, err
+	}
+	defer decoder.leave()
+	sfsArray := &SFSArray{
+		dataHolder: make([]sfsDataWrapper, 0, min(int(size), decoder.remaining())),
 	}
-
 	for i := uint16(0); i < size; i++ {
-		wrapper, err := decodeData(buf)
+		wrapper, err := decoder.decodeData()
 		if err != nil {
 			return nil, err
 		}
-		sfsArray.addsfsDataWrapper(*wrapper)
+		sfsArray.addsfsDataWrapper(wrapper)
 	}
-
 	return sfsArray, nil
 }
 
-func decodeData(buf *bytes.Buffer) (*sfsDataWrapper, error) {
-	var header byte
-	if err := binary.Read(buf, binary.BigEndian, &header); err != nil {
-		return nil, &ErrReadingData{TypeToRead: "value header", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-	}
-	switch sfsDataType(header) {
-	case type_NULL:
-		return newsfsDataWrapper(type_NULL, nil), nil
-	case type_BOOL:
-		var input byte
-		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "bool", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		switch input {
-		case 0:
-			return newsfsDataWrapper(type_BOOL, false), nil
-		case 1:
-			return newsfsDataWrapper(type_BOOL, true), nil
-		default:
-			return nil, ErrDecodingBool
-		}
-	case type_BYTE:
-		var input int8
-		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "byte", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		return newsfsDataWrapper(type_BYTE, input), nil
-	case type_SHORT:
-		var input int16
-		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "short", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		return newsfsDataWrapper(type_SHORT, input), nil
-	case type_INT:
-		var input int32
-		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "int", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		return newsfsDataWrapper(type_INT, input), nil
-	case type_LONG:
-		var input int64
-		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "long", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		return newsfsDataWrapper(type_LONG, input), nil
-	case type_FLOAT:
-		var input float32
-		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "float", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		return newsfsDataWrapper(type_FLOAT, input), nil
-	case type_DOUBLE:
-		var input float64
-		if err := binary.Read(buf, binary.BigEndian, &input); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "double", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		return newsfsDataWrapper(type_DOUBLE, input), nil
-	case type_UTF_STRING:
-		var len uint16
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "string length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		stringBytes := make([]byte, len)
-		if err := binary.Read(buf, binary.BigEndian, &stringBytes); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "string bytes", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		decodedString := string(stringBytes)
-		return newsfsDataWrapper(type_UTF_STRING, decodedString), nil
-	case type_TEXT:
-		var len uint32
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "text length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		stringBytes := make([]byte, len)
-		if err := binary.Read(buf, binary.BigEndian, &stringBytes); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "text bytes", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-		}
-		decodedString := string(stringBytes)
-		return newsfsDataWrapper(type_TEXT, decodedString), nil
-	case type_BOOL_ARRAY:
-		var length uint16
-		if err := binary.Read(buf, binary.BigEndian, &length); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "bool array length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+func (decoder *sfsDecoder) decodeData() (sfsDataWrapper, error) {
+	header, err := decoder.readByte("value header")
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	typeId := DataType(header)
+	switch typeId {
+	case TypeNull:
+		return sfsDataWrapper{typeId: TypeNull}, nil
+	case TypeBool:
+		input, err := decoder.readByte("bool")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		value, err := byteToBool(input)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeBool, data: value}, nil
+	case TypeByte:
+		input, err := decoder.readByte("byte")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeByte, data: int8(input)}, nil
+	case TypeShort:
+		input, err := decoder.readUint16("short")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeShort, data: int16(input)}, nil
+	case TypeInt:
+		input, err := decoder.readUint32("int")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeInt, data: int32(input)}, nil
+	case TypeLong:
+		input, err := decoder.readUint64("long")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeLong, data: int64(input)}, nil
+	case TypeFloat:
+		input, err := decoder.readUint32("float")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeFloat, data: math.Float32frombits(input)}, nil
+	case TypeDouble:
+		input, err := decoder.readUint64("double")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeDouble, data: math.Float64frombits(input)}, nil
+	case TypeUtfString:
+		length, err := decoder.readUint16("string length")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		stringBytes, err := decoder.read(int(length), "string bytes")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeUtfString, data: string(stringBytes)}, nil
+	case TypeText:
+		length, err := decoder.readUint32("text length")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		stringBytes, err := decoder.read(int(length), "text bytes")
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeText, data: string(stringBytes)}, nil
+	case TypeBoolArray:
+		length, bytes, err := decoder.readArray(1, "bool array")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
 		results := make([]bool, length)
-		for i := uint16(0); i < length; i++ {
-			var tempValue byte
-			if err := binary.Read(buf, binary.BigEndian, &tempValue); err != nil {
-				return nil, &ErrReadingData{TypeToRead: "bool array element", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
-			}
-			switch tempValue {
-			case 0:
-				results[i] = false
-			case 1:
-				results[i] = true
-			default:
-				return nil, ErrDecodingBool
+		for i := range results {
+			if results[i], err = byteToBool(bytes[i]); err != nil {
+				return sfsDataWrapper{}, err
 			}
 		}
-		return newsfsDataWrapper(type_BOOL_ARRAY, results), nil
-	case type_BYTE_ARRAY:
-		var len uint32
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "byte array length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		return sfsDataWrapper{typeId: TypeBoolArray, data: results}, nil
+	case TypeByteArray:
+		length, err := decoder.readUint32("byte array length")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
-		results := make([]int8, len)
-		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "byte array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		bytes, err := decoder.read(int(length), "byte array")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-		return newsfsDataWrapper(type_BYTE_ARRAY, results), nil
-	case type_SHORT_ARRAY:
-		var len uint16
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "short array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		results := make([]byte, length)
+		copy(results, bytes)
+		return sfsDataWrapper{typeId: TypeByteArray, data: results}, nil
+	case TypeShortArray:
+		length, bytes, err := decoder.readArray(2, "short array")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
-		results := make([]int16, len)
-		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "short array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		results := make([]int16, length)
+		for i := range results {
+			results[i] = int16(binary.BigEndian.Uint16(bytes[i*2:]))
 		}
-		return newsfsDataWrapper(type_SHORT_ARRAY, results), nil
-	case type_INT_ARRAY:
-		var len uint16
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "int array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		return sfsDataWrapper{typeId: TypeShortArray, data: results}, nil
+	case TypeIntArray:
+		length, bytes, err := decoder.readArray(4, "int array")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
-		results := make([]int32, len)
-		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "int array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		results := make([]int32, length)
+		for i := range results {
+			results[i] = int32(binary.BigEndian.Uint32(bytes[i*4:]))
 		}
-		return newsfsDataWrapper(type_INT_ARRAY, results), nil
-	case type_LONG_ARRAY:
-		var len uint16
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "long array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		return sfsDataWrapper{typeId: TypeIntArray, data: results}, nil
+	case TypeLongArray:
+		length, bytes, err := decoder.readArray(8, "long array")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
-		results := make([]int64, len)
-		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "long array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		results := make([]int64, length)
+		for i := range results {
+			results[i] = int64(binary.BigEndian.Uint64(bytes[i*8:]))
 		}
-		return newsfsDataWrapper(type_LONG_ARRAY, results), nil
-	case type_FLOAT_ARRAY:
-		var len uint16
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "float array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		return sfsDataWrapper{typeId: TypeLongArray, data: results}, nil
+	case TypeFloatArray:
+		length, bytes, err := decoder.readArray(4, "float array")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
-		results := make([]float32, len)
-		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "float array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		results := make([]float32, length)
+		for i := range results {
+			results[i] = math.Float32frombits(binary.BigEndian.Uint32(bytes[i*4:]))
 		}
-		return newsfsDataWrapper(type_FLOAT_ARRAY, results), nil
-	case type_DOUBLE_ARRAY:
-		var len uint16
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "double array size", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		return sfsDataWrapper{typeId: TypeFloatArray, data: results}, nil
+	case TypeDoubleArray:
+		length, bytes, err := decoder.readArray(8, "double array")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
-		results := make([]float64, len)
-		if err := binary.Read(buf, binary.BigEndian, &results); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "double array", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		results := make([]float64, length)
+		for i := range results {
+			results[i] = math.Float64frombits(binary.BigEndian.Uint64(bytes[i*8:]))
 		}
-		return newsfsDataWrapper(type_DOUBLE_ARRAY, results), nil
-	case type_UTF_STRING_ARRAY:
-		var len uint16
-		if err := binary.Read(buf, binary.BigEndian, &len); err != nil {
-			return nil, &ErrReadingData{TypeToRead: "string array length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		return sfsDataWrapper{typeId: TypeDoubleArray, data: results}, nil
+	case TypeUtfStringArray:
+		length, err := decoder.readUint16("string array length")
+		if err != nil {
+			return sfsDataWrapper{}, err
 		}
-
-		results := make([]string, 0)
-		for i := uint16(0); i < len; i++ {
-			var strLen uint16
-			if err := binary.Read(buf, binary.BigEndian, &strLen); err != nil {
-				return nil, &ErrReadingData{TypeToRead: "string array element length", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+		results := make([]string, 0, min(int(length), decoder.remaining()/2))
+		for i := uint16(0); i < length; i++ {
+			strLen, err := decoder.readUint16("string array element length")
+			if err != nil {
+				return sfsDataWrapper{}, err
 			}
-			stringBytes := make([]byte, strLen)
-			if err := binary.Read(buf, binary.BigEndian, &stringBytes); err != nil {
-				return nil, &ErrReadingData{TypeToRead: "string array element bytes", Len: buf.Len(), Cap: buf.Cap(), IoErr: err}
+			stringBytes, err := decoder.read(int(strLen), "string array element bytes")
+			if err != nil {
+				return sfsDataWrapper{}, err
 			}
 			results = append(results, string(stringBytes))
 		}
-		return newsfsDataWrapper(type_UTF_STRING_ARRAY, results), nil
-	case type_SFS_ARRAY:
-		buf.UnreadByte()
-		obj, err := decodeSFSArray(buf)
+		return sfsDataWrapper{typeId: TypeUtfStringArray, data: results}, nil
+	case TypeSFSArray:
+		decoder.offset--
+		obj, err := decoder.decodeSFSArray()
 		if err != nil {
-			return nil, err
+			return sfsDataWrapper{}, err
 		}
-		return newsfsDataWrapper(type_SFS_ARRAY, *obj), nil
-	case type_SFS_OBJECT:
-		buf.UnreadByte()
-		obj, err := decodeSFSObject(buf)
+		return sfsDataWrapper{typeId: TypeSFSArray, data: obj}, nil
+	case TypeSFSObject:
+		decoder.offset--
+		obj, err := decoder.decodeSFSObject()
 		if err != nil {
-			return nil, err
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: TypeSFSObject, data: obj}, nil
+	default:
+		return sfsDataWrapper{}, &ErrDecodingUnsupportedType{Type: typeId, Len: len(decoder.data) - decoder.offset, Cap: len(decoder.data)}
+	}
+}
+
+func byteToBool(value byte) (bool, error) {
+	switch value {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		return false, ErrDecodingBool
+	}
+}
+
+// skipData advances past the next value without decoding it.
+func (decoder *sfsDecoder) skipData() error {
+	header, err := decoder.readByte("value header")
+	if err != nil {
+		return err
+	}
+	typeId := DataType(header)
+	switch typeId {
+	case TypeNull:
+		return nil
+	case TypeBool, TypeByte:
+		_, err = decoder.read(1, typeId.String())
+	case TypeShort:
+		_, err = decoder.read(2, typeId.String())
+	case TypeInt, TypeFloat:
+		_, err = decoder.read(4, typeId.String())
+	case TypeLong, TypeDouble:
+		_, err = decoder.read(8, typeId.String())
+	case TypeUtfString:
+		var length uint16
+		if length, err = decoder.readUint16("string length"); err == nil {
+			_, err = decoder.read(int(length), "string bytes")
+		}
+	case TypeText:
+		var length uint32
+		if length, err = decoder.readUint32("text length"); err == nil {
+			_, err = decoder.read(int(length), "text bytes")
+		}
+	case TypeByteArray:
+		var length uint32
+		if length, err = decoder.readUint32("byte array length"); err == nil {
+			_, err = decoder.read(int(length), "byte array")
+		}
+	case TypeBoolArray:
+		_, _, err = decoder.readArray(1, "bool array")
+	case TypeShortArray:
+		_, _, err = decoder.readArray(2, "short array")
+	case TypeIntArray, TypeFloatArray:
+		_, _, err = decoder.readArray(4, typeId.String())
+	case TypeLongArray, TypeDoubleArray:
+		_, _, err = decoder.readArray(8, typeId.String())
+	case TypeUtfStringArray:
+		var length, strLen uint16
+		if length, err = decoder.readUint16("string array length"); err != nil {
+			return err
+		}
+		for i := uint16(0); i < length && err == nil; i++ {
+			if strLen, err = decoder.readUint16("string array element length"); err == nil {
+				_, err = decoder.read(int(strLen), "string array element bytes")
+			}
+		}
+	case TypeSFSArray:
+		var size uint16
+		if size, err = decoder.readUint16("SFSArray size"); err != nil {
+			return err
+		}
+		if err = decoder.enter(); err != nil {
+			return err
+		}
+		defer decoder.leave()
+		for i := uint16(0); i < size && err == nil; i++ {
+			err = decoder.skipData()
+		}
+	case TypeSFSObject:
+		var size, keySize uint16
+		if size, err = decoder.readUint16("SFSObject size"); err != nil {
+			return err
+		}
+		if err = decoder.enter(); err != nil {
+			return err
+		}
+		defer decoder.leave()
+		for i := uint16(0); i < size && err == nil; i++ {
+			if keySize, err = decoder.readUint16("key size"); err != nil {
+				return err
+			}
+			if _, err = decoder.read(int(keySize), "key"); err == nil {
+				err = decoder.skipData()
+			}
 		}
-		return newsfsDataWrapper(type_SFS_OBJECT, *obj), nil
-	default: // sfsDataType(header)
-		return nil, &ErrDecodingUnsupportedType{sfsType: sfsDataType(header), Len: buf.Len(), Cap: buf.Cap()}
+	default:
+		return &ErrDecodingUnsupportedType{Type: typeId, Len: len(decoder.data) - decoder.offset, Cap: len(decoder.data)}
 	}
+	return err
 }
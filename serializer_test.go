@@ -0,0 +1,101 @@
+package sfstypes
+
+import (
+	"bytes"
+	"testing"
+)
+
+const benchmarkArrayLength = 10000
+
+func benchmarkIntArrayObject() *SFSObject {
+	values := make([]int32, benchmarkArrayLength)
+	for i := range values {
+		values[i] = int32(i * 7919)
+	}
+	sfsobject := NewSFSObject()
+	sfsobject.PutIntArray("values", values)
+	return sfsobject
+}
+
+func benchmarkDoubleArrayObject() *SFSObject {
+	values := make([]float64, benchmarkArrayLength)
+	for i := range values {
+		values[i] = float64(i) / 3
+	}
+	sfsobject := NewSFSObject()
+	sfsobject.PutDoubleArray("values", values)
+	return sfsobject
+}
+
+// TestLegacyCodecMatches checks that the benchmarks compare codecs that
+// produce and accept the same bytes.
+func TestLegacyCodecMatches(t *testing.T) {
+	for _, sfsobject := range []*SFSObject{benchmarkIntArrayObject(), benchmarkDoubleArrayObject()} {
+		data := sfsobject.ToBinary()
+		if legacy := legacyEncodeSFSObject(sfsobject); !bytes.Equal(legacy, data) {
+			t.Fatal("legacy encoding differs")
+		}
+		decoded, err := legacyDecodeSFSObject(bytes.NewBuffer(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded.ToBinary(), data) {
+			t.Fatal("legacy decoding differs")
+		}
+	}
+}
+
+func benchmarkEncode(b *testing.B, sfsobject *SFSObject) {
+	b.Run("append", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(sfsobject.ToBinary())))
+		for i := 0; i < b.N; i++ {
+			sfsobject.ToBinary()
+		}
+	})
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(sfsobject.ToBinary())))
+		for i := 0; i < b.N; i++ {
+			legacyEncodeSFSObject(sfsobject)
+		}
+	})
+}
+
+func benchmarkDecode(b *testing.B, sfsobject *SFSObject) {
+	data := sfsobject.ToBinary()
+	b.Run("append", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := NewSFSObjectFromBinaryData(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := legacyDecodeSFSObject(bytes.NewBuffer(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEncodeIntArray10k(b *testing.B) {
+	benchmarkEncode(b, benchmarkIntArrayObject())
+}
+
+func BenchmarkEncodeDoubleArray10k(b *testing.B) {
+	benchmarkEncode(b, benchmarkDoubleArrayObject())
+}
+
+func BenchmarkDecodeIntArray10k(b *testing.B) {
+	benchmarkDecode(b, benchmarkIntArrayObject())
+}
+
+func BenchmarkDecodeDoubleArray10k(b *testing.B) {
+	benchmarkDecode(b, benchmarkDoubleArrayObject())
+}
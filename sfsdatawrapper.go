@@ -1,86 +1,185 @@
 package sfstypes
 
-type sfsDataType byte
+import (
+	"fmt"
+	"slices"
+)
+
+// DataType is the wire type id that precedes every value in the SFS2X binary
+// protocol.
+type DataType byte
 
 const (
-	type_NULL             sfsDataType = 0
-	type_BOOL             sfsDataType = 1
-	type_BYTE             sfsDataType = 2
-	type_SHORT            sfsDataType = 3
-	type_INT              sfsDataType = 4
-	type_LONG             sfsDataType = 5
-	type_FLOAT            sfsDataType = 6
-	type_DOUBLE           sfsDataType = 7
-	type_UTF_STRING       sfsDataType = 8
-	type_BOOL_ARRAY       sfsDataType = 9
-	type_BYTE_ARRAY       sfsDataType = 10
-	type_SHORT_ARRAY      sfsDataType = 11
-	type_INT_ARRAY        sfsDataType = 12
-	type_LONG_ARRAY       sfsDataType = 13
-	type_FLOAT_ARRAY      sfsDataType = 14
-	type_DOUBLE_ARRAY     sfsDataType = 15
-	type_UTF_STRING_ARRAY sfsDataType = 16
-	type_SFS_ARRAY        sfsDataType = 17
-	type_SFS_OBJECT       sfsDataType = 18
-	type_CLASS            sfsDataType = 19
-	type_TEXT             sfsDataType = 20
+	TypeNull           DataType = 0
+	TypeBool           DataType = 1
+	TypeByte           DataType = 2
+	TypeShort          DataType = 3
+	TypeInt            DataType = 4
+	TypeLong           DataType = 5
+	TypeFloat          DataType = 6
+	TypeDouble         DataType = 7
+	TypeUtfString      DataType = 8
+	TypeBoolArray      DataType = 9
+	TypeByteArray      DataType = 10
+	TypeShortArray     DataType = 11
+	TypeIntArray       DataType = 12
+	TypeLongArray      DataType = 13
+	TypeFloatArray     DataType = 14
+	TypeDoubleArray    DataType = 15
+	TypeUtfStringArray DataType = 16
+	TypeSFSArray       DataType = 17
+	TypeSFSObject      DataType = 18
+	TypeClass          DataType = 19
+	TypeText           DataType = 20
 )
 
 type sfsDataWrapper struct {
-	typeId sfsDataType
+	typeId DataType
 	data   interface{}
 }
 
-func newsfsDataWrapper(typeId sfsDataType, data interface{}) *sfsDataWrapper {
+func newsfsDataWrapper(typeId DataType, data interface{}) *sfsDataWrapper {
 	return &sfsDataWrapper{
 		typeId: typeId,
 		data:   data,
 	}
 }
 
-func sfsTypeToString(sfsType sfsDataType) string {
-	switch sfsType {
-	case type_NULL:
+// interfaceValue returns the data as the Go type the Get accessors use.
+// BYTE_ARRAY data is held as []byte but exposed as []int8.
+func (wrapper *sfsDataWrapper) interfaceValue() interface{} {
+	if wrapper.typeId == TypeByteArray {
+		return BytesToInt8s(wrapper.data.([]byte))
+	}
+	return wrapper.data
+}
+
+func (wrapper *sfsDataWrapper) deepCopy() sfsDataWrapper {
+	switch data := wrapper.data.(type) {
+	case []bool:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case []byte:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case []int16:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case []int32:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case []int64:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case []float32:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case []float64:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case []string:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: slices.Clone(data)}
+	case *SFSArray:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: data.deepCopy()}
+	case *SFSObject:
+		return sfsDataWrapper{typeId: wrapper.typeId, data: data.deepCopy()}
+	default:
+		return *wrapper
+	}
+}
+
+var dataTypeNames = [...]string{
+	TypeNull:           "NULL",
+	TypeBool:           "BOOL",
+	TypeByte:           "BYTE",
+	TypeShort:          "SHORT",
+	TypeInt:            "INT",
+	TypeLong:           "LONG",
+	TypeFloat:          "FLOAT",
+	TypeDouble:         "DOUBLE",
+	TypeUtfString:      "UTF_STRING",
+	TypeBoolArray:      "BOOL_ARRAY",
+	TypeByteArray:      "BYTE_ARRAY",
+	TypeShortArray:     "SHORT_ARRAY",
+	TypeIntArray:       "INT_ARRAY",
+	TypeLongArray:      "LONG_ARRAY",
+	TypeFloatArray:     "FLOAT_ARRAY",
+	TypeDoubleArray:    "DOUBLE_ARRAY",
+	TypeUtfStringArray: "UTF_STRING_ARRAY",
+	TypeSFSArray:       "SFS_ARRAY",
+	TypeSFSObject:      "SFS_OBJECT",
+	TypeClass:          "CLASS",
+	TypeText:           "TEXT",
+}
+
+// Name returns the SFS2X name of the type, e.g. "UTF_STRING_ARRAY".
+func (dataType DataType) Name() string {
+	if int(dataType) < len(dataTypeNames) {
+		return dataTypeNames[dataType]
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", byte(dataType))
+}
+
+// ParseDataType returns the type with the given SFS2X name, as returned by
+// Name.
+func ParseDataType(name string) (DataType, error) {
+	for dataType, dataTypeName := range dataTypeNames {
+		if dataTypeName == name {
+			return DataType(dataType), nil
+		}
+	}
+	return TypeNull, fmt.Errorf("unknown SFS data type %q", name)
+}
+
+func (dataType DataType) MarshalText() ([]byte, error) {
+	return []byte(dataType.Name()), nil
+}
+
+func (dataType *DataType) UnmarshalText(text []byte) error {
+	parsed, err := ParseDataType(string(text))
+	if err != nil {
+		return err
+	}
+	*dataType = parsed
+	return nil
+}
+
+func (dataType DataType) String() string {
+	switch dataType {
+	case TypeNull:
 		return "NULL/nil"
-	case type_BOOL:
+	case TypeBool:
 		return "BOOL/bool"
-	case type_BYTE:
+	case TypeByte:
 		return "BYTE/int8"
-	case type_SHORT:
+	case TypeShort:
 		return "SHORT/int16"
-	case type_INT:
+	case TypeInt:
 		return "INT/int32"
-	case type_LONG:
+	case TypeLong:
 		return "LONG/int64"
-	case type_FLOAT:
+	case TypeFloat:
 		return "FLOAT/float32"
-	case type_DOUBLE:
+	case TypeDouble:
 		return "DOUBLE/float64"
-	case type_UTF_STRING:
+	case TypeUtfString:
 		return "UTF_STRING/string"
-	case type_BOOL_ARRAY:
+	case TypeBoolArray:
 		return "BOOL_ARRAY/[]bool"
-	case type_BYTE_ARRAY:
+	case TypeByteArray:
 		return "BYTE_ARRAY/[]int8"
-	case type_SHORT_ARRAY:
+	case TypeShortArray:
 		return "SHORT_ARRAY/[]int16"
-	case type_INT_ARRAY:
+	case TypeIntArray:
 		return "INT_ARRAY/[]int32"
-	case type_LONG_ARRAY:
+	case TypeLongArray:
 		return "LONG_ARRAY/[]int64"
-	case type_FLOAT_ARRAY:
+	case TypeFloatArray:
 		return "FLOAT_ARRAY/[]float32"
-	case type_DOUBLE_ARRAY:
+	case TypeDoubleArray:
 		return "DOUBLE_ARRAY/[]float64"
-	case type_UTF_STRING_ARRAY:
+	case TypeUtfStringArray:
 		return "UTF_STRING_ARRAY/[]string"
-	case type_SFS_ARRAY:
+	case TypeSFSArray:
 		return "SFS_ARRAY"
-	case type_SFS_OBJECT:
+	case TypeSFSObject:
 		return "SFS_OBJECT"
-	case type_CLASS:
+	case TypeClass:
 		return "CLASS (unsupported)"
-	case type_TEXT:
+	case TypeText:
 		return "TEXT/string"
 	default:
 		return "unknown type"
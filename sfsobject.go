@@ -1,11 +1,17 @@
 package sfstypes
 
 import (
-	"fmt"
+	"maps"
+	"slices"
 )
 
 type SFSObject struct {
-	dataHolder map[string]sfsDataWrapper
+	dataHolder    map[string]sfsDataWrapper
+	numericPolicy NumericPolicy
+	sqlFormat     SQLFormat
+	// peakSize is the most entries dataHolder was sized for. Go maps don't
+	// shrink, so Release measures the object by it.
+	peakSize int
 }
 
 func NewSFSObject() *SFSObject {
@@ -22,6 +28,10 @@ func NewSFSObjectFromJsonData(jsonString string) (*SFSObject, error) {
 	return sfsObjectFromJson(jsonString)
 }
 
+func NewSFSObjectFromJsonDataWithOptions(jsonString string, options JsonOptions) (*SFSObject, error) {
+	return sfsObjectFromJsonWithOptions(jsonString, options)
+}
+
 /*
 // TODO
 func NewSFSObjectFromResultSet() (*SFSObject, error) {
@@ -29,10 +39,18 @@ func NewSFSObjectFromResultSet() (*SFSObject, error) {
 }
 */
 
+// ToBinary encodes sfsobject in the SFS2X binary format. Keys are written in
+// sorted order at every level, so equal objects encode to equal bytes.
 func (sfsobject *SFSObject) ToBinary() []byte {
 	return encodeSFSObject(sfsobject)
 }
 
+// AppendBinary appends the binary encoding of sfsobject to dst and returns
+// the extended slice.
+func (sfsobject *SFSObject) AppendBinary(dst []byte) []byte {
+	return appendSFSObject(dst, sfsobject)
+}
+
 func (sfsobject *SFSObject) ToJson() string {
 	return sfsObjectToJson(sfsobject)
 }
@@ -41,16 +59,9 @@ func (sfsobject *SFSObject) Size() int {
 	return len(sfsobject.dataHolder)
 }
 
-func (sfsobject *SFSObject) GetHexDump() string {
-	bytes := sfsobject.ToBinary()
-	hexString := fmt.Sprintf("binary size: %d\n", len(bytes))
-	for i, b := range bytes {
-		if i%16 == 0 && i != 0 {
-			hexString += "\n"
-		}
-		hexString += fmt.Sprintf("%02x ", b)
-	}
-	return hexString
+// Reset removes all keys while keeping the allocated storage for reuse.
+func (sfsobject *SFSObject) Reset() {
+	clear(sfsobject.dataHolder)
 }
 
 func (sfsobject *SFSObject) GetKeys() []string {
@@ -61,6 +72,12 @@ func (sfsobject *SFSObject) GetKeys() []string {
 	return keys
 }
 
+// sortedKeys returns the keys of sfsobject in canonical order: sorted by
+// their bytes. Encoders use it so that equal objects produce equal output.
+func (sfsobject *SFSObject) sortedKeys() []string {
+	return slices.Sorted(maps.Keys(sfsobject.dataHolder))
+}
+
 func (sfsobject *SFSObject) ContainsKey(key string) bool {
 	if _, exists := sfsobject.dataHolder[key]; !exists {
 		return false
@@ -70,7 +87,7 @@ func (sfsobject *SFSObject) ContainsKey(key string) bool {
 
 func (sfsobject *SFSObject) RemoveElement(key string) error {
 	if _, exists := sfsobject.dataHolder[key]; !exists {
-		return &ErrKeyNotFound{key: key}
+		return &ErrKeyNotFound{Key: key}
 	}
 	delete(sfsobject.dataHolder, key)
 	return nil
@@ -87,18 +104,39 @@ func (sfsobject *SFSObject) IsNull(key string) (bool, error) {
 	return false, err
 }
 
+func (sfsobject *SFSObject) TypeOf(key string) (DataType, error) {
+	value, err := sfsobject.getWrapper(key)
+	if err != nil {
+		return TypeNull, err
+	}
+	return value.typeId, nil
+}
+
+func (sfsobject *SFSObject) deepCopy() *SFSObject {
+	result := &SFSObject{
+		dataHolder:    make(map[string]sfsDataWrapper, len(sfsobject.dataHolder)),
+		numericPolicy: sfsobject.numericPolicy,
+		sqlFormat:     sfsobject.sqlFormat,
+		peakSize:      len(sfsobject.dataHolder),
+	}
+	for key, value := range sfsobject.dataHolder {
+		result.dataHolder[key] = value.deepCopy()
+	}
+	return result
+}
+
 func (sfsobject *SFSObject) getWrapper(key string) (*sfsDataWrapper, error) {
 	if value, exists := sfsobject.dataHolder[key]; exists {
 		return &value, nil
 	}
-	return nil, &ErrKeyNotFound{key: key}
+	return nil, &ErrKeyNotFound{Key: key}
 }
 
 func (sfsobject *SFSObject) getData(key string) (interface{}, error) {
 	if value, exists := sfsobject.dataHolder[key]; exists {
 		return value.data, nil
 	}
-	return nil, &ErrKeyNotFound{key: key}
+	return nil, &ErrKeyNotFound{Key: key}
 }
 
 func (sfsobject *SFSObject) Get(key string) (interface{}, error) {
@@ -107,58 +145,66 @@ func (sfsobject *SFSObject) Get(key string) (interface{}, error) {
 		return nil, err
 	}
 	switch value.typeId {
-	case type_NULL:
+	case TypeNull:
 		return nil, nil
-	case type_BOOL:
+	case TypeBool:
 		return value.data.(bool), nil
-	case type_BYTE:
+	case TypeByte:
 		return value.data.(int8), nil
-	case type_SHORT:
+	case TypeShort:
 		return value.data.(int16), nil
-	case type_INT:
+	case TypeInt:
 		return value.data.(int32), nil
-	case type_LONG:
+	case TypeLong:
 		return value.data.(int64), nil
-	case type_FLOAT:
+	case TypeFloat:
 		return value.data.(float32), nil
-	case type_DOUBLE:
+	case TypeDouble:
 		return value.data.(float64), nil
-	case type_UTF_STRING:
+	case TypeUtfString:
 		return value.data.(string), nil
-	case type_BOOL_ARRAY:
+	case TypeBoolArray:
 		return value.data.([]bool), nil
-	case type_BYTE_ARRAY:
-		return value.data.([]int8), nil
-	case type_SHORT_ARRAY:
+	case TypeByteArray:
+		return BytesToInt8s(value.data.([]byte)), nil
+	case TypeShortArray:
 		return value.data.([]int16), nil
-	case type_INT_ARRAY:
+	case TypeIntArray:
 		return value.data.([]int32), nil
-	case type_LONG_ARRAY:
+	case TypeLongArray:
 		return value.data.([]int64), nil
-	case type_FLOAT_ARRAY:
+	case TypeFloatArray:
 		return value.data.([]float32), nil
-	case type_DOUBLE_ARRAY:
+	case TypeDoubleArray:
 		return value.data.([]float64), nil
-	case type_UTF_STRING_ARRAY:
+	case TypeUtfStringArray:
 		return value.data.([]string), nil
-	case type_SFS_ARRAY:
-		return value.data.(SFSArray), nil
-	case type_SFS_OBJECT:
-		return value.data.(SFSObject), nil
-	case type_TEXT:
+	case TypeSFSArray:
+		return value.data.(*SFSArray), nil
+	case TypeSFSObject:
+		return value.data.(*SFSObject), nil
+	case TypeText:
 		return value.data.(string), nil
 	default:
-		return nil, &ErrUnsupportedType{value: value}
+		return nil, &ErrUnsupportedType{Value: value}
+	}
+}
+
+func (sfsobject *SFSObject) GetValue(key string) (Value, error) {
+	value, err := sfsobject.getWrapper(key)
+	if err != nil {
+		return Value{}, err
 	}
+	return Value{wrapper: *value}, nil
 }
 
 func (sfsobject *SFSObject) GetBool(key string) (bool, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_BOOL {
+		if value.typeId == TypeBool {
 			return value.data.(bool), nil
 		}
-		return false, &ErrWrongType{actualType: value.typeId, wantedType: type_BOOL}
+		return false, &ErrWrongType{ActualType: value.typeId, WantedType: TypeBool}
 	}
 	return false, err
 }
@@ -166,10 +212,10 @@ func (sfsobject *SFSObject) GetBool(key string) (bool, error) {
 func (sfsobject *SFSObject) GetBoolArray(key string) ([]bool, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_BOOL_ARRAY {
+		if value.typeId == TypeBoolArray {
 			return value.data.([]bool), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_BOOL_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeBoolArray}
 	}
 	return nil, err
 }
@@ -177,10 +223,10 @@ func (sfsobject *SFSObject) GetBoolArray(key string) ([]bool, error) {
 func (sfsobject *SFSObject) GetByte(key string) (int8, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_BYTE {
+		if value.typeId == TypeByte {
 			return value.data.(int8), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByte}
 	}
 	return 0, err
 }
@@ -188,10 +234,10 @@ func (sfsobject *SFSObject) GetByte(key string) (int8, error) {
 func (sfsobject *SFSObject) GetByteArray(key string) ([]int8, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_BYTE_ARRAY {
-			return value.data.([]int8), nil
+		if value.typeId == TypeByteArray {
+			return BytesToInt8s(value.data.([]byte)), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByteArray}
 	}
 	return nil, err
 }
@@ -199,10 +245,10 @@ func (sfsobject *SFSObject) GetByteArray(key string) ([]int8, error) {
 func (sfsobject *SFSObject) GetDouble(key string) (float64, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_DOUBLE {
+		if value.typeId == TypeDouble {
 			return value.data.(float64), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_DOUBLE}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeDouble}
 	}
 	return 0, err
 }
@@ -210,10 +256,10 @@ func (sfsobject *SFSObject) GetDouble(key string) (float64, error) {
 func (sfsobject *SFSObject) GetDoubleArray(key string) ([]float64, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_DOUBLE_ARRAY {
+		if value.typeId == TypeDoubleArray {
 			return value.data.([]float64), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_DOUBLE_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeDoubleArray}
 	}
 	return nil, err
 }
@@ -221,10 +267,10 @@ func (sfsobject *SFSObject) GetDoubleArray(key string) ([]float64, error) {
 func (sfsobject *SFSObject) GetFloat(key string) (float32, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_FLOAT {
+		if value.typeId == TypeFloat {
 			return value.data.(float32), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_FLOAT}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeFloat}
 	}
 	return 0, err
 }
@@ -232,10 +278,10 @@ func (sfsobject *SFSObject) GetFloat(key string) (float32, error) {
 func (sfsobject *SFSObject) GetFloatArray(key string) ([]float32, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_FLOAT_ARRAY {
+		if value.typeId == TypeFloatArray {
 			return value.data.([]float32), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_FLOAT_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeFloatArray}
 	}
 	return nil, err
 }
@@ -243,10 +289,10 @@ func (sfsobject *SFSObject) GetFloatArray(key string) ([]float32, error) {
 func (sfsobject *SFSObject) GetInt(key string) (int32, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_INT {
+		if value.typeId == TypeInt {
 			return value.data.(int32), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_INT}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeInt}
 	}
 	return 0, err
 }
@@ -254,10 +300,10 @@ func (sfsobject *SFSObject) GetInt(key string) (int32, error) {
 func (sfsobject *SFSObject) GetIntArray(key string) ([]int32, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_INT_ARRAY {
+		if value.typeId == TypeIntArray {
 			return value.data.([]int32), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_INT_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeIntArray}
 	}
 	return nil, err
 }
@@ -265,10 +311,10 @@ func (sfsobject *SFSObject) GetIntArray(key string) ([]int32, error) {
 func (sfsobject *SFSObject) GetLong(key string) (int64, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_LONG {
+		if value.typeId == TypeLong {
 			return value.data.(int64), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_LONG}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeLong}
 	}
 	return 0, err
 }
@@ -276,43 +322,67 @@ func (sfsobject *SFSObject) GetLong(key string) (int64, error) {
 func (sfsobject *SFSObject) GetLongArray(key string) ([]int64, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_LONG_ARRAY {
+		if value.typeId == TypeLongArray {
 			return value.data.([]int64), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_LONG_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeLongArray}
 	}
 	return nil, err
 }
 
-func (sfsobject *SFSObject) GetSFSArray(key string) (SFSArray, error) {
+// GetSFSArray returns the nested SFSArray stored under key. The returned
+// pointer aliases the stored array, so changes made through it are visible
+// in sfsobject. Use GetSFSArrayCopy for a detached copy.
+func (sfsobject *SFSObject) GetSFSArray(key string) (*SFSArray, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_SFS_ARRAY {
-			return value.data.(SFSArray), nil
+		if value.typeId == TypeSFSArray {
+			return value.data.(*SFSArray), nil
 		}
-		return *NewSFSArray(), &ErrWrongType{actualType: value.typeId, wantedType: type_SFS_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeSFSArray}
 	}
-	return *NewSFSArray(), err
+	return nil, err
 }
 
-func (sfsobject *SFSObject) GetSFSObject(key string) (SFSObject, error) {
+// GetSFSArrayCopy returns a deep copy of the nested SFSArray stored under key.
+func (sfsobject *SFSObject) GetSFSArrayCopy(key string) (*SFSArray, error) {
+	value, err := sfsobject.GetSFSArray(key)
+	if err != nil {
+		return nil, err
+	}
+	return value.deepCopy(), nil
+}
+
+// GetSFSObject returns the nested SFSObject stored under key. The returned
+// pointer aliases the stored object, so changes made through it are visible
+// in sfsobject. Use GetSFSObjectCopy for a detached copy.
+func (sfsobject *SFSObject) GetSFSObject(key string) (*SFSObject, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_SFS_OBJECT {
-			return value.data.(SFSObject), nil
+		if value.typeId == TypeSFSObject {
+			return value.data.(*SFSObject), nil
 		}
-		return *NewSFSObject(), &ErrWrongType{actualType: value.typeId, wantedType: type_SFS_OBJECT}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeSFSObject}
+	}
+	return nil, err
+}
+
+// GetSFSObjectCopy returns a deep copy of the nested SFSObject stored under key.
+func (sfsobject *SFSObject) GetSFSObjectCopy(key string) (*SFSObject, error) {
+	value, err := sfsobject.GetSFSObject(key)
+	if err != nil {
+		return nil, err
 	}
-	return *NewSFSObject(), err
+	return value.deepCopy(), nil
 }
 
 func (sfsobject *SFSObject) GetShort(key string) (int16, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_SHORT {
+		if value.typeId == TypeShort {
 			return value.data.(int16), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_SHORT}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeShort}
 	}
 	return 0, err
 }
@@ -320,10 +390,10 @@ func (sfsobject *SFSObject) GetShort(key string) (int16, error) {
 func (sfsobject *SFSObject) GetShortArray(key string) ([]int16, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_SHORT_ARRAY {
+		if value.typeId == TypeShortArray {
 			return value.data.([]int16), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_SHORT_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeShortArray}
 	}
 	return nil, err
 }
@@ -331,10 +401,10 @@ func (sfsobject *SFSObject) GetShortArray(key string) ([]int16, error) {
 func (sfsobject *SFSObject) GetUnsignedByte(key string) (uint8, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_BYTE {
-			return value.data.(uint8), nil
+		if value.typeId == TypeByte {
+			return uint8(value.data.(int8)), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByte}
 	}
 	return 0, err
 }
@@ -342,10 +412,10 @@ func (sfsobject *SFSObject) GetUnsignedByte(key string) (uint8, error) {
 func (sfsobject *SFSObject) GetUnsignedByteArray(key string) ([]uint8, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_BYTE_ARRAY {
-			return value.data.([]uint8), nil
+		if value.typeId == TypeByteArray {
+			return value.data.([]byte), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByteArray}
 	}
 	return nil, err
 }
@@ -353,10 +423,10 @@ func (sfsobject *SFSObject) GetUnsignedByteArray(key string) ([]uint8, error) {
 func (sfsobject *SFSObject) GetUtfString(key string) (string, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_UTF_STRING {
+		if value.typeId == TypeUtfString {
 			return value.data.(string), nil
 		}
-		return "", &ErrWrongType{actualType: value.typeId, wantedType: type_UTF_STRING}
+		return "", &ErrWrongType{ActualType: value.typeId, WantedType: TypeUtfString}
 	}
 	return "", err
 }
@@ -364,10 +434,10 @@ func (sfsobject *SFSObject) GetUtfString(key string) (string, error) {
 func (sfsobject *SFSObject) GetText(key string) (string, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_TEXT {
+		if value.typeId == TypeText {
 			return value.data.(string), nil
 		}
-		return "", &ErrWrongType{actualType: value.typeId, wantedType: type_TEXT}
+		return "", &ErrWrongType{ActualType: value.typeId, WantedType: TypeText}
 	}
 	return "", err
 }
@@ -375,10 +445,10 @@ func (sfsobject *SFSObject) GetText(key string) (string, error) {
 func (sfsobject *SFSObject) GetUtfStringArray(key string) ([]string, error) {
 	value, err := sfsobject.getWrapper(key)
 	if err == nil {
-		if value.typeId == type_UTF_STRING_ARRAY {
+		if value.typeId == TypeUtfStringArray {
 			return value.data.([]string), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_UTF_STRING_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeUtfStringArray}
 	}
 	return nil, err
 }
@@ -387,19 +457,24 @@ func (sfsobject *SFSObject) putsfsDataWrapper(key string, wrapper *sfsDataWrappe
 	if key == "" {
 		return ErrKeyEmpty
 	} else if len(key) > 255 {
-		return &ErrInvalidKeySize{key: key, length: len(key)}
+		return &ErrInvalidKeySize{Key: key, Length: len(key)}
 	}
 	sfsobject.dataHolder[key] = *wrapper
+	sfsobject.peakSize = max(sfsobject.peakSize, len(sfsobject.dataHolder))
 	return nil
 }
 
-func (sfsobject *SFSObject) putData(key string, value interface{}, typeId sfsDataType) error {
-	if typeId != type_NULL && value == nil {
+func (sfsobject *SFSObject) putData(key string, value interface{}, typeId DataType) error {
+	if typeId != TypeNull && value == nil {
 		return ErrDataNull
 	}
 	return sfsobject.putsfsDataWrapper(key, newsfsDataWrapper(typeId, value))
 }
 
+// Put stores value under the SFS type matching its Go type. SFSObjects and
+// SFSArrays must be passed as pointers and are stored by reference, like
+// PutSFSObject and PutSFSArray do; passing one by value returns an
+// ErrUnsupportedType.
 func (sfsobject *SFSObject) Put(key string, value interface{}) error {
 	switch v := value.(type) {
 	case bool:
@@ -410,6 +485,10 @@ func (sfsobject *SFSObject) Put(key string, value interface{}) error {
 		return sfsobject.PutByte(key, v)
 	case []int8:
 		return sfsobject.PutByteArray(key, v)
+	case uint8:
+		return sfsobject.PutUnsignedByte(key, v)
+	case []byte:
+		return sfsobject.PutUnsignedByteArray(key, v)
 	case float32:
 		return sfsobject.PutFloat(key, v)
 	case []float32:
@@ -418,14 +497,6 @@ func (sfsobject *SFSObject) Put(key string, value interface{}) error {
 		return sfsobject.PutDouble(key, v)
 	case []float64:
 		return sfsobject.PutDoubleArray(key, v)
-	case int:
-		return sfsobject.PutInt(key, int32(v))
-	case []int:
-		arr := make([]int32, len(v))
-		for i, element := range v {
-			arr[i] = int32(element)
-		}
-		return sfsobject.PutIntArray(key, arr)
 	case int32:
 		return sfsobject.PutInt(key, v)
 	case []int32:
@@ -436,10 +507,9 @@ func (sfsobject *SFSObject) Put(key string, value interface{}) error {
 		return sfsobject.PutLongArray(key, v)
 	case nil:
 		return sfsobject.PutNull(key)
-	case SFSArray:
-		return sfsobject.PutSFSArray(key, &v)
-	case SFSObject:
-		return sfsobject.PutSFSObject(key, &v)
+	case SFSArray, SFSObject:
+		// A copy would share its storage with the original only partly.
+		return &ErrUnsupportedType{Value: value}
 	case *SFSArray:
 		return sfsobject.PutSFSArray(key, v)
 	case *SFSObject:
@@ -453,85 +523,117 @@ func (sfsobject *SFSObject) Put(key string, value interface{}) error {
 	case []string:
 		return sfsobject.PutUtfStringArray(key, v)
 	}
-	return &ErrUnsupportedType{value: value}
+	wrapper, err := convertValue(value, sfsobject.numericPolicy)
+	if err != nil {
+		return err
+	}
+	return sfsobject.putsfsDataWrapper(key, &wrapper)
+}
+
+func (sfsobject *SFSObject) PutValue(key string, value Value) error {
+	return sfsobject.putsfsDataWrapper(key, &value.wrapper)
 }
 
 func (sfsobject *SFSObject) PutBool(key string, value bool) error {
-	return sfsobject.putData(key, value, type_BOOL)
+	return sfsobject.putData(key, value, TypeBool)
 }
 
 func (sfsobject *SFSObject) PutBoolArray(key string, value []bool) error {
-	return sfsobject.putData(key, value, type_BOOL_ARRAY)
+	return sfsobject.putData(key, value, TypeBoolArray)
 }
 
 func (sfsobject *SFSObject) PutByte(key string, value int8) error {
-	return sfsobject.putData(key, value, type_BYTE)
+	return sfsobject.putData(key, value, TypeByte)
 }
 
+// PutByteArray stores value as a BYTE_ARRAY. The array shares memory with
+// value.
 func (sfsobject *SFSObject) PutByteArray(key string, value []int8) error {
-	return sfsobject.putData(key, value, type_BYTE_ARRAY)
+	return sfsobject.putData(key, Int8sToBytes(value), TypeByteArray)
+}
+
+func (sfsobject *SFSObject) PutUnsignedByte(key string, value uint8) error {
+	return sfsobject.putData(key, int8(value), TypeByte)
+}
+
+// PutUnsignedByteArray stores value as a BYTE_ARRAY. The array shares memory
+// with value.
+func (sfsobject *SFSObject) PutUnsignedByteArray(key string, value []byte) error {
+	return sfsobject.putData(key, value, TypeByteArray)
 }
 
 func (sfsobject *SFSObject) PutDouble(key string, value float64) error {
-	return sfsobject.putData(key, value, type_DOUBLE)
+	return sfsobject.putData(key, value, TypeDouble)
 }
 
 func (sfsobject *SFSObject) PutDoubleArray(key string, value []float64) error {
-	return sfsobject.putData(key, value, type_DOUBLE_ARRAY)
+	return sfsobject.putData(key, value, TypeDoubleArray)
 }
 
 func (sfsobject *SFSObject) PutFloat(key string, value float32) error {
-	return sfsobject.putData(key, value, type_FLOAT)
+	return sfsobject.putData(key, value, TypeFloat)
 }
 
 func (sfsobject *SFSObject) PutFloatArray(key string, value []float32) error {
-	return sfsobject.putData(key, value, type_FLOAT_ARRAY)
+	return sfsobject.putData(key, value, TypeFloatArray)
 }
 
 func (sfsobject *SFSObject) PutInt(key string, value int32) error {
-	return sfsobject.putData(key, value, type_INT)
+	return sfsobject.putData(key, value, TypeInt)
 }
 
 func (sfsobject *SFSObject) PutIntArray(key string, value []int32) error {
-	return sfsobject.putData(key, value, type_INT_ARRAY)
+	return sfsobject.putData(key, value, TypeIntArray)
 }
 
 func (sfsobject *SFSObject) PutLong(key string, value int64) error {
-	return sfsobject.putData(key, value, type_LONG)
+	return sfsobject.putData(key, value, TypeLong)
 }
 
 func (sfsobject *SFSObject) PutLongArray(key string, value []int64) error {
-	return sfsobject.putData(key, value, type_LONG_ARRAY)
+	return sfsobject.putData(key, value, TypeLongArray)
 }
 
 func (sfsobject *SFSObject) PutNull(key string) error {
-	return sfsobject.putData(key, nil, type_NULL)
+	return sfsobject.putData(key, nil, TypeNull)
 }
 
+// PutSFSArray stores value by reference: later changes to value are visible
+// through sfsobject and vice versa. A nil value returns ErrDataNull; use
+// PutNull to store NULL.
 func (sfsobject *SFSObject) PutSFSArray(key string, value *SFSArray) error {
-	return sfsobject.putData(key, *value, type_SFS_ARRAY)
+	if value == nil {
+		return ErrDataNull
+	}
+	return sfsobject.putData(key, value, TypeSFSArray)
 }
 
+// PutSFSObject stores value by reference: later changes to value are visible
+// through sfsobject and vice versa. A nil value returns ErrDataNull; use
+// PutNull to store NULL. An object must not contain itself.
 func (sfsobject *SFSObject) PutSFSObject(key string, value *SFSObject) error {
-	return sfsobject.putData(key, *value, type_SFS_OBJECT)
+	if value == nil {
+		return ErrDataNull
+	}
+	return sfsobject.putData(key, value, TypeSFSObject)
 }
 
 func (sfsobject *SFSObject) PutShort(key string, value int16) error {
-	return sfsobject.putData(key, value, type_SHORT)
+	return sfsobject.putData(key, value, TypeShort)
 }
 
 func (sfsobject *SFSObject) PutShortArray(key string, value []int16) error {
-	return sfsobject.putData(key, value, type_SHORT_ARRAY)
+	return sfsobject.putData(key, value, TypeShortArray)
 }
 
 func (sfsobject *SFSObject) PutUtfString(key string, value string) error {
-	return sfsobject.putData(key, value, type_UTF_STRING)
+	return sfsobject.putData(key, value, TypeUtfString)
 }
 
 func (sfsobject *SFSObject) PutText(key string, value string) error {
-	return sfsobject.putData(key, value, type_TEXT)
+	return sfsobject.putData(key, value, TypeText)
 }
 
 func (sfsobject *SFSObject) PutUtfStringArray(key string, value []string) error {
-	return sfsobject.putData(key, value, type_UTF_STRING_ARRAY)
+	return sfsobject.putData(key, value, TypeUtfStringArray)
 }
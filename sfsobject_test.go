@@ -0,0 +1,184 @@
+package sfstypes
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// fuzzSeedObjects returns valid payloads that together hold every wire type,
+// nested containers and edge-case lengths.
+func fuzzSeedObjects() []*SFSObject {
+	nested := NewSFSObject()
+	nested.PutUtfString("name", "nested")
+	array := NewSFSArray()
+	array.AddNull()
+	array.AddInt(1)
+	array.AddSFSObject(nested)
+	array.AddSFSArray(NewSFSArray())
+
+	all := NewSFSObject()
+	all.PutNull("null")
+	all.PutBool("bool", true)
+	all.PutByte("byte", -1)
+	all.PutShort("short", math.MinInt16)
+	all.PutInt("int", math.MaxInt32)
+	all.PutLong("long", math.MinInt64)
+	all.PutFloat("float", 1.5)
+	all.PutDouble("double", -0.25)
+	all.PutUtfString("string", "héllo")
+	all.PutBoolArray("bools", []bool{true, false})
+	all.PutByteArray("bytes", []int8{-128, 0, 127})
+	all.PutShortArray("shorts", []int16{1, -1})
+	all.PutIntArray("ints", []int32{1, -1})
+	all.PutLongArray("longs", []int64{1, -1})
+	all.PutFloatArray("floats", []float32{1, -1})
+	all.PutDoubleArray("doubles", []float64{1, -1})
+	all.PutUtfStringArray("strings", []string{"", "a"})
+	all.PutSFSArray("array", array)
+	all.PutSFSObject("object", nested)
+	all.PutText("text", "text")
+
+	lengths := NewSFSObject()
+	lengths.PutUtfString("empty", "")
+	lengths.PutUtfString("long", strings.Repeat("x", maxUtfStringLength))
+	lengths.PutText("longText", strings.Repeat("y", maxUtfStringLength+1))
+	lengths.PutIntArray("emptyInts", []int32{})
+	lengths.PutUtfString(strings.Repeat("k", 255), "longest key")
+
+	deep := NewSFSObject()
+	for i := 0; i < maxDecodeDepth-1; i++ {
+		parent := NewSFSObject()
+		parent.PutSFSObject("d", deep)
+		deep = parent
+	}
+
+	return []*SFSObject{NewSFSObject(), all, lengths, deep}
+}
+
+// addBinarySeeds adds the fuzz seed objects and the golden corpus to f.
+func addBinarySeeds(f *testing.F) {
+	for _, sfsobject := range fuzzSeedObjects() {
+		f.Add(sfsobject.ToBinary())
+	}
+	for _, data := range readGoldenCorpus(f) {
+		f.Add(data)
+	}
+}
+
+// FuzzBinaryDecode checks that the decoders don't panic on any input and that
+// whatever they accept encodes to a payload that decodes to the same encoding
+// again.
+func FuzzBinaryDecode(f *testing.F) {
+	addBinarySeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if sfsarray, err := NewSFSArrayFromBinaryData(data); err == nil {
+			sfsarray.ToBinary()
+			sfsarray.GetHexDump()
+		}
+		if lazy, err := NewLazySFSObject(data); err == nil && lazy.Index() == nil {
+			for _, key := range lazy.GetKeys() {
+				lazy.Get(key)
+			}
+			lazy.ToBinary()
+		}
+
+		sfsobject, err := NewSFSObjectFromBinaryData(data)
+		if err != nil {
+			return
+		}
+		encoded := sfsobject.ToBinary()
+		decoded, err := NewSFSObjectFromBinaryData(encoded)
+		if err != nil {
+			t.Fatalf("can't decode re-encoded payload: %v", err)
+		}
+		if reencoded := decoded.ToBinary(); !bytes.Equal(reencoded, encoded) {
+			t.Fatalf("re-encoding changed the payload:\n%x\n%x", encoded, reencoded)
+		}
+		sfsobject.GetHexDump()
+	})
+}
+
+// FuzzJsonDecode checks that the JSON importer doesn't panic and that the
+// JSON of whatever it accepts can be imported again.
+func FuzzJsonDecode(f *testing.F) {
+	for _, sfsobject := range fuzzSeedObjects() {
+		f.Add(sfsobject.ToJson())
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, options := range []JsonOptions{{}, {IntegerNumbers: true, TypedArrays: true, AutoText: true}} {
+			sfsobject, err := NewSFSObjectFromJsonDataWithOptions(input, options)
+			if err != nil {
+				continue
+			}
+			if _, err := NewSFSObjectFromJsonDataWithOptions(sfsobject.ToJson(), options); err != nil {
+				t.Fatalf("can't import exported JSON %s: %v", sfsobject.ToJson(), err)
+			}
+		}
+	})
+}
+
+// FuzzRoundTrip checks that every decoded payload survives the lossless
+// formats unchanged.
+func FuzzRoundTrip(f *testing.F) {
+	addBinarySeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sfsobject, err := NewSFSObjectFromBinaryData(data)
+		if err != nil {
+			return
+		}
+		encoded := sfsobject.ToBinary()
+		formats := []struct {
+			name   string
+			decode func() (*SFSObject, error)
+		}{
+			{"binary", func() (*SFSObject, error) { return NewSFSObjectFromBinaryData(encoded) }},
+			{"lazy", func() (*SFSObject, error) {
+				lazy, err := NewLazySFSObject(encoded)
+				if err != nil {
+					return nil, err
+				}
+				return lazy.ToSFSObject()
+			}},
+			{"CBOR", func() (*SFSObject, error) { return NewSFSObjectFromCBOR(sfsobject.ToCBOR()) }},
+			{"MessagePack", func() (*SFSObject, error) { return NewSFSObjectFromMsgPackData(sfsobject.ToMsgPack()) }},
+		}
+		// Typed JSON can't hold NaN and infinite floats, and replaces invalid
+		// UTF-8.
+		if typedJson, err := sfsobject.ToTypedJson(); err == nil && validUtf8(sfsobject) {
+			formats = append(formats, struct {
+				name   string
+				decode func() (*SFSObject, error)
+			}{"typed JSON", func() (*SFSObject, error) { return NewSFSObjectFromTypedJsonData(typedJson) }})
+		}
+		for _, format := range formats {
+			decoded, err := format.decode()
+			if err != nil {
+				t.Fatalf("%s: %v", format.name, err)
+			}
+			if got := decoded.ToBinary(); !bytes.Equal(got, encoded) {
+				t.Fatalf("%s changed the payload:\n%x\n%x", format.name, encoded, got)
+			}
+		}
+	})
+}
+
+// validUtf8 reports whether all keys and strings in sfsobject are valid
+// UTF-8.
+func validUtf8(sfsobject *SFSObject) bool {
+	valid := true
+	sfsobject.Walk(func(path string, value Value) error {
+		switch data := value.Interface().(type) {
+		case string:
+			valid = valid && utf8.ValidString(data)
+		case []string:
+			valid = valid && !slices.ContainsFunc(data, func(element string) bool { return !utf8.ValidString(element) })
+		}
+		valid = valid && utf8.ValidString(path)
+		return nil
+	})
+	return valid
+}
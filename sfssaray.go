@@ -1,11 +1,13 @@
 package sfstypes
 
 import (
-	"fmt"
+	"reflect"
 )
 
 type SFSArray struct {
-	dataHolder []sfsDataWrapper
+	dataHolder    []sfsDataWrapper
+	numericPolicy NumericPolicy
+	sqlFormat     SQLFormat
 }
 
 func NewSFSArray() *SFSArray {
@@ -22,6 +24,10 @@ func NewSFSArrayFromJsonData(jsonStr string) (*SFSArray, error) {
 	return sfsArrayFromJson(jsonStr)
 }
 
+func NewSFSArrayFromJsonDataWithOptions(jsonStr string, options JsonOptions) (*SFSArray, error) {
+	return sfsArrayFromJsonWithOptions(jsonStr, options)
+}
+
 /*
 // TODO
 func NewSFSArrayFromResultSet() (*SFSArray, error) {
@@ -29,22 +35,16 @@ func NewSFSArrayFromResultSet() (*SFSArray, error) {
 }
 */
 
-func (sfsarray *SFSArray) GetHexDump() string {
-	bytes := sfsarray.ToBinary()
-	hexString := fmt.Sprintf("binary size: %d\n", len(bytes))
-	for i, b := range bytes {
-		if i%16 == 0 && i != 0 {
-			hexString += "\n"
-		}
-		hexString += fmt.Sprintf("%02x ", b)
-	}
-	return hexString
-}
-
 func (sfsarray *SFSArray) ToBinary() []byte {
 	return encodeSFSArray(sfsarray)
 }
 
+// AppendBinary appends the binary encoding of sfsarray to dst and returns
+// the extended slice.
+func (sfsarray *SFSArray) AppendBinary(dst []byte) []byte {
+	return appendSFSArray(dst, sfsarray)
+}
+
 func (sfsarray *SFSArray) ToJson() string {
 	return sfsArrayToJson(sfsarray)
 }
@@ -54,15 +54,14 @@ func (sfsarray *SFSArray) IsNull(index int) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if test == nil {
-		return true, nil
-	}
-	return false, nil
+	return test.typeId == TypeNull, nil
 }
 
+// Contains reports whether an element equals value, as returned by Get.
+// Slices and nested containers are compared by content.
 func (sfsarray *SFSArray) Contains(value interface{}) bool {
-	for _, v := range sfsarray.dataHolder {
-		if v == value {
+	for i := range sfsarray.dataHolder {
+		if reflect.DeepEqual(sfsarray.dataHolder[i].interfaceValue(), value) {
 			return true
 		}
 	}
@@ -71,88 +70,122 @@ func (sfsarray *SFSArray) Contains(value interface{}) bool {
 
 func (sfsarray *SFSArray) GetElementAt(index int) (interface{}, error) {
 	if index >= len(sfsarray.dataHolder) || index < 0 {
-		return nil, &ErrIndexNotInRange{index: index}
+		return nil, &ErrIndexNotInRange{Index: index}
 	}
 	return sfsarray.dataHolder[index], nil
 }
 
 func (sfsarray *SFSArray) RemoveElementAt(index int) error {
 	if index >= len(sfsarray.dataHolder) || index < 0 {
-		return &ErrIndexNotInRange{index: index}
+		return &ErrIndexNotInRange{Index: index}
 	}
 	sfsarray.dataHolder = append(sfsarray.dataHolder[:index], sfsarray.dataHolder[index+1:]...)
 	return nil
 }
 
+// Reset removes all elements while keeping the allocated storage for reuse.
+func (sfsarray *SFSArray) Reset() {
+	clear(sfsarray.dataHolder)
+	sfsarray.dataHolder = sfsarray.dataHolder[:0]
+}
+
 func (sfsarray *SFSArray) Size() int {
 	return len(sfsarray.dataHolder)
 }
 
+func (sfsarray *SFSArray) TypeOf(index int) (DataType, error) {
+	value, err := sfsarray.getWrapper(index)
+	if err != nil {
+		return TypeNull, err
+	}
+	return value.typeId, nil
+}
+
+func (sfsarray *SFSArray) deepCopy() *SFSArray {
+	result := &SFSArray{
+		dataHolder:    make([]sfsDataWrapper, len(sfsarray.dataHolder)),
+		numericPolicy: sfsarray.numericPolicy,
+		sqlFormat:     sfsarray.sqlFormat,
+	}
+	for i := range sfsarray.dataHolder {
+		result.dataHolder[i] = sfsarray.dataHolder[i].deepCopy()
+	}
+	return result
+}
+
 func (sfsarray *SFSArray) getWrapper(index int) (*sfsDataWrapper, error) {
-	if index >= 0 && index <= sfsarray.Size() {
+	if index >= 0 && index < sfsarray.Size() {
 		return &sfsarray.dataHolder[index], nil
 	}
-	return nil, &ErrIndexNotInRange{index: index}
+	return nil, &ErrIndexNotInRange{Index: index}
 }
 
 func (sfsarray *SFSArray) Get(index int) (interface{}, error) {
-	if index < 0 && index >= len(sfsarray.dataHolder) {
-		return nil, &ErrIndexNotInRange{index: index}
+	value, err := sfsarray.getWrapper(index)
+	if err != nil {
+		return nil, err
 	}
-	value, _ := sfsarray.getWrapper(index)
 	switch value.typeId {
-	case type_NULL:
+	case TypeNull:
 		return nil, nil
-	case type_BOOL:
+	case TypeBool:
 		return value.data.(bool), nil
-	case type_BYTE:
+	case TypeByte:
 		return value.data.(int8), nil
-	case type_SHORT:
+	case TypeShort:
 		return value.data.(int16), nil
-	case type_INT:
+	case TypeInt:
 		return value.data.(int32), nil
-	case type_LONG:
+	case TypeLong:
 		return value.data.(int64), nil
-	case type_FLOAT:
+	case TypeFloat:
 		return value.data.(float32), nil
-	case type_DOUBLE:
+	case TypeDouble:
 		return value.data.(float64), nil
-	case type_UTF_STRING:
+	case TypeUtfString:
 		return value.data.(string), nil
-	case type_BOOL_ARRAY:
+	case TypeBoolArray:
 		return value.data.([]bool), nil
-	case type_BYTE_ARRAY:
-		return value.data.([]int8), nil
-	case type_SHORT_ARRAY:
+	case TypeByteArray:
+		return BytesToInt8s(value.data.([]byte)), nil
+	case TypeShortArray:
 		return value.data.([]int16), nil
-	case type_INT_ARRAY:
+	case TypeIntArray:
 		return value.data.([]int32), nil
-	case type_LONG_ARRAY:
+	case TypeLongArray:
 		return value.data.([]int64), nil
-	case type_FLOAT_ARRAY:
+	case TypeFloatArray:
 		return value.data.([]float32), nil
-	case type_DOUBLE_ARRAY:
+	case TypeDoubleArray:
 		return value.data.([]float64), nil
-	case type_UTF_STRING_ARRAY:
+	case TypeUtfStringArray:
 		return value.data.([]string), nil
-	case type_SFS_ARRAY:
-		return value.data.(SFSArray), nil
-	case type_SFS_OBJECT:
-		return value.data.(SFSObject), nil
-	case type_TEXT:
+	case TypeSFSArray:
+		return value.data.(*SFSArray), nil
+	case TypeSFSObject:
+		return value.data.(*SFSObject), nil
+	case TypeText:
 		return value.data.(string), nil
 	default:
-		return nil, &ErrUnsupportedType{value: value}
+		return nil, &ErrUnsupportedType{Value: value}
 	}
 }
 
+func (sfsarray *SFSArray) GetValue(index int) (Value, error) {
+	value, err := sfsarray.getWrapper(index)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{wrapper: *value}, nil
+}
+
 func (sfsarray *SFSArray) GetBool(index int) (bool, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_BOOL {
+		if value.typeId == TypeBool {
 			return value.data.(bool), nil
 		}
-		return false, &ErrWrongType{actualType: value.typeId, wantedType: type_BOOL}
+		return false, &ErrWrongType{ActualType: value.typeId, WantedType: TypeBool}
 	}
 	return false, err
 }
@@ -160,10 +193,10 @@ func (sfsarray *SFSArray) GetBool(index int) (bool, error) {
 func (sfsarray *SFSArray) GetBoolArray(index int) ([]bool, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_BOOL_ARRAY {
+		if value.typeId == TypeBoolArray {
 			return value.data.([]bool), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_BOOL_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeBoolArray}
 	}
 	return nil, err
 }
@@ -171,10 +204,10 @@ func (sfsarray *SFSArray) GetBoolArray(index int) ([]bool, error) {
 func (sfsarray *SFSArray) GetByte(index int) (int8, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_BYTE {
+		if value.typeId == TypeByte {
 			return value.data.(int8), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByte}
 	}
 	return 0, err
 }
@@ -182,10 +215,10 @@ func (sfsarray *SFSArray) GetByte(index int) (int8, error) {
 func (sfsarray *SFSArray) GetByteArray(index int) ([]int8, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_BYTE_ARRAY {
-			return value.data.([]int8), nil
+		if value.typeId == TypeByteArray {
+			return BytesToInt8s(value.data.([]byte)), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByteArray}
 	}
 	return nil, err
 }
@@ -193,10 +226,10 @@ func (sfsarray *SFSArray) GetByteArray(index int) ([]int8, error) {
 func (sfsarray *SFSArray) GetDouble(index int) (float64, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_DOUBLE {
+		if value.typeId == TypeDouble {
 			return value.data.(float64), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_DOUBLE}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeDouble}
 	}
 	return 0, err
 }
@@ -204,10 +237,10 @@ func (sfsarray *SFSArray) GetDouble(index int) (float64, error) {
 func (sfsarray *SFSArray) GetDoubleArray(index int) ([]float64, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_DOUBLE_ARRAY {
+		if value.typeId == TypeDoubleArray {
 			return value.data.([]float64), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_DOUBLE_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeDoubleArray}
 	}
 	return nil, err
 }
@@ -215,10 +248,10 @@ func (sfsarray *SFSArray) GetDoubleArray(index int) ([]float64, error) {
 func (sfsarray *SFSArray) GetFloat(index int) (float32, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_FLOAT {
+		if value.typeId == TypeFloat {
 			return value.data.(float32), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_FLOAT}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeFloat}
 	}
 	return 0, err
 }
@@ -226,10 +259,10 @@ func (sfsarray *SFSArray) GetFloat(index int) (float32, error) {
 func (sfsarray *SFSArray) GetFloatArray(index int) ([]float32, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_FLOAT_ARRAY {
+		if value.typeId == TypeFloatArray {
 			return value.data.([]float32), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_FLOAT_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeFloatArray}
 	}
 	return nil, err
 }
@@ -237,10 +270,10 @@ func (sfsarray *SFSArray) GetFloatArray(index int) ([]float32, error) {
 func (sfsarray *SFSArray) GetInt(index int) (int32, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_INT {
+		if value.typeId == TypeInt {
 			return value.data.(int32), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_INT}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeInt}
 	}
 	return 0, err
 }
@@ -248,10 +281,10 @@ func (sfsarray *SFSArray) GetInt(index int) (int32, error) {
 func (sfsarray *SFSArray) GetIntArray(index int) ([]int32, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_INT_ARRAY {
+		if value.typeId == TypeIntArray {
 			return value.data.([]int32), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_INT_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeIntArray}
 	}
 	return nil, err
 }
@@ -259,10 +292,10 @@ func (sfsarray *SFSArray) GetIntArray(index int) ([]int32, error) {
 func (sfsarray *SFSArray) GetLong(index int) (int64, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_LONG {
+		if value.typeId == TypeLong {
 			return value.data.(int64), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_LONG}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeLong}
 	}
 	return 0, err
 }
@@ -270,43 +303,67 @@ func (sfsarray *SFSArray) GetLong(index int) (int64, error) {
 func (sfsarray *SFSArray) GetLongArray(index int) ([]int64, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_LONG_ARRAY {
+		if value.typeId == TypeLongArray {
 			return value.data.([]int64), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_LONG_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeLongArray}
 	}
 	return nil, err
 }
 
-func (sfsarray *SFSArray) GetSFSObject(index int) (SFSObject, error) {
+// GetSFSObject returns the nested SFSObject stored at index. The returned
+// pointer aliases the stored object, so changes made through it are visible
+// in sfsarray. Use GetSFSObjectCopy for a detached copy.
+func (sfsarray *SFSArray) GetSFSObject(index int) (*SFSObject, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_SFS_OBJECT {
-			return value.data.(SFSObject), nil
+		if value.typeId == TypeSFSObject {
+			return value.data.(*SFSObject), nil
 		}
-		return *NewSFSObject(), &ErrWrongType{actualType: value.typeId, wantedType: type_SFS_OBJECT}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeSFSObject}
 	}
-	return *NewSFSObject(), err
+	return nil, err
 }
 
-func (sfsarray *SFSArray) GetSFSArray(index int) (SFSArray, error) {
+// GetSFSObjectCopy returns a deep copy of the nested SFSObject stored at index.
+func (sfsarray *SFSArray) GetSFSObjectCopy(index int) (*SFSObject, error) {
+	value, err := sfsarray.GetSFSObject(index)
+	if err != nil {
+		return nil, err
+	}
+	return value.deepCopy(), nil
+}
+
+// GetSFSArray returns the nested SFSArray stored at index. The returned
+// pointer aliases the stored array, so changes made through it are visible
+// in sfsarray. Use GetSFSArrayCopy for a detached copy.
+func (sfsarray *SFSArray) GetSFSArray(index int) (*SFSArray, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_SFS_ARRAY {
-			return *value.data.(*SFSArray), nil
+		if value.typeId == TypeSFSArray {
+			return value.data.(*SFSArray), nil
 		}
-		return *NewSFSArray(), &ErrWrongType{actualType: value.typeId, wantedType: type_SFS_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeSFSArray}
+	}
+	return nil, err
+}
+
+// GetSFSArrayCopy returns a deep copy of the nested SFSArray stored at index.
+func (sfsarray *SFSArray) GetSFSArrayCopy(index int) (*SFSArray, error) {
+	value, err := sfsarray.GetSFSArray(index)
+	if err != nil {
+		return nil, err
 	}
-	return *NewSFSArray(), err
+	return value.deepCopy(), nil
 }
 
 func (sfsarray *SFSArray) GetShort(index int) (int16, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_SHORT {
+		if value.typeId == TypeShort {
 			return value.data.(int16), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_SHORT}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeShort}
 	}
 	return 0, err
 }
@@ -314,10 +371,10 @@ func (sfsarray *SFSArray) GetShort(index int) (int16, error) {
 func (sfsarray *SFSArray) GetShortArray(index int) ([]int16, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_SHORT_ARRAY {
+		if value.typeId == TypeShortArray {
 			return value.data.([]int16), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_SHORT_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeShortArray}
 	}
 	return nil, err
 }
@@ -325,10 +382,10 @@ func (sfsarray *SFSArray) GetShortArray(index int) ([]int16, error) {
 func (sfsarray *SFSArray) GetUnsignedByte(index int) (uint8, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_BYTE {
-			return value.data.(uint8), nil
+		if value.typeId == TypeByte {
+			return uint8(value.data.(int8)), nil
 		}
-		return 0, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE}
+		return 0, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByte}
 	}
 	return 0, err
 }
@@ -336,10 +393,10 @@ func (sfsarray *SFSArray) GetUnsignedByte(index int) (uint8, error) {
 func (sfsarray *SFSArray) GetUnsignedByteArray(index int) ([]uint8, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_BYTE_ARRAY {
-			return value.data.([]uint8), nil
+		if value.typeId == TypeByteArray {
+			return value.data.([]byte), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_BYTE_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeByteArray}
 	}
 	return nil, err
 }
@@ -347,10 +404,10 @@ func (sfsarray *SFSArray) GetUnsignedByteArray(index int) ([]uint8, error) {
 func (sfsarray *SFSArray) GetUtfString(index int) (string, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_UTF_STRING {
+		if value.typeId == TypeUtfString {
 			return value.data.(string), nil
 		}
-		return "", &ErrWrongType{actualType: value.typeId, wantedType: type_UTF_STRING}
+		return "", &ErrWrongType{ActualType: value.typeId, WantedType: TypeUtfString}
 	}
 	return "", err
 }
@@ -358,10 +415,10 @@ func (sfsarray *SFSArray) GetUtfString(index int) (string, error) {
 func (sfsarray *SFSArray) GetText(index int) (string, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_TEXT {
+		if value.typeId == TypeText {
 			return value.data.(string), nil
 		}
-		return "", &ErrWrongType{actualType: value.typeId, wantedType: type_TEXT}
+		return "", &ErrWrongType{ActualType: value.typeId, WantedType: TypeText}
 	}
 	return "", err
 }
@@ -369,15 +426,15 @@ func (sfsarray *SFSArray) GetText(index int) (string, error) {
 func (sfsarray *SFSArray) GetUtfStringArray(index int) ([]string, error) {
 	value, err := sfsarray.getWrapper(index)
 	if err == nil {
-		if value.typeId == type_UTF_STRING_ARRAY {
+		if value.typeId == TypeUtfStringArray {
 			return value.data.([]string), nil
 		}
-		return nil, &ErrWrongType{actualType: value.typeId, wantedType: type_UTF_STRING_ARRAY}
+		return nil, &ErrWrongType{ActualType: value.typeId, WantedType: TypeUtfStringArray}
 	}
 	return nil, err
 }
 
-func (sfsarray *SFSArray) addData(value interface{}, typeId sfsDataType) {
+func (sfsarray *SFSArray) addData(value interface{}, typeId DataType) {
 	sfsarray.addsfsDataWrapper(*newsfsDataWrapper(typeId, value))
 }
 
@@ -385,6 +442,8 @@ func (sfsarray *SFSArray) addsfsDataWrapper(value sfsDataWrapper) {
 	sfsarray.dataHolder = append(sfsarray.dataHolder, value)
 }
 
+// Add appends value like SFSObject.Put stores it; SFSObjects and SFSArrays
+// passed by value return an ErrUnsupportedType.
 func (sfsarray *SFSArray) Add(value interface{}) error {
 	switch v := value.(type) {
 	case bool:
@@ -399,6 +458,12 @@ func (sfsarray *SFSArray) Add(value interface{}) error {
 	case []int8:
 		sfsarray.AddByteArray(v)
 		return nil
+	case uint8:
+		sfsarray.AddUnsignedByte(v)
+		return nil
+	case []byte:
+		sfsarray.AddUnsignedByteArray(v)
+		return nil
 	case float32:
 		sfsarray.AddFloat(v)
 		return nil
@@ -411,14 +476,9 @@ func (sfsarray *SFSArray) Add(value interface{}) error {
 	case []float64:
 		sfsarray.AddDoubleArray(v)
 		return nil
-	case int:
-		sfsarray.AddInt(int32(v))
-		return nil
 	case int32:
 		sfsarray.AddInt(v)
 		return nil
-	case []int:
-		// TODO
 	case []int32:
 		sfsarray.AddIntArray(v)
 		return nil
@@ -434,15 +494,12 @@ func (sfsarray *SFSArray) Add(value interface{}) error {
 	case *SFSArray:
 		sfsarray.AddSFSArray(v)
 		return nil
-	case SFSArray:
-		sfsarray.AddSFSArray(&v)
-		return nil
 	case *SFSObject:
 		sfsarray.AddSFSObject(v)
 		return nil
-	case SFSObject:
-		sfsarray.AddSFSObject(&v)
-		return nil
+	case SFSArray, SFSObject:
+		// A copy would share its storage with the original only partly.
+		return &ErrUnsupportedType{Value: value}
 	case int16:
 		sfsarray.AddShort(v)
 		return nil
@@ -456,85 +513,118 @@ func (sfsarray *SFSArray) Add(value interface{}) error {
 		sfsarray.AddUtfStringArray(v)
 		return nil
 	}
-	return &ErrUnsupportedType{value: value}
+	wrapper, err := convertValue(value, sfsarray.numericPolicy)
+	if err != nil {
+		return err
+	}
+	sfsarray.addsfsDataWrapper(wrapper)
+	return nil
+}
+
+func (sfsarray *SFSArray) AddValue(value Value) {
+	sfsarray.addsfsDataWrapper(value.wrapper)
 }
 
 func (sfsarray *SFSArray) AddBool(value bool) {
-	sfsarray.addData(value, type_BOOL)
+	sfsarray.addData(value, TypeBool)
 }
 
 func (sfsarray *SFSArray) AddBoolArray(value []bool) {
-	sfsarray.addData(value, type_BOOL_ARRAY)
+	sfsarray.addData(value, TypeBoolArray)
 }
 
 func (sfsarray *SFSArray) AddByte(value int8) {
-	sfsarray.addData(value, type_BYTE)
+	sfsarray.addData(value, TypeByte)
 }
 
+// AddByteArray adds value as a BYTE_ARRAY. The array shares memory with
+// value.
 func (sfsarray *SFSArray) AddByteArray(value []int8) {
-	sfsarray.addData(value, type_BYTE_ARRAY)
+	sfsarray.addData(Int8sToBytes(value), TypeByteArray)
+}
+
+func (sfsarray *SFSArray) AddUnsignedByte(value uint8) {
+	sfsarray.addData(int8(value), TypeByte)
+}
+
+// AddUnsignedByteArray adds value as a BYTE_ARRAY. The array shares memory
+// with value.
+func (sfsarray *SFSArray) AddUnsignedByteArray(value []byte) {
+	sfsarray.addData(value, TypeByteArray)
 }
 
 func (sfsarray *SFSArray) AddDouble(value float64) {
-	sfsarray.addData(value, type_DOUBLE)
+	sfsarray.addData(value, TypeDouble)
 }
 
 func (sfsarray *SFSArray) AddDoubleArray(value []float64) {
-	sfsarray.addData(value, type_DOUBLE_ARRAY)
+	sfsarray.addData(value, TypeDoubleArray)
 }
 
 func (sfsarray *SFSArray) AddFloat(value float32) {
-	sfsarray.addData(value, type_FLOAT)
+	sfsarray.addData(value, TypeFloat)
 }
 
 func (sfsarray *SFSArray) AddFloatArray(value []float32) {
-	sfsarray.addData(value, type_FLOAT_ARRAY)
+	sfsarray.addData(value, TypeFloatArray)
 }
 
 func (sfsarray *SFSArray) AddInt(value int32) {
-	sfsarray.addData(value, type_INT)
+	sfsarray.addData(value, TypeInt)
 }
 
 func (sfsarray *SFSArray) AddIntArray(value []int32) {
-	sfsarray.addData(value, type_INT_ARRAY)
+	sfsarray.addData(value, TypeIntArray)
 }
 
 func (sfsarray *SFSArray) AddLong(value int64) {
-	sfsarray.addData(value, type_LONG)
+	sfsarray.addData(value, TypeLong)
 }
 
 func (sfsarray *SFSArray) AddLongArray(value []int64) {
-	sfsarray.addData(value, type_LONG_ARRAY)
+	sfsarray.addData(value, TypeLongArray)
 }
 
 func (sfsarray *SFSArray) AddNull() {
-	sfsarray.addData(nil, type_NULL)
+	sfsarray.addData(nil, TypeNull)
 }
 
+// AddSFSArray stores value by reference: later changes to value are visible
+// through sfsarray and vice versa. A nil value is added as NULL.
 func (sfsarray *SFSArray) AddSFSArray(value *SFSArray) {
-	sfsarray.addData(*value, type_SFS_ARRAY)
+	if value == nil {
+		sfsarray.AddNull()
+		return
+	}
+	sfsarray.addData(value, TypeSFSArray)
 }
 
+// AddSFSObject stores value by reference: later changes to value are visible
+// through sfsarray and vice versa. A nil value is added as NULL.
 func (sfsarray *SFSArray) AddSFSObject(value *SFSObject) {
-	sfsarray.addData(*value, type_SFS_OBJECT)
+	if value == nil {
+		sfsarray.AddNull()
+		return
+	}
+	sfsarray.addData(value, TypeSFSObject)
 }
 
 func (sfsarray *SFSArray) AddShort(value int16) {
-	sfsarray.addData(value, type_SHORT)
+	sfsarray.addData(value, TypeShort)
 }
 
 func (sfsarray *SFSArray) AddShortArray(value []int16) {
-	sfsarray.addData(value, type_SHORT_ARRAY)
+	sfsarray.addData(value, TypeShortArray)
 }
 
 func (sfsarray *SFSArray) AddUtfString(value string) {
-	sfsarray.addData(value, type_UTF_STRING)
+	sfsarray.addData(value, TypeUtfString)
 }
 
 func (sfsarray *SFSArray) AddText(value string) {
-	sfsarray.addData(value, type_TEXT)
+	sfsarray.addData(value, TypeText)
 }
 
 func (sfsarray *SFSArray) AddUtfStringArray(value []string) {
-	sfsarray.addData(value, type_UTF_STRING_ARRAY)
+	sfsarray.addData(value, TypeUtfStringArray)
 }
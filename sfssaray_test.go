@@ -0,0 +1,134 @@
+package sfstypes
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestNestedContainersAreShared(t *testing.T) {
+	sfsobject := NewSFSObject()
+	nested := NewSFSArray()
+	if err := sfsobject.PutSFSArray("nested", nested); err != nil {
+		t.Fatal(err)
+	}
+	got, err := sfsobject.GetSFSArray("nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.AddInt(1)
+	if nested.Size() != 1 {
+		t.Fatal("change to the returned array isn't visible in the stored one")
+	}
+
+	detached, err := sfsobject.GetSFSArrayCopy("nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	detached.AddInt(2)
+	if nested.Size() != 1 {
+		t.Fatal("change to the copy is visible in the stored array")
+	}
+}
+
+func TestNilAndByValueContainers(t *testing.T) {
+	sfsobject := NewSFSObject()
+	if err := sfsobject.PutSFSObject("object", nil); !errors.Is(err, ErrDataNull) {
+		t.Fatalf("PutSFSObject(nil): got %v, want ErrDataNull", err)
+	}
+	if err := sfsobject.PutSFSArray("array", nil); !errors.Is(err, ErrDataNull) {
+		t.Fatalf("PutSFSArray(nil): got %v, want ErrDataNull", err)
+	}
+	var unsupported *ErrUnsupportedType
+	if err := sfsobject.Put("object", *NewSFSObject()); !errors.As(err, &unsupported) {
+		t.Fatalf("Put of an SFSObject value: got %v, want ErrUnsupportedType", err)
+	}
+	if err := NewSFSArray().Add(*NewSFSArray()); !errors.As(err, &unsupported) {
+		t.Fatalf("Add of an SFSArray value: got %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestSFSArrayIsNull(t *testing.T) {
+	sfsarray := NewSFSArray()
+	sfsarray.AddNull()
+	sfsarray.AddInt(1)
+	sfsarray.AddSFSObject(nil)
+	for index, want := range []bool{true, false, true} {
+		got, err := sfsarray.IsNull(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("IsNull(%d) = %v, want %v", index, got, want)
+		}
+	}
+}
+
+func TestSFSArrayIndexRange(t *testing.T) {
+	sfsarray := NewSFSArray()
+	sfsarray.AddInt(1)
+	for _, index := range []int{-1, 1} {
+		var notInRange *ErrIndexNotInRange
+		if _, err := sfsarray.Get(index); !errors.As(err, &notInRange) {
+			t.Errorf("Get(%d): got %v, want ErrIndexNotInRange", index, err)
+		}
+		if _, err := sfsarray.GetInt(index); !errors.As(err, &notInRange) {
+			t.Errorf("GetInt(%d): got %v, want ErrIndexNotInRange", index, err)
+		}
+	}
+}
+
+func TestSFSArrayContains(t *testing.T) {
+	nested := NewSFSObject()
+	nested.PutInt("a", 1)
+	sfsarray := NewSFSArray()
+	sfsarray.AddInt(1)
+	sfsarray.AddIntArray([]int32{2, 3})
+	sfsarray.AddSFSObject(nested)
+	equal := NewSFSObject()
+	equal.PutInt("a", 1)
+	for _, value := range []interface{}{int32(1), []int32{2, 3}, equal} {
+		if !sfsarray.Contains(value) {
+			t.Errorf("Contains(%v) = false", value)
+		}
+	}
+	for _, value := range []interface{}{1, int64(1), []int32{2}, NewSFSObject()} {
+		if sfsarray.Contains(value) {
+			t.Errorf("Contains(%#v) = true", value)
+		}
+	}
+}
+
+func TestDecodeRejectsDeepNesting(t *testing.T) {
+	for _, depth := range []int{maxDecodeDepth, maxDecodeDepth + 1} {
+		var data []byte
+		for i := 0; i < depth-1; i++ {
+			data = append(data, byte(TypeSFSArray), 0x00, 0x01)
+		}
+		data = append(data, byte(TypeSFSArray), 0x00, 0x00)
+
+		_, err := NewSFSArrayFromBinaryData(data)
+		var tooDeep *ErrMaxDepthExceeded
+		if depth > maxDecodeDepth && !errors.As(err, &tooDeep) {
+			t.Errorf("depth %d: got %v, want ErrMaxDepthExceeded", depth, err)
+		} else if depth <= maxDecodeDepth && err != nil {
+			t.Errorf("depth %d: %v", depth, err)
+		}
+	}
+}
+
+func TestDecodeCapsCorruptSizes(t *testing.T) {
+	// Every nested array claims 65535 elements. Preallocating them would take
+	// more than a megabyte per header.
+	data := bytes.Repeat([]byte{byte(TypeSFSArray), 0xff, 0xff}, maxDecodeDepth)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := NewSFSArrayFromBinaryData(data); err == nil {
+		t.Fatal("truncated data was accepted")
+	}
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("decoding %d bytes allocated %d bytes", len(data), allocated)
+	}
+}
@@ -0,0 +1,132 @@
+package sfstypes
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// SQLFormat selects how Value stores an SFSObject or SFSArray in a database
+// column.
+type SQLFormat int
+
+const (
+	// SQLBinary stores the SFS2X binary encoding as []byte, for bytea and
+	// blob columns.
+	SQLBinary SQLFormat = iota
+	// SQLTypedJson stores typed JSON (see ToTypedJson), for json and jsonb
+	// columns that have to keep every type.
+	SQLTypedJson
+	// SQLJson stores the plain JSON of ToJson, which other applications
+	// can query but which loses numeric and array types.
+	SQLJson
+)
+
+// SetSQLFormat sets the format Value uses. Scan detects the format of the
+// column by itself.
+func (sfsobject *SFSObject) SetSQLFormat(format SQLFormat) {
+	sfsobject.sqlFormat = format
+}
+
+func (sfsarray *SFSArray) SetSQLFormat(format SQLFormat) {
+	sfsarray.sqlFormat = format
+}
+
+// Value implements driver.Valuer. The binary format is returned as []byte
+// and the JSON formats as string, which drivers pass to json columns as is.
+func (sfsobject *SFSObject) Value() (driver.Value, error) {
+	switch sfsobject.sqlFormat {
+	case SQLTypedJson:
+		return sfsobject.ToTypedJson()
+	case SQLJson:
+		data, err := sfsobject.MarshalJSON()
+		return string(data), err
+	default:
+		return sfsobject.ToBinary(), nil
+	}
+}
+
+// Scan implements sql.Scanner. It accepts binary data, typed JSON and plain
+// JSON, as []byte or string, and replaces the contents of sfsobject. A NULL
+// column leaves sfsobject empty.
+func (sfsobject *SFSObject) Scan(src interface{}) error {
+	data, err := scanData(src)
+	if err != nil || data == nil {
+		sfsobject.Reset()
+		return err
+	}
+	var decoded *SFSObject
+	switch detectSQLFormat(data, TypeSFSObject) {
+	case SQLBinary:
+		decoded, err = NewSFSObjectFromBinaryData(data)
+	case SQLTypedJson:
+		decoded, err = NewSFSObjectFromTypedJsonData(string(data))
+	default:
+		decoded, err = NewSFSObjectFromJsonData(string(data))
+	}
+	if err != nil {
+		return err
+	}
+	sfsobject.replace(decoded)
+	return nil
+}
+
+// Value implements driver.Valuer like SFSObject.Value.
+func (sfsarray *SFSArray) Value() (driver.Value, error) {
+	switch sfsarray.sqlFormat {
+	case SQLTypedJson:
+		return sfsarray.ToTypedJson()
+	case SQLJson:
+		data, err := sfsarray.MarshalJSON()
+		return string(data), err
+	default:
+		return sfsarray.ToBinary(), nil
+	}
+}
+
+// Scan implements sql.Scanner like SFSObject.Scan.
+func (sfsarray *SFSArray) Scan(src interface{}) error {
+	data, err := scanData(src)
+	if err != nil || data == nil {
+		sfsarray.Reset()
+		return err
+	}
+	var decoded *SFSArray
+	switch detectSQLFormat(data, TypeSFSArray) {
+	case SQLBinary:
+		decoded, err = NewSFSArrayFromBinaryData(data)
+	case SQLTypedJson:
+		decoded, err = NewSFSArrayFromTypedJsonData(string(data))
+	default:
+		decoded, err = NewSFSArrayFromJsonData(string(data))
+	}
+	if err != nil {
+		return err
+	}
+	sfsarray.dataHolder = decoded.dataHolder
+	return nil
+}
+
+func scanData(src interface{}) ([]byte, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return src, nil
+	case string:
+		return []byte(src), nil
+	default:
+		return nil, fmt.Errorf("can't scan %T into an SFS container", src)
+	}
+}
+
+// detectSQLFormat recognizes binary data by its leading type id and typed JSON
+// by a "t" naming the container type; anything else is taken as plain JSON.
+func detectSQLFormat(data []byte, typeId DataType) SQLFormat {
+	if len(data) > 0 && DataType(data[0]) == typeId {
+		return SQLBinary
+	}
+	if isTypedJson(data, typeId) {
+		return SQLTypedJson
+	}
+	return SQLJson
+}
@@ -0,0 +1,129 @@
+package sfstypes
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSQLValueAndScan(t *testing.T) {
+	sfsobject := marshalTestObject()
+	want := sfsobject.ToBinary()
+	tests := []struct {
+		format   SQLFormat
+		wantType string
+		// lossy formats don't keep the numeric and array types.
+		lossy bool
+	}{
+		{SQLBinary, "[]uint8", false},
+		{SQLTypedJson, "string", false},
+		{SQLJson, "string", true},
+	}
+	for _, test := range tests {
+		sfsobject.SetSQLFormat(test.format)
+		value, err := sfsobject.Value()
+		if err != nil {
+			t.Fatalf("format %d: %v", test.format, err)
+		}
+		var sources []interface{}
+		switch value := value.(type) {
+		case []byte:
+			sources = []interface{}{value, string(value)}
+		case string:
+			sources = []interface{}{value, []byte(value)}
+		default:
+			t.Fatalf("format %d: Value returned %T", test.format, value)
+		}
+		for _, src := range sources {
+			scanned := NewSFSObject()
+			if err := scanned.Scan(src); err != nil {
+				t.Fatalf("format %d, %T: %v", test.format, src, err)
+			}
+			if test.lossy {
+				if id, err := scanned.GetDouble("id"); err != nil || id != 1<<40 {
+					t.Errorf("format %d, %T: id = %v, %v", test.format, src, id, err)
+				}
+			} else if got := scanned.ToBinary(); !bytes.Equal(got, want) {
+				t.Errorf("format %d, %T: got %x, want %x", test.format, src, got, want)
+			}
+		}
+	}
+}
+
+func TestSQLScanArray(t *testing.T) {
+	sfsarray := NewSFSArray()
+	sfsarray.AddInt(1)
+	sfsarray.AddUtfString("a")
+	for _, format := range []SQLFormat{SQLBinary, SQLTypedJson} {
+		sfsarray.SetSQLFormat(format)
+		value, err := sfsarray.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanned := NewSFSArray()
+		if err := scanned.Scan(value); err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if !bytes.Equal(scanned.ToBinary(), sfsarray.ToBinary()) {
+			t.Errorf("format %d: got %x, want %x", format, scanned.ToBinary(), sfsarray.ToBinary())
+		}
+	}
+	scanned := NewSFSArray()
+	if err := scanned.Scan(`[1, "a"]`); err != nil || scanned.Size() != 2 {
+		t.Errorf("plain JSON: size %d, %v", scanned.Size(), err)
+	}
+}
+
+func TestSQLScanDetectsFormat(t *testing.T) {
+	tests := []struct {
+		src  string
+		want SQLFormat
+	}{
+		{"\x12\x00\x00", SQLBinary},
+		{`{"t":"SFS_OBJECT","v":{}}`, SQLTypedJson},
+		// A "t" that doesn't name an SFS_OBJECT is an ordinary key.
+		{`{"t":"INT","v":1}`, SQLJson},
+		{`{"t":"SFS_OBJECT"}`, SQLJson},
+		{`{"a":1}`, SQLJson},
+		{``, SQLJson},
+	}
+	for _, test := range tests {
+		if got := detectSQLFormat([]byte(test.src), TypeSFSObject); got != test.want {
+			t.Errorf("%q: got %d, want %d", test.src, got, test.want)
+		}
+	}
+
+	sfsobject := NewSFSObject()
+	if err := sfsobject.Scan(`{"t":"INT","v":1}`); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := sfsobject.GetUtfString("t"); err != nil || s != "INT" {
+		t.Errorf("t = %q, %v", s, err)
+	}
+}
+
+func TestSQLScanNullAndErrors(t *testing.T) {
+	sfsobject := marshalTestObject()
+	sfsobject.SetNumericPolicy(NumericLong)
+	if err := sfsobject.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if sfsobject.Size() != 0 {
+		t.Errorf("NULL left %d keys", sfsobject.Size())
+	}
+	sfsobject.Put("n", 1)
+	if typeId, _ := sfsobject.TypeOf("n"); typeId != TypeLong {
+		t.Errorf("numeric policy was lost: Put stored %v", typeId)
+	}
+
+	sfsarray := NewSFSArray()
+	sfsarray.AddInt(1)
+	if err := sfsarray.Scan(nil); err != nil || sfsarray.Size() != 0 {
+		t.Errorf("NULL left %d elements, %v", sfsarray.Size(), err)
+	}
+
+	for _, src := range []interface{}{42, "\x12\x00\x01", `{"t":"SFS_OBJECT","v":{"a":{"v":1}}}`, "{"} {
+		if err := NewSFSObject().Scan(src); err == nil {
+			t.Errorf("%#v was accepted", src)
+		}
+	}
+}
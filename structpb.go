@@ -0,0 +1,346 @@
+package sfstypes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The protobuf well-known Struct, ListValue and Value types only know
+// numbers, strings, bools, null, structs and lists. SFSObjects become
+// Structs and SFSArrays ListValues; every number becomes a double, typed
+// arrays become lists and BYTE_ARRAYs base64 strings, as structpb.NewValue
+// does for []byte. LONGs that a double can't hold exactly become decimal
+// strings, as in the JSON mapping of int64 fields.
+//
+// The WithTypes variants also return a sidecar map from Walk paths (e.g.
+// "player.items[2].id") to the SFS type of every value that wouldn't convert
+// back to the same type by itself. Passing the map to NewSFSObjectFromStruct
+// or NewSFSArrayFromListValue restores the original wire types. Keys that
+// contain "." or "[" would be ambiguous in paths, so they fail to convert
+// whenever a sidecar map is used.
+
+func (sfsobject *SFSObject) ToStruct() (*structpb.Struct, error) {
+	return structpb.NewStruct(structpbMap(convertSFSObjectToMap(sfsobject)))
+}
+
+func (sfsobject *SFSObject) ToStructWithTypes() (*structpb.Struct, map[string]DataType, error) {
+	if err := checkStructpbKeys(sfsDataWrapper{typeId: TypeSFSObject, data: sfsobject}); err != nil {
+		return nil, nil, err
+	}
+	result, err := sfsobject.ToStruct()
+	if err != nil {
+		return nil, nil, err
+	}
+	types := make(map[string]DataType)
+	sfsobject.Walk(recordStructpbTypes(types))
+	return result, types, nil
+}
+
+func (sfsarray *SFSArray) ToListValue() (*structpb.ListValue, error) {
+	return structpb.NewList(structpbSlice(convertSFSArrayToSlice(sfsarray)))
+}
+
+func (sfsarray *SFSArray) ToListValueWithTypes() (*structpb.ListValue, map[string]DataType, error) {
+	if err := checkStructpbKeys(sfsDataWrapper{typeId: TypeSFSArray, data: sfsarray}); err != nil {
+		return nil, nil, err
+	}
+	result, err := sfsarray.ToListValue()
+	if err != nil {
+		return nil, nil, err
+	}
+	types := make(map[string]DataType)
+	sfsarray.Walk(recordStructpbTypes(types))
+	return result, types, nil
+}
+
+// NewSFSObjectFromStruct converts a Struct into an SFSObject. Numbers become
+// DOUBLE, strings UTF_STRING (or TEXT if too long for one) and lists
+// SFSArrays unless types, which may be nil, says otherwise.
+// With a non-nil types map, keys containing "." or "[" fail to convert.
+func NewSFSObjectFromStruct(input *structpb.Struct, types map[string]DataType) (*SFSObject, error) {
+	return convertStructpbStruct("", input, types)
+}
+
+// NewSFSArrayFromListValue converts a ListValue into an SFSArray like
+// NewSFSObjectFromStruct.
+func NewSFSArrayFromListValue(input *structpb.ListValue, types map[string]DataType) (*SFSArray, error) {
+	return convertStructpbList("", input, types)
+}
+
+// structpbMap replaces the values of convertSFSObjectToMap that
+// structpb.NewValue doesn't accept.
+func structpbMap(input map[string]interface{}) map[string]interface{} {
+	for key, value := range input {
+		input[key] = structpbCompatible(value)
+	}
+	return input
+}
+
+func structpbSlice(input []interface{}) []interface{} {
+	for index, value := range input {
+		input[index] = structpbCompatible(value)
+	}
+	return input
+}
+
+func structpbCompatible(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return structpbMap(value)
+	case []interface{}:
+		return structpbSlice(value)
+	case []int8:
+		data := make([]byte, len(value))
+		for i, element := range value {
+			data[i] = byte(element)
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	case int64:
+		return structpbLong(value)
+	case []int64:
+		result := make([]interface{}, len(value))
+		for i, element := range value {
+			result[i] = structpbLong(element)
+		}
+		return result
+	case []bool, []int16, []int32, []float32, []float64, []string:
+		reflectValue := reflect.ValueOf(value)
+		result := make([]interface{}, reflectValue.Len())
+		for i := range result {
+			result[i] = reflectValue.Index(i).Interface()
+		}
+		return result
+	}
+	return value
+}
+
+// maxExactDouble is the largest integer up to which doubles hold every
+// integer exactly.
+const maxExactDouble = 1 << 53
+
+func structpbLong(value int64) interface{} {
+	if value > maxExactDouble || value < -maxExactDouble {
+		return strconv.FormatInt(value, 10)
+	}
+	return value
+}
+
+// checkStructpbKeys fails on keys that can't be told apart from nested paths
+// in a sidecar map.
+func checkStructpbKeys(wrapper sfsDataWrapper) error {
+	switch data := wrapper.data.(type) {
+	case *SFSObject:
+		for _, key := range data.sortedKeys() {
+			if err := checkStructpbKey(key); err != nil {
+				return err
+			}
+			if err := checkStructpbKeys(data.dataHolder[key]); err != nil {
+				return err
+			}
+		}
+	case *SFSArray:
+		for _, element := range data.dataHolder {
+			if err := checkStructpbKeys(element); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkStructpbKey(key string) error {
+	if strings.ContainsAny(key, ".[") {
+		return fmt.Errorf("key %q contains \".\" or \"[\", which the types map can't tell apart from a nested path", key)
+	}
+	return nil
+}
+
+func recordStructpbTypes(types map[string]DataType) WalkFunc {
+	return func(path string, value Value) error {
+		switch value.Type() {
+		case TypeNull, TypeBool, TypeDouble, TypeUtfString, TypeSFSObject, TypeSFSArray:
+		default:
+			types[path] = value.Type()
+		}
+		return nil
+	}
+}
+
+func convertStructpbStruct(prefix string, input *structpb.Struct, types map[string]DataType) (*SFSObject, error) {
+	sfsObject := NewSFSObject()
+	fields := input.GetFields()
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		value := fields[key]
+		if types != nil {
+			if err := checkStructpbKey(key); err != nil {
+				return nil, err
+			}
+		}
+		wrapper, err := convertStructpbValue(joinPath(prefix, key), value, types)
+		if err != nil {
+			return nil, err
+		}
+		if err := sfsObject.putsfsDataWrapper(key, &wrapper); err != nil {
+			return nil, err
+		}
+	}
+	return sfsObject, nil
+}
+
+func convertStructpbList(prefix string, input *structpb.ListValue, types map[string]DataType) (*SFSArray, error) {
+	sfsArray := NewSFSArray()
+	for index, value := range input.GetValues() {
+		wrapper, err := convertStructpbValue(prefix+"["+strconv.Itoa(index)+"]", value, types)
+		if err != nil {
+			return nil, err
+		}
+		sfsArray.addsfsDataWrapper(wrapper)
+	}
+	return sfsArray, nil
+}
+
+func convertStructpbValue(path string, value *structpb.Value, types map[string]DataType) (sfsDataWrapper, error) {
+	typeId, typed := types[path]
+	if !typed {
+		switch kind := value.GetKind().(type) {
+		case *structpb.Value_BoolValue:
+			return sfsDataWrapper{typeId: TypeBool, data: kind.BoolValue}, nil
+		case *structpb.Value_NumberValue:
+			return sfsDataWrapper{typeId: TypeDouble, data: kind.NumberValue}, nil
+		case *structpb.Value_StringValue:
+			if len(kind.StringValue) > maxUtfStringLength {
+				return sfsDataWrapper{typeId: TypeText, data: kind.StringValue}, nil
+			}
+			return sfsDataWrapper{typeId: TypeUtfString, data: kind.StringValue}, nil
+		case *structpb.Value_StructValue:
+			sfsObject, err := convertStructpbStruct(path, kind.StructValue, types)
+			return sfsDataWrapper{typeId: TypeSFSObject, data: sfsObject}, err
+		case *structpb.Value_ListValue:
+			sfsArray, err := convertStructpbList(path, kind.ListValue, types)
+			return sfsDataWrapper{typeId: TypeSFSArray, data: sfsArray}, err
+		default:
+			return sfsDataWrapper{typeId: TypeNull}, nil
+		}
+	}
+	wrapper, err := convertStructpbTyped(value, typeId)
+	if err != nil {
+		return sfsDataWrapper{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return wrapper, nil
+}
+
+// convertStructpbTyped converts a scalar or list of scalars into the type
+// recorded in the sidecar map.
+func convertStructpbTyped(value *structpb.Value, typeId DataType) (sfsDataWrapper, error) {
+	wrongKind := fmt.Errorf("can't convert %T to %s", value.GetKind(), typeId)
+	switch typeId {
+	case TypeByte, TypeShort, TypeInt, TypeLong:
+		integer, err := structpbInteger(value, typeId)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: typeId, data: integerData(typeId, integer)}, nil
+	case TypeFloat:
+		number, isNumber := value.GetKind().(*structpb.Value_NumberValue)
+		if !isNumber {
+			return sfsDataWrapper{}, wrongKind
+		}
+		return sfsDataWrapper{typeId: TypeFloat, data: float32(number.NumberValue)}, nil
+	case TypeText:
+		text, isString := value.GetKind().(*structpb.Value_StringValue)
+		if !isString {
+			return sfsDataWrapper{}, wrongKind
+		}
+		return sfsDataWrapper{typeId: TypeText, data: text.StringValue}, nil
+	case TypeByteArray:
+		if text, isString := value.GetKind().(*structpb.Value_StringValue); isString {
+			data, err := base64.StdEncoding.DecodeString(text.StringValue)
+			return sfsDataWrapper{typeId: TypeByteArray, data: data}, err
+		}
+	}
+	list, isList := value.GetKind().(*structpb.Value_ListValue)
+	if !isList {
+		return sfsDataWrapper{}, wrongKind
+	}
+	elements := list.ListValue.GetValues()
+	switch typeId {
+	case TypeBoolArray:
+		result := make([]bool, len(elements))
+		for i, element := range elements {
+			boolean, isBool := element.GetKind().(*structpb.Value_BoolValue)
+			if !isBool {
+				return sfsDataWrapper{}, fmt.Errorf("element %d: can't convert %T to %s", i, element.GetKind(), TypeBool)
+			}
+			result[i] = boolean.BoolValue
+		}
+		return sfsDataWrapper{typeId: TypeBoolArray, data: result}, nil
+	case TypeUtfStringArray:
+		result := make([]string, len(elements))
+		for i, element := range elements {
+			text, isString := element.GetKind().(*structpb.Value_StringValue)
+			if !isString {
+				return sfsDataWrapper{}, fmt.Errorf("element %d: can't convert %T to %s", i, element.GetKind(), TypeUtfString)
+			}
+			result[i] = text.StringValue
+		}
+		return sfsDataWrapper{typeId: TypeUtfStringArray, data: result}, nil
+	}
+	switch typeId {
+	case TypeByteArray, TypeShortArray, TypeIntArray, TypeLongArray:
+		elementType := map[DataType]DataType{TypeByteArray: TypeByte, TypeShortArray: TypeShort, TypeIntArray: TypeInt, TypeLongArray: TypeLong}[typeId]
+		integers := make([]int64, len(elements))
+		for i, element := range elements {
+			integer, err := structpbInteger(element, elementType)
+			if err != nil {
+				return sfsDataWrapper{}, fmt.Errorf("element %d: %w", i, err)
+			}
+			integers[i] = integer
+		}
+		return integerArray(elementType, integers), nil
+	}
+	numbers := make([]float64, len(elements))
+	for i, element := range elements {
+		number, isNumber := element.GetKind().(*structpb.Value_NumberValue)
+		if !isNumber {
+			return sfsDataWrapper{}, fmt.Errorf("element %d: can't convert %T to a number", i, element.GetKind())
+		}
+		numbers[i] = number.NumberValue
+	}
+	switch typeId {
+	case TypeFloatArray:
+		result := make([]float32, len(numbers))
+		for i, number := range numbers {
+			result[i] = float32(number)
+		}
+		return sfsDataWrapper{typeId: TypeFloatArray, data: result}, nil
+	case TypeDoubleArray:
+		return sfsDataWrapper{typeId: TypeDoubleArray, data: numbers}, nil
+	}
+	return sfsDataWrapper{}, wrongKind
+}
+
+// structpbInteger converts an integral number, or for LONGs a decimal
+// string, into an integer that fits typeId.
+func structpbInteger(value *structpb.Value, typeId DataType) (int64, error) {
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		number := kind.NumberValue
+		if number != math.Trunc(number) || number < math.MinInt64 || number >= math.MaxInt64 || !integerFits(int64(number), typeId) {
+			return 0, &ErrNumericOverflow{Value: number, Type: typeId}
+		}
+		return int64(number), nil
+	case *structpb.Value_StringValue:
+		if typeId == TypeLong {
+			return strconv.ParseInt(kind.StringValue, 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("can't convert %T to %s", value.GetKind(), typeId)
+}
@@ -0,0 +1,185 @@
+package sfstypes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestToStruct(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutShort("short", 5)
+	sfsobject.PutLong("exact", 1<<53)
+	sfsobject.PutLong("big", 1<<53+1)
+	sfsobject.PutLong("negative", -(1<<53 + 1))
+	sfsobject.PutByteArray("bytes", []int8{1, 2, 3})
+	sfsobject.PutIntArray("ints", []int32{1, 2})
+	sfsobject.PutLongArray("longs", []int64{1, 1<<53 + 1})
+	sfsobject.PutText("text", "t")
+	sfsobject.PutText("longText", strings.Repeat("x", maxUtfStringLength+1))
+
+	result, err := sfsobject.ToStruct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := result.GetFields()
+	if got := fields["short"].GetNumberValue(); got != 5 {
+		t.Errorf("short = %v", got)
+	}
+	if got := fields["exact"].GetNumberValue(); got != 1<<53 {
+		t.Errorf("exact = %v", fields["exact"])
+	}
+	if got := fields["big"].GetStringValue(); got != "9007199254740993" {
+		t.Errorf("big = %v, want a decimal string", fields["big"])
+	}
+	if got := fields["negative"].GetStringValue(); got != "-9007199254740993" {
+		t.Errorf("negative = %v, want a decimal string", fields["negative"])
+	}
+	if got := fields["bytes"].GetStringValue(); got != "AQID" {
+		t.Errorf("bytes = %v, want base64", fields["bytes"])
+	}
+	if got := fields["ints"].GetListValue().GetValues(); len(got) != 2 || got[1].GetNumberValue() != 2 {
+		t.Errorf("ints = %v", fields["ints"])
+	}
+	if got := fields["longs"].GetListValue().GetValues(); len(got) != 2 || got[1].GetStringValue() != "9007199254740993" {
+		t.Errorf("longs = %v", fields["longs"])
+	}
+
+	// Without the sidecar, every value comes back as its closest plain type.
+	decoded, err := NewSFSObjectFromStruct(result, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]DataType{
+		"short": TypeDouble, "big": TypeUtfString, "bytes": TypeUtfString, "ints": TypeSFSArray, "text": TypeUtfString,
+		"longText": TypeText,
+	} {
+		if got, _ := decoded.TypeOf(key); got != want {
+			t.Errorf("%s: got %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestStructWithTypesRoundTrip(t *testing.T) {
+	objects := fuzzSeedObjects()
+	for _, data := range readGoldenCorpus(t) {
+		sfsobject, err := NewSFSObjectFromBinaryData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, sfsobject)
+	}
+	big := NewSFSObject()
+	big.PutLong("max", 1<<63-1)
+	big.PutLong("min", -1<<63)
+	big.PutLongArray("longs", []int64{1<<53 + 1, -1})
+	objects = append(objects, big)
+
+	for _, sfsobject := range objects {
+		result, types, err := sfsobject.ToStructWithTypes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := NewSFSObjectFromStruct(result, types)
+		if err != nil {
+			t.Fatalf("%v\ntypes: %v", err, types)
+		}
+		if got, want := decoded.ToBinary(), sfsobject.ToBinary(); !bytes.Equal(got, want) {
+			t.Fatalf("round trip changed the payload:\n%x\n%x", want, got)
+		}
+	}
+}
+
+func TestListValueWithTypes(t *testing.T) {
+	nested := NewSFSObject()
+	nested.PutByte("b", -1)
+	sfsarray := NewSFSArray()
+	sfsarray.AddShort(1)
+	sfsarray.AddSFSObject(nested)
+	sfsarray.AddFloatArray([]float32{0.5})
+	sfsarray.AddNull()
+
+	result, types, err := sfsarray.ToListValueWithTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]DataType{"[0]": TypeShort, "[1].b": TypeByte, "[2]": TypeFloatArray}
+	if len(types) != len(want) {
+		t.Errorf("types = %v, want %v", types, want)
+	}
+	for path, typeId := range want {
+		if types[path] != typeId {
+			t.Errorf("types[%q] = %v, want %v", path, types[path], typeId)
+		}
+	}
+	decoded, err := NewSFSArrayFromListValue(result, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.ToBinary(), sfsarray.ToBinary()) {
+		t.Fatalf("round trip changed the payload:\n%x\n%x", sfsarray.ToBinary(), decoded.ToBinary())
+	}
+}
+
+func TestStructKeyCollisions(t *testing.T) {
+	for _, key := range []string{"a.b", "a[0]"} {
+		sfsobject := NewSFSObject()
+		sfsobject.PutInt(key, 1)
+		if _, err := sfsobject.ToStruct(); err != nil {
+			t.Errorf("ToStruct with key %q: %v", key, err)
+		}
+		if _, _, err := sfsobject.ToStructWithTypes(); err == nil {
+			t.Errorf("ToStructWithTypes accepted key %q", key)
+		}
+
+		nested := NewSFSArray()
+		nested.AddSFSObject(sfsobject)
+		if _, _, err := nested.ToListValueWithTypes(); err == nil {
+			t.Errorf("ToListValueWithTypes accepted nested key %q", key)
+		}
+
+		input, err := structpb.NewStruct(map[string]interface{}{key: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewSFSObjectFromStruct(input, nil); err != nil {
+			t.Errorf("NewSFSObjectFromStruct without types, key %q: %v", key, err)
+		}
+		if _, err := NewSFSObjectFromStruct(input, map[string]DataType{}); err == nil {
+			t.Errorf("NewSFSObjectFromStruct with types accepted key %q", key)
+		}
+	}
+}
+
+func TestStructRejectsMismatchedTypes(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		typeId DataType
+		want   string
+	}{
+		{"1", TypeInt, "can't convert"},
+		{1.5, TypeInt, "overflow"},
+		{300, TypeByte, "overflow"},
+		{1e19, TypeLong, "overflow"},
+		{"x", TypeLong, "invalid syntax"},
+		{1, TypeText, "can't convert"},
+		{"***", TypeByteArray, "illegal base64"},
+		{[]interface{}{1, "a"}, TypeIntArray, "element 1"},
+		{[]interface{}{true, 1}, TypeBoolArray, "element 1"},
+		{[]interface{}{"a", 1}, TypeUtfStringArray, "element 1"},
+		{[]interface{}{1, "a"}, TypeDoubleArray, "element 1"},
+		{1, TypeFloatArray, "can't convert"},
+	}
+	for _, test := range tests {
+		input, err := structpb.NewStruct(map[string]interface{}{"k": test.value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = NewSFSObjectFromStruct(input, map[string]DataType{"k": test.typeId})
+		if err == nil || !strings.Contains(err.Error(), test.want) || !strings.HasPrefix(err.Error(), "k: ") {
+			t.Errorf("%v as %v: got %v, want %q", test.value, test.typeId, err, test.want)
+		}
+	}
+}
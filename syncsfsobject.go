@@ -0,0 +1,180 @@
+package sfstypes
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// SyncSFSObject is an SFSObject guarded by a read/write mutex, safe for use
+// by multiple goroutines. Values are deep-copied on the way in and out, so no
+// caller ever holds a reference into the guarded data.
+type SyncSFSObject struct {
+	mutex  sync.RWMutex
+	object *SFSObject
+}
+
+func NewSyncSFSObject() *SyncSFSObject {
+	return &SyncSFSObject{
+		object: NewSFSObject(),
+	}
+}
+
+// NewSyncSFSObjectFrom guards a deep copy of sfsobject.
+func NewSyncSFSObjectFrom(sfsobject *SFSObject) *SyncSFSObject {
+	return &SyncSFSObject{
+		object: sfsobject.deepCopy(),
+	}
+}
+
+func (syncobject *SyncSFSObject) Size() int {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return syncobject.object.Size()
+}
+
+func (syncobject *SyncSFSObject) GetKeys() []string {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return syncobject.object.GetKeys()
+}
+
+func (syncobject *SyncSFSObject) ContainsKey(key string) bool {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return syncobject.object.ContainsKey(key)
+}
+
+func (syncobject *SyncSFSObject) RemoveElement(key string) error {
+	syncobject.mutex.Lock()
+	defer syncobject.mutex.Unlock()
+	return syncobject.object.RemoveElement(key)
+}
+
+// Get returns a deep copy of the value stored under key.
+func (syncobject *SyncSFSObject) Get(key string) (interface{}, error) {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return syncobject.get(key)
+}
+
+// Put stores a deep copy of value under key.
+func (syncobject *SyncSFSObject) Put(key string, value interface{}) error {
+	syncobject.mutex.Lock()
+	defer syncobject.mutex.Unlock()
+	return syncobject.put(key, value)
+}
+
+// PutIfAbsent stores value under key only if the key is not present yet and
+// reports whether it did so.
+func (syncobject *SyncSFSObject) PutIfAbsent(key string, value interface{}) (bool, error) {
+	syncobject.mutex.Lock()
+	defer syncobject.mutex.Unlock()
+	if syncobject.object.ContainsKey(key) {
+		return false, nil
+	}
+	if err := syncobject.put(key, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CompareAndSwap replaces the value under key with newValue if the stored
+// value has the same SFS type and contents as oldValue, and reports whether
+// the swap happened. oldValue is converted the same way Put converts values,
+// so an int compares equal to a stored INT.
+func (syncobject *SyncSFSObject) CompareAndSwap(key string, oldValue interface{}, newValue interface{}) (bool, error) {
+	syncobject.mutex.Lock()
+	defer syncobject.mutex.Unlock()
+	current, err := syncobject.object.getWrapper(key)
+	if err != nil {
+		return false, err
+	}
+	expected := NewSFSObject()
+	if err := expected.Put(key, oldValue); err != nil {
+		return false, err
+	}
+	expectedWrapper := expected.dataHolder[key]
+	if current.typeId != expectedWrapper.typeId || !reflect.DeepEqual(current.data, expectedWrapper.data) {
+		return false, nil
+	}
+	if err := syncobject.put(key, newValue); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Update atomically replaces the value under key with the result of fn. fn is
+// called with a deep copy of the current value and whether the key exists. If
+// fn returns an error the object is left unchanged and the error is returned.
+func (syncobject *SyncSFSObject) Update(key string, fn func(value interface{}, exists bool) (interface{}, error)) error {
+	syncobject.mutex.Lock()
+	defer syncobject.mutex.Unlock()
+	current, err := syncobject.get(key)
+	exists := err == nil
+	newValue, err := fn(current, exists)
+	if err != nil {
+		return err
+	}
+	return syncobject.put(key, newValue)
+}
+
+// View calls fn with the guarded object while holding the read lock. fn must
+// not modify the object or retain references to it after returning.
+func (syncobject *SyncSFSObject) View(fn func(sfsobject *SFSObject) error) error {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return fn(syncobject.object)
+}
+
+// Modify calls fn with the guarded object while holding the write lock, which
+// allows several changes to be applied atomically. fn must not retain
+// references to the object after returning.
+func (syncobject *SyncSFSObject) Modify(fn func(sfsobject *SFSObject) error) error {
+	syncobject.mutex.Lock()
+	defer syncobject.mutex.Unlock()
+	return fn(syncobject.object)
+}
+
+// Snapshot returns a consistent deep copy of the guarded object.
+func (syncobject *SyncSFSObject) Snapshot() *SFSObject {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return syncobject.object.deepCopy()
+}
+
+func (syncobject *SyncSFSObject) ToBinary() []byte {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return syncobject.object.ToBinary()
+}
+
+func (syncobject *SyncSFSObject) ToJson() string {
+	syncobject.mutex.RLock()
+	defer syncobject.mutex.RUnlock()
+	return syncobject.object.ToJson()
+}
+
+// LogValue logs a snapshot, so the handler doesn't read values that are
+// being modified.
+func (syncobject *SyncSFSObject) LogValue() slog.Value {
+	return syncobject.Snapshot().LogValue()
+}
+
+func (syncobject *SyncSFSObject) get(key string) (interface{}, error) {
+	wrapper, err := syncobject.object.getWrapper(key)
+	if err != nil {
+		return nil, err
+	}
+	copied := wrapper.deepCopy()
+	return copied.interfaceValue(), nil
+}
+
+func (syncobject *SyncSFSObject) put(key string, value interface{}) error {
+	if err := syncobject.object.Put(key, value); err != nil {
+		return err
+	}
+	wrapper := syncobject.object.dataHolder[key]
+	syncobject.object.dataHolder[key] = wrapper.deepCopy()
+	return nil
+}
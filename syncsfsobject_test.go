@@ -0,0 +1,206 @@
+package sfstypes
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Run with -race: the tests only check the results, the race detector checks
+// the locking.
+
+const (
+	syncTestGoroutines = 8
+	syncTestIterations = 200
+)
+
+func TestSyncSFSObjectConcurrentAccess(t *testing.T) {
+	t.Parallel()
+	syncobject := NewSyncSFSObject()
+	var wg sync.WaitGroup
+	for g := 0; g < syncTestGoroutines; g++ {
+		wg.Add(3)
+		go func(g int) {
+			defer wg.Done()
+			nested := NewSFSObject()
+			for i := 0; i < syncTestIterations; i++ {
+				nested.PutInt("i", int32(i))
+				if err := syncobject.Put("key"+strconv.Itoa(g), nested); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < syncTestIterations; i++ {
+				if _, err := NewSFSObjectFromBinaryData(syncobject.ToBinary()); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < syncTestIterations; i++ {
+				snapshot := syncobject.Snapshot()
+				// The snapshot is detached, so changing it must not race
+				// with the writers.
+				snapshot.PutBool("snapshot", true)
+			}
+		}()
+	}
+	wg.Wait()
+	if syncobject.Size() != syncTestGoroutines {
+		t.Fatalf("got %d keys, want %d", syncobject.Size(), syncTestGoroutines)
+	}
+	if syncobject.ContainsKey("snapshot") {
+		t.Fatal("change to a snapshot is visible in the SyncSFSObject")
+	}
+	for g := 0; g < syncTestGoroutines; g++ {
+		value, err := syncobject.Get("key" + strconv.Itoa(g))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i, _ := value.(*SFSObject).GetInt("i"); i != syncTestIterations-1 {
+			t.Fatalf("key%d: got i=%d, want %d", g, i, syncTestIterations-1)
+		}
+	}
+}
+
+func TestSyncSFSObjectPutIfAbsent(t *testing.T) {
+	t.Parallel()
+	syncobject := NewSyncSFSObject()
+	var wg sync.WaitGroup
+	winners := make(chan int32, syncTestGoroutines)
+	for g := 0; g < syncTestGoroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			stored, err := syncobject.PutIfAbsent("owner", int32(g))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if stored {
+				winners <- int32(g)
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(winners)
+	if len(winners) != 1 {
+		t.Fatalf("got %d winners, want 1", len(winners))
+	}
+	winner := <-winners
+	if value, err := syncobject.Get("owner"); err != nil || value != winner {
+		t.Fatalf("got %v, %v, want %d", value, err, winner)
+	}
+}
+
+func TestSyncSFSObjectCompareAndSwap(t *testing.T) {
+	t.Parallel()
+	syncobject := NewSyncSFSObject()
+	if err := syncobject.Put("counter", int32(0)); err != nil {
+		t.Fatal(err)
+	}
+	if swapped, err := syncobject.CompareAndSwap("counter", "0", int32(1)); err != nil || swapped {
+		t.Fatalf("swapped a UTF_STRING for an INT: %v, %v", swapped, err)
+	}
+	if _, err := syncobject.CompareAndSwap("missing", int32(0), int32(1)); err == nil {
+		t.Fatal("CompareAndSwap on a missing key succeeded")
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < syncTestGoroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < syncTestIterations; {
+				value, err := syncobject.Get("counter")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				swapped, err := syncobject.CompareAndSwap("counter", value, value.(int32)+1)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if swapped {
+					i++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assertSyncCounter(t, syncobject, syncTestGoroutines*syncTestIterations)
+}
+
+func TestSyncSFSObjectUpdate(t *testing.T) {
+	t.Parallel()
+	syncobject := NewSyncSFSObject()
+	var wg sync.WaitGroup
+	for g := 0; g < syncTestGoroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < syncTestIterations; i++ {
+				err := syncobject.Update("counter", func(value interface{}, exists bool) (interface{}, error) {
+					if !exists {
+						return int32(1), nil
+					}
+					return value.(int32) + 1, nil
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assertSyncCounter(t, syncobject, syncTestGoroutines*syncTestIterations)
+}
+
+func TestSyncSFSObjectModify(t *testing.T) {
+	t.Parallel()
+	syncobject := NewSyncSFSObject()
+	var wg sync.WaitGroup
+	for g := 0; g < syncTestGoroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < syncTestIterations; i++ {
+				err := syncobject.Modify(func(sfsobject *SFSObject) error {
+					counter, _ := sfsobject.GetInt("counter")
+					sfsobject.PutInt("counter", counter+1)
+					sfsobject.PutInt("last", int32(g))
+					return nil
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assertSyncCounter(t, syncobject, syncTestGoroutines*syncTestIterations)
+}
+
+func assertSyncCounter(t *testing.T, syncobject *SyncSFSObject, want int32) {
+	t.Helper()
+	err := syncobject.View(func(sfsobject *SFSObject) error {
+		counter, err := sfsobject.GetInt("counter")
+		if err != nil {
+			return err
+		}
+		if counter != want {
+			t.Errorf("got counter %d, want %d: updates were lost", counter, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,243 @@
+package sfstypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Typed JSON keeps the SFS type of every value, so it converts back to the
+// same object, unlike ToJson. Each value is written as {"t": type, "v": data}
+// with the SFS2X type name, objects and arrays hold typed values, and
+// BYTE_ARRAY data is base64:
+//
+//	{"t":"SFS_OBJECT","v":{"id":{"t":"INT","v":7},"tags":{"t":"UTF_STRING_ARRAY","v":["a"]}}}
+//
+// Keys are written in sorted order, like ToBinary. Every value must have a
+// "t"; "v" may only be left out for NULL. NaN and infinite floats can't be
+// represented and fail to convert.
+
+func (sfsobject *SFSObject) ToTypedJson() (string, error) {
+	result, err := appendTypedJson(nil, sfsDataWrapper{typeId: TypeSFSObject, data: sfsobject})
+	return string(result), err
+}
+
+func (sfsarray *SFSArray) ToTypedJson() (string, error) {
+	result, err := appendTypedJson(nil, sfsDataWrapper{typeId: TypeSFSArray, data: sfsarray})
+	return string(result), err
+}
+
+func NewSFSObjectFromTypedJsonData(jsonString string) (*SFSObject, error) {
+	wrapper, err := decodeTypedJson([]byte(jsonString), TypeSFSObject)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSObject), nil
+}
+
+func NewSFSArrayFromTypedJsonData(jsonString string) (*SFSArray, error) {
+	wrapper, err := decodeTypedJson([]byte(jsonString), TypeSFSArray)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSArray), nil
+}
+
+func appendTypedJson(dst []byte, wrapper sfsDataWrapper) ([]byte, error) {
+	dst = append(dst, `{"t":"`...)
+	dst = append(dst, wrapper.typeId.Name()...)
+	dst = append(dst, '"')
+	var err error
+	switch data := wrapper.data.(type) {
+	case nil:
+	case *SFSObject:
+		dst = append(dst, `,"v":{`...)
+		for i, key := range data.sortedKeys() {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			encodedKey, err := json.Marshal(key)
+			if err != nil {
+				return nil, err
+			}
+			dst = append(dst, encodedKey...)
+			dst = append(dst, ':')
+			if dst, err = appendTypedJson(dst, data.dataHolder[key]); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, '}')
+	case *SFSArray:
+		dst = append(dst, `,"v":[`...)
+		for i, element := range data.dataHolder {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendTypedJson(dst, element); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, ']')
+	default:
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		// Arrays stored as nil slices are empty on the wire as well.
+		if string(encoded) == "null" {
+			encoded = []byte("[]")
+			if wrapper.typeId == TypeByteArray {
+				encoded = []byte(`""`)
+			}
+		}
+		dst = append(dst, `,"v":`...)
+		dst = append(dst, encoded...)
+	}
+	return append(dst, '}'), nil
+}
+
+type typedJsonValue struct {
+	Type  DataType
+	Value json.RawMessage
+}
+
+func (value *typedJsonValue) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		Type  *DataType       `json:"t"`
+		Value json.RawMessage `json:"v"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if fields.Type == nil {
+		return fmt.Errorf("typed JSON value %s has no \"t\"", data)
+	}
+	value.Type, value.Value = *fields.Type, fields.Value
+	return nil
+}
+
+func decodeTypedJson(data []byte, typeId DataType) (sfsDataWrapper, error) {
+	var value typedJsonValue
+	if err := json.Unmarshal(data, &value); err != nil {
+		return sfsDataWrapper{}, err
+	}
+	if value.Type != typeId {
+		return sfsDataWrapper{}, &ErrWrongType{ActualType: value.Type, WantedType: typeId}
+	}
+	return value.wrapper()
+}
+
+// isTypedJson reports whether data is a typed JSON value of the given type,
+// without decoding its contents.
+func isTypedJson(data []byte, typeId DataType) bool {
+	var header struct {
+		Type  *DataType       `json:"t"`
+		Value json.RawMessage `json:"v"`
+	}
+	return json.Unmarshal(data, &header) == nil && header.Type != nil && *header.Type == typeId && header.Value != nil
+}
+
+func (value *typedJsonValue) wrapper() (sfsDataWrapper, error) {
+	var data interface{}
+	var err error
+	switch value.Type {
+	case TypeNull:
+		return sfsDataWrapper{typeId: TypeNull}, nil
+	case TypeBool:
+		data, err = unmarshalTypedJson[bool](value.Value)
+	case TypeByte:
+		data, err = unmarshalTypedJson[int8](value.Value)
+	case TypeShort:
+		data, err = unmarshalTypedJson[int16](value.Value)
+	case TypeInt:
+		data, err = unmarshalTypedJson[int32](value.Value)
+	case TypeLong:
+		data, err = unmarshalTypedJson[int64](value.Value)
+	case TypeFloat:
+		data, err = unmarshalTypedJson[float32](value.Value)
+	case TypeDouble:
+		data, err = unmarshalTypedJson[float64](value.Value)
+	case TypeUtfString, TypeText:
+		data, err = unmarshalTypedJson[string](value.Value)
+	case TypeBoolArray:
+		data, err = unmarshalTypedJson[[]bool](value.Value)
+	case TypeByteArray:
+		data, err = unmarshalTypedJson[[]byte](value.Value)
+	case TypeShortArray:
+		data, err = unmarshalTypedJson[[]int16](value.Value)
+	case TypeIntArray:
+		data, err = unmarshalTypedJson[[]int32](value.Value)
+	case TypeLongArray:
+		data, err = unmarshalTypedJson[[]int64](value.Value)
+	case TypeFloatArray:
+		data, err = unmarshalTypedJson[[]float32](value.Value)
+	case TypeDoubleArray:
+		data, err = unmarshalTypedJson[[]float64](value.Value)
+	case TypeUtfStringArray:
+		data, err = unmarshalTypedJson[[]string](value.Value)
+	case TypeSFSObject:
+		data, err = typedJsonToSFSObject(value.Value)
+	case TypeSFSArray:
+		data, err = typedJsonToSFSArray(value.Value)
+	default:
+		return sfsDataWrapper{}, &ErrDecodingUnsupportedType{Type: value.Type}
+	}
+	if err != nil {
+		return sfsDataWrapper{}, fmt.Errorf("typed JSON %s value: %w", value.Type.Name(), err)
+	}
+	return sfsDataWrapper{typeId: value.Type, data: data}, nil
+}
+
+// unmarshalTypedJson decodes the data of a value, which must be present.
+func unmarshalTypedJson[T any](data json.RawMessage) (T, error) {
+	var result T
+	if bytes.Equal(data, []byte("null")) {
+		return result, fmt.Errorf("unexpected null")
+	}
+	err := json.Unmarshal(data, &result)
+	return result, err
+}
+
+func typedJsonToSFSObject(data json.RawMessage) (*SFSObject, error) {
+	var values map[string]typedJsonValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return nil, fmt.Errorf("unexpected null")
+	}
+	sfsobject := NewSFSObject()
+	// Go maps have no order; sorting keeps the reported error stable.
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		value := values[key]
+		wrapper, err := value.wrapper()
+		if err != nil {
+			return nil, err
+		}
+		if err := sfsobject.putsfsDataWrapper(key, &wrapper); err != nil {
+			return nil, err
+		}
+	}
+	return sfsobject, nil
+}
+
+func typedJsonToSFSArray(data json.RawMessage) (*SFSArray, error) {
+	var values []typedJsonValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return nil, fmt.Errorf("unexpected null")
+	}
+	sfsarray := NewSFSArray()
+	for i := range values {
+		wrapper, err := values[i].wrapper()
+		if err != nil {
+			return nil, err
+		}
+		sfsarray.addsfsDataWrapper(wrapper)
+	}
+	return sfsarray, nil
+}
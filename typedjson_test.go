@@ -0,0 +1,97 @@
+package sfstypes
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTypedJsonRoundTrip(t *testing.T) {
+	for _, sfsobject := range fuzzSeedObjects() {
+		typedJson, err := sfsobject.ToTypedJson()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := NewSFSObjectFromTypedJsonData(typedJson)
+		if err != nil {
+			t.Fatalf("%s: %v", typedJson, err)
+		}
+		if got, want := decoded.ToBinary(), sfsobject.ToBinary(); !bytes.Equal(got, want) {
+			t.Fatalf("round trip changed the payload:\n%x\n%x", want, got)
+		}
+	}
+
+	sfsarray := NewSFSArray()
+	sfsarray.AddNull()
+	sfsarray.AddText("text")
+	sfsarray.AddByteArray([]int8{-1, 2})
+	typedJson, err := sfsarray.ToTypedJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"t":"SFS_ARRAY","v":[{"t":"NULL"},{"t":"TEXT","v":"text"},{"t":"BYTE_ARRAY","v":"/wI="}]}`
+	if typedJson != want {
+		t.Fatalf("got %s, want %s", typedJson, want)
+	}
+	decoded, err := NewSFSArrayFromTypedJsonData(typedJson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.ToBinary(), sfsarray.ToBinary()) {
+		t.Fatalf("SFSArray round trip changed the payload")
+	}
+}
+
+func TestTypedJsonKeyOrder(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("z", 1)
+	sfsobject.PutShort("a", 2)
+	typedJson, err := sfsobject.ToTypedJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"t":"SFS_OBJECT","v":{"a":{"t":"SHORT","v":2},"z":{"t":"INT","v":1}}}`; typedJson != want {
+		t.Fatalf("got %s, want %s", typedJson, want)
+	}
+}
+
+func TestTypedJsonRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`{"v":{}}`, `has no "t"`},
+		{`{"t":"SFS_OBJECT","v":{"id":{"v":1}}}`, `has no "t"`},
+		{`{"t":"SFS_OBJECT","v":{"id":null}}`, `has no "t"`},
+		{`{"t":"SFS_OBJECT","v":{"id":{"t":"INT"}}}`, "INT value"},
+		{`{"t":"SFS_OBJECT","v":{"id":{"t":"INT","v":null}}}`, "unexpected null"},
+		{`{"t":"SFS_OBJECT","v":{"id":{"t":"BYTE","v":128}}}`, "BYTE value"},
+		{`{"t":"SFS_OBJECT","v":{"id":{"t":"CLASS","v":{}}}}`, "CLASS"},
+		{`{"t":"SFS_OBJECT","v":{"id":{"t":"int","v":1}}}`, `unknown SFS data type "int"`},
+		{`{"t":"SFS_OBJECT","v":null}`, "unexpected null"},
+		{`{"t":"SFS_OBJECT","v":[]}`, "cannot unmarshal array"},
+		{`{"t":"SFS_ARRAY","v":[]}`, "SFS_ARRAY"},
+		{`{"t":"SFS_OBJECT","v":{"":{"t":"NULL"}}}`, ""},
+	}
+	for _, test := range tests {
+		_, err := NewSFSObjectFromTypedJsonData(test.json)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want %q", test.json, err, test.want)
+		}
+	}
+
+	if _, err := NewSFSArrayFromTypedJsonData(`{"t":"SFS_ARRAY","v":[{"v":1}]}`); err == nil {
+		t.Error("array element without a type was accepted")
+	}
+}
+
+func TestTypedJsonRejectsNonFiniteFloats(t *testing.T) {
+	for _, value := range []float64{math.NaN(), math.Inf(1)} {
+		sfsobject := NewSFSObject()
+		sfsobject.PutDouble("d", value)
+		if _, err := sfsobject.ToTypedJson(); err == nil {
+			t.Errorf("%v was converted", value)
+		}
+	}
+}
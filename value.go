@@ -0,0 +1,254 @@
+package sfstypes
+
+// Value is a typed handle to a single value stored in an SFSObject or
+// SFSArray. Containers and slices returned by its accessors alias the stored
+// data, like the Get accessors of SFSObject and SFSArray do.
+type Value struct {
+	wrapper sfsDataWrapper
+}
+
+// NewValue pairs data with typeId. data must have the Go type the Get
+// accessors use for typeId, e.g. int16 for TypeShort or *SFSObject for
+// TypeSFSObject. BYTE_ARRAY data may be given as []int8 or []byte.
+func NewValue(typeId DataType, data interface{}) (Value, error) {
+	if array, isInt8s := data.([]int8); isInt8s && typeId == TypeByteArray {
+		data = Int8sToBytes(array)
+	}
+	if !dataMatchesType(typeId, data) {
+		return Value{}, &ErrUnsupportedType{Value: data}
+	}
+	return Value{wrapper: sfsDataWrapper{typeId: typeId, data: data}}, nil
+}
+
+func dataMatchesType(typeId DataType, data interface{}) bool {
+	var ok bool
+	switch typeId {
+	case TypeNull:
+		ok = data == nil
+	case TypeBool:
+		_, ok = data.(bool)
+	case TypeByte:
+		_, ok = data.(int8)
+	case TypeShort:
+		_, ok = data.(int16)
+	case TypeInt:
+		_, ok = data.(int32)
+	case TypeLong:
+		_, ok = data.(int64)
+	case TypeFloat:
+		_, ok = data.(float32)
+	case TypeDouble:
+		_, ok = data.(float64)
+	case TypeUtfString, TypeText:
+		_, ok = data.(string)
+	case TypeBoolArray:
+		_, ok = data.([]bool)
+	case TypeByteArray:
+		_, ok = data.([]byte)
+	case TypeShortArray:
+		_, ok = data.([]int16)
+	case TypeIntArray:
+		_, ok = data.([]int32)
+	case TypeLongArray:
+		_, ok = data.([]int64)
+	case TypeFloatArray:
+		_, ok = data.([]float32)
+	case TypeDoubleArray:
+		_, ok = data.([]float64)
+	case TypeUtfStringArray:
+		_, ok = data.([]string)
+	case TypeSFSArray:
+		var arr *SFSArray
+		arr, ok = data.(*SFSArray)
+		ok = ok && arr != nil
+	case TypeSFSObject:
+		var obj *SFSObject
+		obj, ok = data.(*SFSObject)
+		ok = ok && obj != nil
+	}
+	return ok
+}
+
+func (value Value) Type() DataType {
+	return value.wrapper.typeId
+}
+
+func (value Value) IsNull() bool {
+	return value.wrapper.typeId == TypeNull
+}
+
+// Interface returns the stored data as the Go type the Get accessors use.
+func (value Value) Interface() interface{} {
+	return value.wrapper.interfaceValue()
+}
+
+func (value Value) as(typeId DataType) (interface{}, error) {
+	if value.wrapper.typeId != typeId {
+		return nil, &ErrWrongType{ActualType: value.wrapper.typeId, WantedType: typeId}
+	}
+	return value.wrapper.data, nil
+}
+
+func (value Value) AsBool() (bool, error) {
+	data, err := value.as(TypeBool)
+	if err != nil {
+		return false, err
+	}
+	return data.(bool), nil
+}
+
+func (value Value) AsByte() (int8, error) {
+	data, err := value.as(TypeByte)
+	if err != nil {
+		return 0, err
+	}
+	return data.(int8), nil
+}
+
+func (value Value) AsShort() (int16, error) {
+	data, err := value.as(TypeShort)
+	if err != nil {
+		return 0, err
+	}
+	return data.(int16), nil
+}
+
+func (value Value) AsInt() (int32, error) {
+	data, err := value.as(TypeInt)
+	if err != nil {
+		return 0, err
+	}
+	return data.(int32), nil
+}
+
+func (value Value) AsLong() (int64, error) {
+	data, err := value.as(TypeLong)
+	if err != nil {
+		return 0, err
+	}
+	return data.(int64), nil
+}
+
+func (value Value) AsFloat() (float32, error) {
+	data, err := value.as(TypeFloat)
+	if err != nil {
+		return 0, err
+	}
+	return data.(float32), nil
+}
+
+func (value Value) AsDouble() (float64, error) {
+	data, err := value.as(TypeDouble)
+	if err != nil {
+		return 0, err
+	}
+	return data.(float64), nil
+}
+
+// AsString returns the data of a UTF_STRING or TEXT value.
+func (value Value) AsString() (string, error) {
+	if value.wrapper.typeId == TypeText {
+		return value.wrapper.data.(string), nil
+	}
+	data, err := value.as(TypeUtfString)
+	if err != nil {
+		return "", err
+	}
+	return data.(string), nil
+}
+
+func (value Value) AsBoolArray() ([]bool, error) {
+	data, err := value.as(TypeBoolArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]bool), nil
+}
+
+func (value Value) AsByteArray() ([]int8, error) {
+	data, err := value.as(TypeByteArray)
+	if err != nil {
+		return nil, err
+	}
+	return BytesToInt8s(data.([]byte)), nil
+}
+
+func (value Value) AsUnsignedByte() (uint8, error) {
+	data, err := value.as(TypeByte)
+	if err != nil {
+		return 0, err
+	}
+	return uint8(data.(int8)), nil
+}
+
+func (value Value) AsUnsignedByteArray() ([]byte, error) {
+	data, err := value.as(TypeByteArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]byte), nil
+}
+
+func (value Value) AsShortArray() ([]int16, error) {
+	data, err := value.as(TypeShortArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]int16), nil
+}
+
+func (value Value) AsIntArray() ([]int32, error) {
+	data, err := value.as(TypeIntArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]int32), nil
+}
+
+func (value Value) AsLongArray() ([]int64, error) {
+	data, err := value.as(TypeLongArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]int64), nil
+}
+
+func (value Value) AsFloatArray() ([]float32, error) {
+	data, err := value.as(TypeFloatArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]float32), nil
+}
+
+func (value Value) AsDoubleArray() ([]float64, error) {
+	data, err := value.as(TypeDoubleArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]float64), nil
+}
+
+func (value Value) AsStringArray() ([]string, error) {
+	data, err := value.as(TypeUtfStringArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]string), nil
+}
+
+func (value Value) AsSFSArray() (*SFSArray, error) {
+	data, err := value.as(TypeSFSArray)
+	if err != nil {
+		return nil, err
+	}
+	return data.(*SFSArray), nil
+}
+
+func (value Value) AsSFSObject() (*SFSObject, error) {
+	data, err := value.as(TypeSFSObject)
+	if err != nil {
+		return nil, err
+	}
+	return data.(*SFSObject), nil
+}
@@ -0,0 +1,104 @@
+package sfstypes
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValueAccessors(t *testing.T) {
+	object := NewSFSObject()
+	array := NewSFSArray()
+	tests := []struct {
+		typeId DataType
+		data   interface{}
+		as     func(Value) (interface{}, error)
+	}{
+		{TypeBool, true, func(v Value) (interface{}, error) { return v.AsBool() }},
+		{TypeByte, int8(-1), func(v Value) (interface{}, error) { return v.AsByte() }},
+		{TypeShort, int16(-2), func(v Value) (interface{}, error) { return v.AsShort() }},
+		{TypeInt, int32(-3), func(v Value) (interface{}, error) { return v.AsInt() }},
+		{TypeLong, int64(-4), func(v Value) (interface{}, error) { return v.AsLong() }},
+		{TypeFloat, float32(0.5), func(v Value) (interface{}, error) { return v.AsFloat() }},
+		{TypeDouble, 0.25, func(v Value) (interface{}, error) { return v.AsDouble() }},
+		{TypeUtfString, "string", func(v Value) (interface{}, error) { return v.AsString() }},
+		{TypeText, "text", func(v Value) (interface{}, error) { return v.AsString() }},
+		{TypeBoolArray, []bool{true}, func(v Value) (interface{}, error) { return v.AsBoolArray() }},
+		{TypeByteArray, []int8{1}, func(v Value) (interface{}, error) { return v.AsByteArray() }},
+		{TypeShortArray, []int16{2}, func(v Value) (interface{}, error) { return v.AsShortArray() }},
+		{TypeIntArray, []int32{3}, func(v Value) (interface{}, error) { return v.AsIntArray() }},
+		{TypeLongArray, []int64{4}, func(v Value) (interface{}, error) { return v.AsLongArray() }},
+		{TypeFloatArray, []float32{5}, func(v Value) (interface{}, error) { return v.AsFloatArray() }},
+		{TypeDoubleArray, []float64{6}, func(v Value) (interface{}, error) { return v.AsDoubleArray() }},
+		{TypeUtfStringArray, []string{"7"}, func(v Value) (interface{}, error) { return v.AsStringArray() }},
+		{TypeSFSArray, array, func(v Value) (interface{}, error) { return v.AsSFSArray() }},
+		{TypeSFSObject, object, func(v Value) (interface{}, error) { return v.AsSFSObject() }},
+	}
+	for _, test := range tests {
+		value, err := NewValue(test.typeId, test.data)
+		if err != nil {
+			t.Fatalf("NewValue(%v, %v): %v", test.typeId, test.data, err)
+		}
+		if value.Type() != test.typeId {
+			t.Errorf("%v: Type() = %v", test.typeId, value.Type())
+		}
+		got, err := test.as(value)
+		if err != nil {
+			t.Errorf("%v: %v", test.typeId, err)
+		} else if !reflect.DeepEqual(got, test.data) {
+			t.Errorf("%v: got %v, want %v", test.typeId, got, test.data)
+		}
+
+		// Every accessor but the matching one must fail with the types
+		// involved.
+		other := tests[0]
+		if test.typeId == other.typeId {
+			other = tests[1]
+		}
+		var wrongType *ErrWrongType
+		if _, err := other.as(value); !errors.As(err, &wrongType) {
+			t.Errorf("%v read as %v: got %v, want ErrWrongType", test.typeId, other.typeId, err)
+		} else if wrongType.ActualType != test.typeId || wrongType.WantedType != other.typeId {
+			t.Errorf("%v read as %v: got %+v", test.typeId, other.typeId, wrongType)
+		}
+	}
+}
+
+func TestNewValueRejectsMismatchedData(t *testing.T) {
+	mismatches := []struct {
+		typeId DataType
+		data   interface{}
+	}{
+		{TypeNull, int32(0)},
+		{TypeInt, int64(0)},
+		{TypeUtfString, []string{}},
+		{TypeSFSObject, (*SFSObject)(nil)},
+		{TypeSFSArray, NewSFSObject()},
+		{TypeClass, nil},
+	}
+	for _, mismatch := range mismatches {
+		var unsupported *ErrUnsupportedType
+		if _, err := NewValue(mismatch.typeId, mismatch.data); !errors.As(err, &unsupported) {
+			t.Errorf("NewValue(%v, %#v): got %v, want ErrUnsupportedType", mismatch.typeId, mismatch.data, err)
+		}
+	}
+	value, err := NewValue(TypeNull, nil)
+	if err != nil || !value.IsNull() {
+		t.Fatalf("NewValue(TypeNull, nil) = %v, %v", value, err)
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutShort("short", 1)
+	if typeId, err := sfsobject.TypeOf("short"); err != nil || typeId != TypeShort {
+		t.Fatalf("TypeOf(short) = %v, %v", typeId, err)
+	}
+	var notFound *ErrKeyNotFound
+	if _, err := sfsobject.TypeOf("missing"); !errors.As(err, &notFound) || notFound.Key != "missing" {
+		t.Fatalf("TypeOf(missing): got %v, want ErrKeyNotFound", err)
+	}
+	if TypeShortArray.String() != "SHORT_ARRAY/[]int16" {
+		t.Fatalf("String() = %q", TypeShortArray.String())
+	}
+}
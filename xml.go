@@ -0,0 +1,389 @@
+package sfstypes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// XML conversion uses the layout of SmartFoxServer 1.x:
+//
+//	<dataObj><var n='id' t='n'>7</var><obj o='pos' t='o'>...</obj></dataObj>
+//
+// Values are var elements named by n, containers obj elements named by o,
+// and t holds the type letter: b (bool, written 1 or 0), n (number), s
+// (string), x (null), o (object) and a (array, whose elements are named by
+// their index). The SFS2X number types all become n and TEXT becomes s;
+// typed arrays and BYTE_ARRAYs become arrays of n, b or s. On the way back
+// integral numbers become INT, or LONG if they don't fit, other numbers
+// DOUBLE, and strings that a UTF_STRING can't hold TEXT.
+//
+// SFS1.x has no top-level arrays, so an SFSArray is written as a dataObj
+// whose values are named by their index. Object keys are written in sorted
+// order. Only whitespace, comments and processing instructions may follow
+// the closing dataObj tag.
+
+func (sfsobject *SFSObject) ToXml() string {
+	var builder strings.Builder
+	builder.WriteString("<dataObj>")
+	for _, key := range sfsobject.sortedKeys() {
+		appendXmlValue(&builder, key, sfsobject.dataHolder[key])
+	}
+	builder.WriteString("</dataObj>")
+	return builder.String()
+}
+
+func (sfsarray *SFSArray) ToXml() string {
+	var builder strings.Builder
+	builder.WriteString("<dataObj>")
+	for index, wrapper := range sfsarray.dataHolder {
+		appendXmlValue(&builder, strconv.Itoa(index), wrapper)
+	}
+	builder.WriteString("</dataObj>")
+	return builder.String()
+}
+
+func NewSFSObjectFromXmlData(xmlString string) (*SFSObject, error) {
+	wrapper, err := parseXml(xmlString, 'o')
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSObject), nil
+}
+
+func NewSFSArrayFromXmlData(xmlString string) (*SFSArray, error) {
+	wrapper, err := parseXml(xmlString, 'a')
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSArray), nil
+}
+
+func appendXmlValue(builder *strings.Builder, name string, wrapper sfsDataWrapper) {
+	switch data := wrapper.data.(type) {
+	case nil:
+		appendXmlVar(builder, name, 'x', "")
+	case bool:
+		appendXmlVar(builder, name, 'b', xmlBool(data))
+	case string:
+		appendXmlVar(builder, name, 's', data)
+	case float32:
+		appendXmlVar(builder, name, 'n', strconv.FormatFloat(float64(data), 'g', -1, 32))
+	case float64:
+		appendXmlVar(builder, name, 'n', strconv.FormatFloat(data, 'g', -1, 64))
+	case int8, int16, int32, int64:
+		appendXmlVar(builder, name, 'n', fmt.Sprint(data))
+	case *SFSObject:
+		appendXmlObj(builder, name, 'o')
+		for _, key := range data.sortedKeys() {
+			appendXmlValue(builder, key, data.dataHolder[key])
+		}
+		builder.WriteString("</obj>")
+	case *SFSArray:
+		appendXmlObj(builder, name, 'a')
+		for index, element := range data.dataHolder {
+			appendXmlValue(builder, strconv.Itoa(index), element)
+		}
+		builder.WriteString("</obj>")
+	default:
+		letter, elements := xmlArrayElements(data)
+		appendXmlObj(builder, name, 'a')
+		for index, element := range elements {
+			appendXmlVar(builder, strconv.Itoa(index), letter, element)
+		}
+		builder.WriteString("</obj>")
+	}
+}
+
+// xmlArrayElements returns the type letter and formatted elements of a
+// typed array.
+func xmlArrayElements(data interface{}) (byte, []string) {
+	var elements []string
+	switch data := data.(type) {
+	case []bool:
+		for _, element := range data {
+			elements = append(elements, xmlBool(element))
+		}
+		return 'b', elements
+	case []string:
+		return 's', data
+	case []byte:
+		for _, element := range data {
+			elements = append(elements, strconv.Itoa(int(element)))
+		}
+	case []int16:
+		for _, element := range data {
+			elements = append(elements, strconv.Itoa(int(element)))
+		}
+	case []int32:
+		for _, element := range data {
+			elements = append(elements, strconv.Itoa(int(element)))
+		}
+	case []int64:
+		for _, element := range data {
+			elements = append(elements, strconv.FormatInt(element, 10))
+		}
+	case []float32:
+		for _, element := range data {
+			elements = append(elements, strconv.FormatFloat(float64(element), 'g', -1, 32))
+		}
+	case []float64:
+		for _, element := range data {
+			elements = append(elements, strconv.FormatFloat(element, 'g', -1, 64))
+		}
+	}
+	return 'n', elements
+}
+
+func xmlBool(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// SFS1.x quotes attributes with single quotes.
+func appendXmlObj(builder *strings.Builder, name string, letter byte) {
+	builder.WriteString("<obj o='")
+	xml.EscapeText(builder, []byte(name))
+	builder.WriteString("' t='")
+	builder.WriteByte(letter)
+	builder.WriteString("'>")
+}
+
+func appendXmlVar(builder *strings.Builder, name string, letter byte, value string) {
+	builder.WriteString("<var n='")
+	xml.EscapeText(builder, []byte(name))
+	builder.WriteString("' t='")
+	builder.WriteByte(letter)
+	if value == "" {
+		builder.WriteString("' />")
+		return
+	}
+	builder.WriteString("'>")
+	xml.EscapeText(builder, []byte(value))
+	builder.WriteString("</var>")
+}
+
+type xmlParser struct {
+	decoder *xml.Decoder
+	depth   int
+}
+
+func parseXml(input string, letter byte) (sfsDataWrapper, error) {
+	parser := &xmlParser{decoder: xml.NewDecoder(strings.NewReader(input))}
+	for {
+		token, err := parser.decoder.Token()
+		if err == io.EOF {
+			return sfsDataWrapper{}, fmt.Errorf("xml: missing dataObj element")
+		}
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Local != "dataObj" {
+				return sfsDataWrapper{}, fmt.Errorf("xml: expected dataObj, found %s", token.Name.Local)
+			}
+			wrapper, err := parser.parseContainer(letter)
+			if err != nil {
+				return sfsDataWrapper{}, err
+			}
+			if err := parser.checkEnd(); err != nil {
+				return sfsDataWrapper{}, err
+			}
+			return wrapper, nil
+		case xml.CharData:
+			if len(strings.TrimSpace(string(token))) > 0 {
+				return sfsDataWrapper{}, fmt.Errorf("xml: unexpected text %q", token)
+			}
+		}
+	}
+}
+
+// checkEnd fails if anything but whitespace, comments and processing
+// instructions follows the dataObj element.
+func (parser *xmlParser) checkEnd() error {
+	for {
+		token, err := parser.decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			return fmt.Errorf("xml: unexpected element %s after dataObj", token.Name.Local)
+		case xml.CharData:
+			if len(strings.TrimSpace(string(token))) > 0 {
+				return fmt.Errorf("xml: unexpected text %q after dataObj", token)
+			}
+		}
+	}
+}
+
+// parseContainer reads the contents of a dataObj or obj element up to its
+// end tag.
+func (parser *xmlParser) parseContainer(letter byte) (sfsDataWrapper, error) {
+	parser.depth++
+	defer func() { parser.depth-- }()
+	if parser.depth > maxDecodeDepth {
+		return sfsDataWrapper{}, &ErrMaxDepthExceeded{Depth: maxDecodeDepth}
+	}
+	var names []string
+	var values []sfsDataWrapper
+	for {
+		token, err := parser.decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return sfsDataWrapper{}, err
+		}
+		switch token := token.(type) {
+		case xml.EndElement:
+			return newXmlContainer(letter, names, values)
+		case xml.CharData:
+			if len(strings.TrimSpace(string(token))) > 0 {
+				return sfsDataWrapper{}, fmt.Errorf("xml: unexpected text %q", token)
+			}
+		case xml.StartElement:
+			var value sfsDataWrapper
+			var name string
+			switch token.Name.Local {
+			case "var":
+				name = xmlAttr(token, "n")
+				value, err = parser.parseVar(name, xmlAttr(token, "t"))
+			case "obj":
+				name = xmlAttr(token, "o")
+				switch xmlAttr(token, "t") {
+				case "o":
+					value, err = parser.parseContainer('o')
+				case "a":
+					value, err = parser.parseContainer('a')
+				default:
+					err = fmt.Errorf("xml: obj %q has unknown type %q", name, xmlAttr(token, "t"))
+				}
+			default:
+				err = fmt.Errorf("xml: unexpected element %s", token.Name.Local)
+			}
+			if err != nil {
+				return sfsDataWrapper{}, err
+			}
+			names = append(names, name)
+			values = append(values, value)
+		}
+	}
+}
+
+func (parser *xmlParser) parseVar(name string, letter string) (sfsDataWrapper, error) {
+	var text string
+	for {
+		token, err := parser.decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return sfsDataWrapper{}, err
+		}
+		switch token := token.(type) {
+		case xml.CharData:
+			text += string(token)
+		case xml.StartElement:
+			return sfsDataWrapper{}, fmt.Errorf("xml: unexpected element %s in var %q", token.Name.Local, name)
+		case xml.EndElement:
+			wrapper, err := convertXmlVar(text, letter)
+			if err != nil {
+				return sfsDataWrapper{}, fmt.Errorf("xml: var %q: %w", name, err)
+			}
+			return wrapper, nil
+		}
+	}
+}
+
+func convertXmlVar(text string, letter string) (sfsDataWrapper, error) {
+	switch letter {
+	case "x":
+		return sfsDataWrapper{typeId: TypeNull}, nil
+	case "b":
+		switch strings.TrimSpace(text) {
+		case "1", "true":
+			return sfsDataWrapper{typeId: TypeBool, data: true}, nil
+		case "0", "false":
+			return sfsDataWrapper{typeId: TypeBool, data: false}, nil
+		}
+		return sfsDataWrapper{}, fmt.Errorf("%q is not a bool", text)
+	case "n":
+		text = strings.TrimSpace(text)
+		if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+			if integerFits(integer, TypeInt) {
+				return sfsDataWrapper{typeId: TypeInt, data: int32(integer)}, nil
+			}
+			return sfsDataWrapper{typeId: TypeLong, data: integer}, nil
+		}
+		double, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return sfsDataWrapper{}, fmt.Errorf("%q is not a number", text)
+		}
+		return sfsDataWrapper{typeId: TypeDouble, data: double}, nil
+	case "s":
+		if len(text) > maxUtfStringLength {
+			return sfsDataWrapper{typeId: TypeText, data: text}, nil
+		}
+		return sfsDataWrapper{typeId: TypeUtfString, data: text}, nil
+	}
+	return sfsDataWrapper{}, fmt.Errorf("unknown type %q", letter)
+}
+
+// newXmlContainer builds an SFSObject, or an SFSArray whose elements are
+// ordered by the indexes they are named by, which must run from 0 without
+// gaps.
+func newXmlContainer(letter byte, names []string, values []sfsDataWrapper) (sfsDataWrapper, error) {
+	if letter == 'o' {
+		sfsObject := NewSFSObject()
+		for i, name := range names {
+			if _, exists := sfsObject.dataHolder[name]; exists {
+				return sfsDataWrapper{}, fmt.Errorf("xml: duplicate key %q", name)
+			}
+			if err := sfsObject.putsfsDataWrapper(name, &values[i]); err != nil {
+				return sfsDataWrapper{}, err
+			}
+		}
+		return sfsDataWrapper{typeId: TypeSFSObject, data: sfsObject}, nil
+	}
+	indexes := make([]int, len(names))
+	order := make([]int, len(names))
+	for i, name := range names {
+		index, err := strconv.Atoi(name)
+		if err != nil || index < 0 {
+			return sfsDataWrapper{}, fmt.Errorf("xml: array element has invalid index %q", name)
+		}
+		indexes[i] = index
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return indexes[a] - indexes[b] })
+	sfsArray := NewSFSArray()
+	for i, position := range order {
+		if i > 0 && indexes[position] == indexes[order[i-1]] {
+			return sfsDataWrapper{}, fmt.Errorf("xml: duplicate array index %d", indexes[position])
+		}
+		if indexes[position] != i {
+			return sfsDataWrapper{}, fmt.Errorf("xml: array index %d is missing", i)
+		}
+		sfsArray.addsfsDataWrapper(values[position])
+	}
+	return sfsDataWrapper{typeId: TypeSFSArray, data: sfsArray}, nil
+}
+
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
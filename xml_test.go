@@ -0,0 +1,190 @@
+package sfstypes
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestXmlOutput(t *testing.T) {
+	nested := NewSFSArray()
+	nested.AddInt(1)
+	nested.AddNull()
+	sfsobject := NewSFSObject()
+	sfsobject.PutUtfString("name", `<a & 'b' "c">`)
+	sfsobject.PutBool("on", true)
+	sfsobject.PutShort("level", -5)
+	sfsobject.PutDouble("ratio", 0.5)
+	sfsobject.PutNull("none")
+	sfsobject.PutSFSArray("list", nested)
+	sfsobject.PutSFSObject("pos", NewSFSObject())
+	sfsobject.PutBoolArray("flags", []bool{false, true})
+	sfsobject.PutByteArray("bytes", []int8{-1})
+	sfsobject.PutUtfString("empty", "")
+
+	want := "<dataObj>" +
+		"<obj o='bytes' t='a'><var n='0' t='n'>255</var></obj>" +
+		"<var n='empty' t='s' />" +
+		"<obj o='flags' t='a'><var n='0' t='b'>0</var><var n='1' t='b'>1</var></obj>" +
+		"<var n='level' t='n'>-5</var>" +
+		"<obj o='list' t='a'><var n='0' t='n'>1</var><var n='1' t='x' /></obj>" +
+		"<var n='name' t='s'>&lt;a &amp; &#39;b&#39; &#34;c&#34;&gt;</var>" +
+		"<var n='none' t='x' />" +
+		"<var n='on' t='b'>1</var>" +
+		"<obj o='pos' t='o'></obj>" +
+		"<var n='ratio' t='n'>0.5</var>" +
+		"</dataObj>"
+	if got := sfsobject.ToXml(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestXmlParsing(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<!-- from an SFS1.x client -->
+<dataObj>
+	<var n='id' t='n'> 7 </var>
+	<var n='big' t='n'>3000000000</var>
+	<var n='ratio' t='n'>1e-3</var>
+	<var n='on' t='b'>true</var>
+	<var n='off' t='b'>0</var>
+	<var n='name' t='s'> spaced &amp; escaped </var>
+	<var n='long' t='s'>` + strings.Repeat("x", maxUtfStringLength+1) + `</var>
+	<var n='none' t='x' />
+	<obj o='list' t='a'>
+		<var n='1' t='s'>second</var>
+		<obj n='ignored' o='0' t='o'><var n='k' t='n'>1</var></obj>
+	</obj>
+</dataObj>
+`
+	sfsobject, err := NewSFSObjectFromXmlData(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path     string
+		wantType DataType
+		wantData interface{}
+	}{
+		{"id", TypeInt, int32(7)},
+		{"big", TypeLong, int64(3000000000)},
+		{"ratio", TypeDouble, 0.001},
+		{"on", TypeBool, true},
+		{"off", TypeBool, false},
+		{"name", TypeUtfString, " spaced & escaped "},
+		{"long", TypeText, strings.Repeat("x", maxUtfStringLength+1)},
+		{"none", TypeNull, nil},
+		{"list[0].k", TypeInt, int32(1)},
+		{"list[1]", TypeUtfString, "second"},
+	}
+	values := make(map[string]Value)
+	sfsobject.Walk(func(path string, value Value) error {
+		values[path] = value
+		return nil
+	})
+	for _, test := range tests {
+		value, exists := values[test.path]
+		if !exists {
+			t.Errorf("%s is missing", test.path)
+			continue
+		}
+		if value.Type() != test.wantType {
+			t.Errorf("%s: got %v, want %v", test.path, value.Type(), test.wantType)
+		} else if test.wantData != nil && !reflect.DeepEqual(value.Interface(), test.wantData) {
+			t.Errorf("%s: got %#v, want %#v", test.path, value.Interface(), test.wantData)
+		}
+	}
+}
+
+func TestXmlRoundTrip(t *testing.T) {
+	sfsobject := NewSFSObject()
+	sfsobject.PutInt("int", -1)
+	sfsobject.PutLong("long", 1<<40)
+	sfsobject.PutDouble("double", 2.5)
+	sfsobject.PutUtfString("string", "a\tb")
+	sfsobject.PutBool("bool", false)
+	sfsobject.PutNull("null")
+	sfsobject.PutSFSObject("object", NewSFSObject())
+	decoded, err := NewSFSObjectFromXmlData(sfsobject.ToXml())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ToXml() != sfsobject.ToXml() || decoded.GetDump() != sfsobject.GetDump() {
+		t.Fatalf("round trip changed\n%s\nto\n%s", sfsobject.GetDump(), decoded.GetDump())
+	}
+
+	sfsarray := NewSFSArray()
+	sfsarray.AddUtfString("a")
+	sfsarray.AddSFSArray(NewSFSArray())
+	if got, want := sfsarray.ToXml(), "<dataObj><var n='0' t='s'>a</var><obj o='1' t='a'></obj></dataObj>"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	decodedArray, err := NewSFSArrayFromXmlData(sfsarray.ToXml())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decodedArray.GetDump() != sfsarray.GetDump() {
+		t.Fatalf("array round trip changed\n%s\nto\n%s", sfsarray.GetDump(), decodedArray.GetDump())
+	}
+
+	// The number types all become n, so they come back as INT, LONG or DOUBLE.
+	lossy := NewSFSObject()
+	lossy.PutByte("byte", 1)
+	lossy.PutFloat("float", 1.5)
+	lossy.PutShortArray("shorts", []int16{1})
+	lossy.PutText("text", "t")
+	decoded, err = NewSFSObjectFromXmlData(lossy.ToXml())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "(int) byte: 1\n(double) float: 1.5\n(sfs_array) shorts:\n\t(int) 1\n(utf_string) text: \"t\"\n"
+	if got := decoded.GetDump(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestXmlRejectsInvalidInput(t *testing.T) {
+	deep := strings.Repeat("<obj o='a' t='o'>", maxDecodeDepth) + strings.Repeat("</obj>", maxDecodeDepth)
+	tests := []struct {
+		xml  string
+		want string
+	}{
+		{"", "missing dataObj"},
+		{"<root/>", "expected dataObj"},
+		{"text<dataObj/>", "unexpected text"},
+		{"<dataObj>text</dataObj>", "unexpected text"},
+		{"<dataObj><var n='a' t='n'>1</var><var n='a' t='n'>2</var></dataObj>", `duplicate key "a"`},
+		{"<dataObj><obj o='a' t='a'><var n='0' t='n'>1</var><var n='0' t='n'>2</var></obj></dataObj>", "duplicate array index 0"},
+		{"<dataObj><obj o='a' t='a'><var n='0' t='n'>1</var><var n='2' t='n'>2</var></obj></dataObj>", "array index 1 is missing"},
+		{"<dataObj><obj o='a' t='a'><var n='1' t='n'>1</var></obj></dataObj>", "array index 0 is missing"},
+		{"<dataObj><obj o='a' t='a'><var n='-1' t='n'>1</var></obj></dataObj>", `invalid index "-1"`},
+		{"<dataObj><obj o='a' t='a'><var n='x' t='n'>1</var></obj></dataObj>", `invalid index "x"`},
+		{"<dataObj>" + deep + "</dataObj>", "nested deeper than 128 levels"},
+		{"<dataObj></dataObj><dataObj></dataObj>", "unexpected element dataObj after dataObj"},
+		{"<dataObj></dataObj>trailing", `unexpected text "trailing" after dataObj`},
+		{"<dataObj></dataObj></extra>", "unexpected end element"},
+		{"<dataObj><obj o='a' t='q'></obj></dataObj>", `unknown type "q"`},
+		{"<dataObj><item/></dataObj>", "unexpected element item"},
+		{"<dataObj><var n='a' t='b'>yes</var></dataObj>", `var "a": "yes" is not a bool`},
+		{"<dataObj><var n='a' t='n'>1x</var></dataObj>", `var "a": "1x" is not a number`},
+		{"<dataObj><var n='a' t='z'>1</var></dataObj>", `unknown type "z"`},
+		{"<dataObj><var n='a' t='s'><b/></var></dataObj>", "unexpected element b in var"},
+		{"<dataObj><var n='a' t='s'>", "unexpected EOF"},
+		{"<dataObj>", "unexpected EOF"},
+	}
+	for _, test := range tests {
+		_, err := NewSFSObjectFromXmlData(test.xml)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%.60q: got %v, want %q", test.xml, err, test.want)
+		}
+	}
+
+	var tooDeep *ErrMaxDepthExceeded
+	if _, err := NewSFSObjectFromXmlData("<dataObj>" + deep + "</dataObj>"); !errors.As(err, &tooDeep) {
+		t.Errorf("deep nesting: got %v, want ErrMaxDepthExceeded", err)
+	}
+	if _, err := NewSFSObjectFromXmlData("<dataObj></dataObj>\n<!-- done -->\n"); err != nil {
+		t.Errorf("trailing comment: %v", err)
+	}
+}
@@ -0,0 +1,995 @@
+package sfstypes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// YAML conversion supports the subset of YAML that is useful for hand-edited
+// fixtures: block mappings and sequences, flow collections ([...], {...}),
+// plain, single- and double-quoted scalars, literal block scalars (|, |-),
+// comments and a leading "---". Anchors, aliases, multiple documents and
+// multi-line flow collections are not supported.
+//
+// Untagged scalars map to NULL (null, ~ or nothing), BOOL (true, false), INT
+// or LONG (integers), DOUBLE (floats, .inf, .nan) and UTF_STRING (anything
+// else, or quoted). Every other type is written with a tag:
+//
+//	level: !short 5
+//	motd: !text "..."
+//	scores: !int[] [1, 2, 3]
+//	avatar: !!binary AQID
+//
+// Scalar tags are !null, !bool, !byte, !short, !int, !long, !float, !double,
+// !string and !text; array tags are !bool[], !byte[], !short[], !int[],
+// !long[], !float[], !double[] and !string[]. BYTE_ARRAYs are written as
+// base64 with the standard !!binary tag. ToYaml writes mapping keys in
+// sorted order, so equal objects give the same document.
+//
+// Double-quoted scalars support all YAML escapes, including \e, \N, \_, \L,
+// \P, \/ and an escaped space; \x gives a code point, not a byte, so strings
+// must be valid UTF-8 to survive a round trip. They can't span lines.
+
+func (sfsobject *SFSObject) ToYaml() string {
+	var encoder yamlEncoder
+	encoder.writeSFSObject(sfsobject, 0)
+	return encoder.String()
+}
+
+func (sfsarray *SFSArray) ToYaml() string {
+	var encoder yamlEncoder
+	encoder.writeSFSArray(sfsarray, 0)
+	return encoder.String()
+}
+
+func NewSFSObjectFromYamlData(yamlString string) (*SFSObject, error) {
+	wrapper, err := parseYaml(yamlString, TypeSFSObject)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSObject), nil
+}
+
+func NewSFSArrayFromYamlData(yamlString string) (*SFSArray, error) {
+	wrapper, err := parseYaml(yamlString, TypeSFSArray)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.data.(*SFSArray), nil
+}
+
+var yamlScalarTags = map[DataType]string{
+	TypeByte:      "!byte",
+	TypeShort:     "!short",
+	TypeLong:      "!long",
+	TypeFloat:     "!float",
+	TypeText:      "!text",
+	TypeUtfString: "!string",
+}
+
+var yamlArrayTags = map[DataType]string{
+	TypeBoolArray:      "!bool[]",
+	TypeByteArray:      "!byte[]",
+	TypeShortArray:     "!short[]",
+	TypeIntArray:       "!int[]",
+	TypeLongArray:      "!long[]",
+	TypeFloatArray:     "!float[]",
+	TypeDoubleArray:    "!double[]",
+	TypeUtfStringArray: "!string[]",
+}
+
+type yamlEncoder struct {
+	strings.Builder
+}
+
+func (encoder *yamlEncoder) writeIndent(indent int) {
+	encoder.WriteString(strings.Repeat("  ", indent))
+}
+
+func (encoder *yamlEncoder) writeSFSObject(sfsobject *SFSObject, indent int) {
+	if sfsobject.Size() == 0 {
+		encoder.WriteString("{}\n")
+		return
+	}
+	for i, key := range sfsobject.sortedKeys() {
+		// The first key of a sequence item follows its "- ".
+		if i > 0 || indent == 0 {
+			encoder.writeIndent(indent)
+		}
+		encoder.WriteString(yamlString(key, false))
+		encoder.WriteString(":")
+		encoder.writeValue(sfsobject.dataHolder[key], indent+1, false)
+	}
+}
+
+func (encoder *yamlEncoder) writeSFSArray(sfsarray *SFSArray, indent int) {
+	if sfsarray.Size() == 0 {
+		encoder.WriteString("[]\n")
+		return
+	}
+	for i, wrapper := range sfsarray.dataHolder {
+		if i > 0 || indent == 0 {
+			encoder.writeIndent(indent)
+		}
+		encoder.WriteString("-")
+		encoder.writeValue(wrapper, indent+1, true)
+	}
+}
+
+// writeValue writes the value of a mapping entry or sequence item whose
+// prefix has been written, and nested content at indent. Containers in
+// sequence items start on the item's line.
+func (encoder *yamlEncoder) writeValue(wrapper sfsDataWrapper, indent int, inSequence bool) {
+	var size int
+	switch data := wrapper.data.(type) {
+	case *SFSObject:
+		size = data.Size()
+	case *SFSArray:
+		size = data.Size()
+	}
+	if size == 0 {
+		encoder.WriteString(" ")
+		encoder.WriteString(yamlScalar(wrapper))
+		encoder.WriteString("\n")
+		return
+	}
+	if inSequence {
+		encoder.WriteString(" ")
+	} else {
+		encoder.WriteString("\n")
+		encoder.writeIndent(indent)
+	}
+	if sfsobject, isObject := wrapper.data.(*SFSObject); isObject {
+		encoder.writeSFSObject(sfsobject, indent)
+	} else {
+		encoder.writeSFSArray(wrapper.data.(*SFSArray), indent)
+	}
+}
+
+func yamlScalar(wrapper sfsDataWrapper) string {
+	switch data := wrapper.data.(type) {
+	case nil:
+		return "null"
+	case *SFSObject:
+		return "{}"
+	case *SFSArray:
+		return "[]"
+	case bool:
+		return strconv.FormatBool(data)
+	case int8:
+		return "!byte " + strconv.FormatInt(int64(data), 10)
+	case int16:
+		return "!short " + strconv.FormatInt(int64(data), 10)
+	case int32:
+		return strconv.FormatInt(int64(data), 10)
+	case int64:
+		return "!long " + strconv.FormatInt(data, 10)
+	case float32:
+		return "!float " + yamlFloat(float64(data), 32)
+	case float64:
+		return yamlFloat(data, 64)
+	case string:
+		if wrapper.typeId == TypeText {
+			return "!text " + strconv.Quote(data)
+		}
+		if len(data) > maxUtfStringLength {
+			return "!string " + strconv.Quote(data)
+		}
+		return yamlString(data, false)
+	case []byte:
+		return "!!binary " + base64.StdEncoding.EncodeToString(data)
+	}
+	elements := yamlArrayElements(wrapper)
+	return yamlArrayTags[wrapper.typeId] + " [" + strings.Join(elements, ", ") + "]"
+}
+
+func yamlArrayElements(wrapper sfsDataWrapper) []string {
+	var elements []string
+	switch data := wrapper.data.(type) {
+	case []bool:
+		for _, element := range data {
+			elements = append(elements, strconv.FormatBool(element))
+		}
+	case []int16:
+		for _, element := range data {
+			elements = append(elements, strconv.FormatInt(int64(element), 10))
+		}
+	case []int32:
+		for _, element := range data {
+			elements = append(elements, strconv.FormatInt(int64(element), 10))
+		}
+	case []int64:
+		for _, element := range data {
+			elements = append(elements, strconv.FormatInt(element, 10))
+		}
+	case []float32:
+		for _, element := range data {
+			elements = append(elements, yamlFloat(float64(element), 32))
+		}
+	case []float64:
+		for _, element := range data {
+			elements = append(elements, yamlFloat(element, 64))
+		}
+	case []string:
+		for _, element := range data {
+			elements = append(elements, yamlString(element, true))
+		}
+	}
+	return elements
+}
+
+// yamlFloat formats a float so that it doesn't read back as an integer.
+func yamlFloat(value float64, bitSize int) string {
+	switch {
+	case math.IsInf(value, 1):
+		return ".inf"
+	case math.IsInf(value, -1):
+		return "-.inf"
+	case math.IsNaN(value):
+		return ".nan"
+	}
+	result := strconv.FormatFloat(value, 'g', -1, bitSize)
+	if !strings.ContainsAny(result, ".e") {
+		result += ".0"
+	}
+	return result
+}
+
+// yamlString returns str as a plain scalar if it reads back as the same
+// string, and double-quoted otherwise. Inside flow collections plain
+// scalars are never used.
+func yamlString(str string, flow bool) string {
+	if flow || str == "" || str != strings.TrimSpace(str) ||
+		strings.ContainsAny(str[:1], "-?:,[]{}#&*!|>'\"%@`~.+0123456789") ||
+		strings.Contains(str, ": ") || strings.Contains(str, " #") || strings.HasSuffix(str, ":") ||
+		strings.ContainsFunc(str, func(r rune) bool { return r < ' ' || r == 0x7f }) {
+		return strconv.Quote(str)
+	}
+	if _, isString := yamlPlainScalar(str).data.(string); !isString {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+var (
+	yamlIntegerPattern = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloatPattern   = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// yamlPlainScalar resolves an untagged plain scalar.
+func yamlPlainScalar(text string) sfsDataWrapper {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return sfsDataWrapper{typeId: TypeNull}
+	case "true", "True", "TRUE":
+		return sfsDataWrapper{typeId: TypeBool, data: true}
+	case "false", "False", "FALSE":
+		return sfsDataWrapper{typeId: TypeBool, data: false}
+	}
+	if yamlIntegerPattern.MatchString(text) {
+		if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+			if integerFits(value, TypeInt) {
+				return sfsDataWrapper{typeId: TypeInt, data: int32(value)}
+			}
+			return sfsDataWrapper{typeId: TypeLong, data: value}
+		}
+	}
+	if value, err := parseYamlFloat(text, 64); err == nil {
+		return sfsDataWrapper{typeId: TypeDouble, data: value}
+	}
+	return yamlStringWrapper(text)
+}
+
+func yamlStringWrapper(text string) sfsDataWrapper {
+	if len(text) > maxUtfStringLength {
+		return sfsDataWrapper{typeId: TypeText, data: text}
+	}
+	return sfsDataWrapper{typeId: TypeUtfString, data: text}
+}
+
+func parseYamlFloat(text string, bitSize int) (float64, error) {
+	switch strings.ToLower(text) {
+	case ".inf", "+.inf":
+		return math.Inf(1), nil
+	case "-.inf":
+		return math.Inf(-1), nil
+	case ".nan":
+		return math.NaN(), nil
+	}
+	if !yamlFloatPattern.MatchString(text) {
+		return 0, fmt.Errorf("%q is not a number", text)
+	}
+	return strconv.ParseFloat(text, bitSize)
+}
+
+// yamlEscapes maps the single character escapes of double-quoted scalars
+// onto what they stand for.
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v",
+	'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// unquoteYamlDouble resolves the escapes of a double-quoted scalar, without
+// its quotes. \x, \u and \U give a code point in 2, 4 or 8 hex digits.
+func unquoteYamlDouble(text string) (string, error) {
+	if !strings.Contains(text, "\\") {
+		return text, nil
+	}
+	var value strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' {
+			value.WriteByte(text[i])
+			continue
+		}
+		i++
+		if i == len(text) {
+			return "", fmt.Errorf("escape at end of string")
+		}
+		if escaped, known := yamlEscapes[text[i]]; known {
+			value.WriteString(escaped)
+			continue
+		}
+		digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[i]]
+		if digits == 0 {
+			return "", fmt.Errorf("unknown escape \\%c", text[i])
+		}
+		if i+digits >= len(text) {
+			return "", fmt.Errorf("escape \\%c needs %d hex digits", text[i], digits)
+		}
+		codePoint, err := strconv.ParseUint(text[i+1:i+1+digits], 16, 32)
+		if err != nil || codePoint > unicode.MaxRune || (codePoint >= 0xd800 && codePoint <= 0xdfff) {
+			return "", fmt.Errorf("invalid escape \\%s", text[i:i+1+digits])
+		}
+		value.WriteRune(rune(codePoint))
+		i += digits
+	}
+	return value.String(), nil
+}
+
+type yamlKind int
+
+const (
+	yamlScalarNode yamlKind = iota
+	yamlSequenceNode
+	yamlMappingNode
+)
+
+type yamlNode struct {
+	kind   yamlKind
+	line   int
+	tag    string
+	value  string
+	quoted bool
+	keys   []string
+	items  []*yamlNode
+}
+
+type yamlParser struct {
+	lines []string
+	// pos is the index of the next line; override replaces its text (after
+	// the indentation) when a sequence item holds a mapping or sequence.
+	pos      int
+	override *yamlLine
+	depth    int
+}
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+func (parser *yamlParser) errorf(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("yaml line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func parseYaml(input string, typeId DataType) (sfsDataWrapper, error) {
+	parser := &yamlParser{lines: strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n")}
+	var root *yamlNode
+	line, exists := parser.peek()
+	if exists && line.text == "---" {
+		parser.pos++
+		line, exists = parser.peek()
+	}
+	if !exists {
+		root = &yamlNode{kind: yamlMappingNode}
+		if typeId == TypeSFSArray {
+			root.kind = yamlSequenceNode
+		}
+	} else {
+		if line.indent != 0 {
+			return sfsDataWrapper{}, parser.errorf(line.number, "unexpected indentation")
+		}
+		var err error
+		if root, err = parser.parseBlock(0); err != nil {
+			return sfsDataWrapper{}, err
+		}
+		if line, exists := parser.peek(); exists {
+			return sfsDataWrapper{}, parser.errorf(line.number, "unexpected content")
+		}
+	}
+	wrapper, err := parser.resolve(root)
+	if err != nil {
+		return sfsDataWrapper{}, err
+	}
+	if wrapper.typeId != typeId {
+		return sfsDataWrapper{}, &ErrWrongType{ActualType: wrapper.typeId, WantedType: typeId}
+	}
+	return wrapper, nil
+}
+
+// peek returns the next line that isn't blank or a comment.
+func (parser *yamlParser) peek() (yamlLine, bool) {
+	if parser.override != nil {
+		return *parser.override, true
+	}
+	for ; parser.pos < len(parser.lines); parser.pos++ {
+		raw := parser.lines[parser.pos]
+		text := strings.TrimLeft(raw, " ")
+		text = strings.TrimRight(stripYamlComment(text), " \t")
+		if text != "" {
+			return yamlLine{number: parser.pos + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text}, true
+		}
+	}
+	return yamlLine{}, false
+}
+
+func (parser *yamlParser) next() {
+	if parser.override != nil {
+		parser.override = nil
+	}
+	parser.pos++
+}
+
+// stripYamlComment removes a comment that starts with # at the beginning of
+// text or after whitespace, outside of quotes.
+func stripYamlComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && startsYamlToken(text, i):
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// startsYamlToken reports whether a quote at text[i] opens a quoted scalar
+// rather than being part of a plain one.
+func startsYamlToken(text string, i int) bool {
+	return i == 0 || strings.IndexByte(" \t[{,:", text[i-1]) >= 0
+}
+
+func isYamlSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseBlock parses the mapping or sequence whose lines start at indent.
+func (parser *yamlParser) parseBlock(indent int) (*yamlNode, error) {
+	parser.depth++
+	defer func() { parser.depth-- }()
+	line, _ := parser.peek()
+	if parser.depth > maxDecodeDepth {
+		return nil, parser.errorf(line.number, "nested deeper than %d levels", maxDecodeDepth)
+	}
+	if isYamlSequenceItem(line.text) {
+		return parser.parseSequence(indent)
+	}
+	if _, _, isEntry := splitYamlEntry(line.text); isEntry {
+		return parser.parseMapping(indent)
+	}
+	// A lone flow collection or scalar, e.g. "{}" for an empty document.
+	parser.next()
+	return parser.parseInline(line.text, line.number)
+}
+
+func (parser *yamlParser) parseMapping(indent int) (*yamlNode, error) {
+	line, _ := parser.peek()
+	node := &yamlNode{kind: yamlMappingNode, line: line.number}
+	seen := make(map[string]bool)
+	for {
+		line, exists := parser.peek()
+		if !exists || line.indent < indent {
+			return node, nil
+		}
+		if line.indent > indent {
+			return nil, parser.errorf(line.number, "unexpected indentation")
+		}
+		if isYamlSequenceItem(line.text) {
+			return node, nil
+		}
+		keyText, rest, isEntry := splitYamlEntry(line.text)
+		if !isEntry {
+			return nil, parser.errorf(line.number, "expected \"key: value\"")
+		}
+		keyNode, err := parser.parseInline(keyText, line.number)
+		if err != nil {
+			return nil, err
+		}
+		if keyNode.kind != yamlScalarNode || keyNode.tag != "" {
+			return nil, parser.errorf(line.number, "keys must be plain or quoted strings")
+		}
+		if seen[keyNode.value] {
+			return nil, parser.errorf(line.number, "duplicate key %q", keyNode.value)
+		}
+		seen[keyNode.value] = true
+		parser.next()
+		value, err := parser.parseValue(rest, line, true)
+		if err != nil {
+			return nil, err
+		}
+		node.keys = append(node.keys, keyNode.value)
+		node.items = append(node.items, value)
+	}
+}
+
+func (parser *yamlParser) parseSequence(indent int) (*yamlNode, error) {
+	line, _ := parser.peek()
+	node := &yamlNode{kind: yamlSequenceNode, line: line.number}
+	for {
+		line, exists := parser.peek()
+		if !exists || line.indent < indent {
+			return node, nil
+		}
+		if line.indent > indent {
+			return nil, parser.errorf(line.number, "unexpected indentation")
+		}
+		if !isYamlSequenceItem(line.text) {
+			return node, nil
+		}
+		rest := strings.TrimLeft(line.text[1:], " ")
+		var item *yamlNode
+		var err error
+		_, _, isEntry := splitYamlEntry(rest)
+		if isEntry || isYamlSequenceItem(rest) {
+			// "- key: value" or "- - item": the rest of the line starts a
+			// nested block whose indentation is that of its first character.
+			nested := yamlLine{number: line.number, indent: line.indent + len(line.text) - len(rest), text: rest}
+			parser.override = &nested
+			item, err = parser.parseBlock(nested.indent)
+		} else {
+			parser.next()
+			item, err = parser.parseValue(rest, line, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		node.items = append(node.items, item)
+	}
+}
+
+// splitYamlEntry splits "key: value" at the first colon outside of quotes and
+// flow collections that is followed by a space or ends the line.
+func splitYamlEntry(text string) (string, string, bool) {
+	var quote byte
+	nesting := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && startsYamlToken(text, i):
+			quote = c
+		case c == '[' || c == '{':
+			nesting++
+		case c == ']' || c == '}':
+			nesting--
+		case c == ':' && nesting == 0 && (i+1 == len(text) || text[i+1] == ' '):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// parseValue parses the value following "key:" or "-" on line. An empty
+// value, or one that only holds a tag or a block scalar indicator, continues
+// on the following lines.
+func (parser *yamlParser) parseValue(rest string, line yamlLine, inMapping bool) (*yamlNode, error) {
+	tag := ""
+	if strings.HasPrefix(rest, "!") {
+		tag, rest, _ = strings.Cut(rest, " ")
+		rest = strings.TrimSpace(rest)
+	}
+	switch {
+	case rest == "|" || rest == "|-":
+		return &yamlNode{kind: yamlScalarNode, line: line.number, tag: tag, quoted: true, value: parser.parseBlockScalar(line.indent, rest == "|")}, nil
+	case rest != "":
+		node, err := parser.parseInline(rest, line.number)
+		if err != nil {
+			return nil, err
+		}
+		if tag != "" {
+			if node.tag != "" {
+				return nil, parser.errorf(line.number, "value has two tags")
+			}
+			node.tag = tag
+		}
+		return node, nil
+	}
+	next, exists := parser.peek()
+	// Sequences under a key may start at the key's own indentation.
+	if exists && (next.indent > line.indent || (inMapping && next.indent == line.indent && isYamlSequenceItem(next.text))) {
+		node, err := parser.parseBlock(next.indent)
+		if err != nil {
+			return nil, err
+		}
+		node.tag = tag
+		return node, nil
+	}
+	return &yamlNode{kind: yamlScalarNode, line: line.number, tag: tag}, nil
+}
+
+// parseBlockScalar reads the lines of a literal block scalar, which are
+// indented deeper than indent and kept as they are.
+func (parser *yamlParser) parseBlockScalar(indent int, keepNewline bool) string {
+	var lines []string
+	blockIndent := -1
+	for ; parser.pos < len(parser.lines); parser.pos++ {
+		raw := parser.lines[parser.pos]
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" {
+			lines = append(lines, "")
+			continue
+		}
+		lineIndent := len(raw) - len(trimmed)
+		if lineIndent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = lineIndent
+		}
+		if lineIndent < blockIndent {
+			break
+		}
+		lines = append(lines, raw[blockIndent:])
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	result := strings.Join(lines, "\n")
+	if keepNewline && result != "" {
+		result += "\n"
+	}
+	return result
+}
+
+// parseInline parses a scalar or flow collection that makes up the rest of a
+// line.
+func (parser *yamlParser) parseInline(text string, line int) (*yamlNode, error) {
+	scanner := yamlScanner{text: text, line: line, parser: parser}
+	node, err := scanner.parseFlowValue(false)
+	if err != nil {
+		return nil, err
+	}
+	scanner.skipSpaces()
+	if scanner.pos < len(text) {
+		return nil, parser.errorf(line, "unexpected %q", text[scanner.pos:])
+	}
+	return node, nil
+}
+
+type yamlScanner struct {
+	text   string
+	pos    int
+	line   int
+	depth  int
+	parser *yamlParser
+}
+
+func (scanner *yamlScanner) skipSpaces() {
+	for scanner.pos < len(scanner.text) && (scanner.text[scanner.pos] == ' ' || scanner.text[scanner.pos] == '\t') {
+		scanner.pos++
+	}
+}
+
+func (scanner *yamlScanner) errorf(format string, args ...interface{}) error {
+	return scanner.parser.errorf(scanner.line, format, args...)
+}
+
+// parseFlowValue parses a scalar or flow collection. Inside flow collections
+// plain scalars end at ",", "]", "}" and (for keys) ":".
+func (scanner *yamlScanner) parseFlowValue(inFlow bool) (*yamlNode, error) {
+	scanner.skipSpaces()
+	node := &yamlNode{kind: yamlScalarNode, line: scanner.line}
+	if strings.HasPrefix(scanner.text[scanner.pos:], "!") {
+		end := strings.IndexAny(scanner.text[scanner.pos:], " \t")
+		if end < 0 {
+			end = len(scanner.text) - scanner.pos
+		}
+		node.tag = scanner.text[scanner.pos : scanner.pos+end]
+		scanner.pos += end
+		scanner.skipSpaces()
+	}
+	if scanner.pos == len(scanner.text) {
+		return node, nil
+	}
+	switch scanner.text[scanner.pos] {
+	case '[', '{':
+		scanner.depth++
+		defer func() { scanner.depth-- }()
+		if scanner.depth+scanner.parser.depth > maxDecodeDepth {
+			return nil, scanner.errorf("nested deeper than %d levels", maxDecodeDepth)
+		}
+		return scanner.parseFlowCollection(node)
+	case '"':
+		end := scanner.pos + 1
+		for ; end < len(scanner.text) && scanner.text[end] != '"'; end++ {
+			if scanner.text[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(scanner.text) {
+			return nil, scanner.errorf("unterminated string")
+		}
+		value, err := unquoteYamlDouble(scanner.text[scanner.pos+1 : end])
+		if err != nil {
+			return nil, scanner.errorf("invalid string %s: %s", scanner.text[scanner.pos:end+1], err)
+		}
+		scanner.pos = end + 1
+		node.value, node.quoted = value, true
+	case '\'':
+		var value strings.Builder
+		end := scanner.pos + 1
+		for ; end < len(scanner.text); end++ {
+			if scanner.text[end] == '\'' {
+				if end+1 < len(scanner.text) && scanner.text[end+1] == '\'' {
+					end++
+				} else {
+					break
+				}
+			}
+			value.WriteByte(scanner.text[end])
+		}
+		if end >= len(scanner.text) {
+			return nil, scanner.errorf("unterminated string")
+		}
+		scanner.pos = end + 1
+		node.value, node.quoted = value.String(), true
+	default:
+		start := scanner.pos
+		for ; scanner.pos < len(scanner.text); scanner.pos++ {
+			c := scanner.text[scanner.pos]
+			if inFlow && (c == ',' || c == ']' || c == '}' ||
+				(c == ':' && (scanner.pos+1 == len(scanner.text) || strings.IndexByte(" ,]}", scanner.text[scanner.pos+1]) >= 0))) {
+				break
+			}
+		}
+		node.value = strings.TrimSpace(scanner.text[start:scanner.pos])
+	}
+	return node, nil
+}
+
+func (scanner *yamlScanner) parseFlowCollection(node *yamlNode) (*yamlNode, error) {
+	closing := byte(']')
+	node.kind = yamlSequenceNode
+	if scanner.text[scanner.pos] == '{' {
+		closing = '}'
+		node.kind = yamlMappingNode
+	}
+	scanner.pos++
+	for {
+		scanner.skipSpaces()
+		if scanner.pos < len(scanner.text) && scanner.text[scanner.pos] == closing {
+			scanner.pos++
+			return node, nil
+		}
+		if len(node.items) > 0 {
+			if scanner.pos >= len(scanner.text) || scanner.text[scanner.pos] != ',' {
+				return nil, scanner.errorf("expected \",\" or %q", closing)
+			}
+			scanner.pos++
+			scanner.skipSpaces()
+		}
+		if node.kind == yamlMappingNode {
+			key, err := scanner.parseFlowValue(true)
+			if err != nil {
+				return nil, err
+			}
+			scanner.skipSpaces()
+			if key.kind != yamlScalarNode || key.tag != "" || scanner.pos >= len(scanner.text) || scanner.text[scanner.pos] != ':' {
+				return nil, scanner.errorf("expected \"key: value\" in flow mapping")
+			}
+			scanner.pos++
+			node.keys = append(node.keys, key.value)
+		}
+		item, err := scanner.parseFlowValue(true)
+		if err != nil {
+			return nil, err
+		}
+		node.items = append(node.items, item)
+	}
+}
+
+// resolve converts a node into an SFS value, applying its tag.
+func (parser *yamlParser) resolve(node *yamlNode) (sfsDataWrapper, error) {
+	if elementType, isArray := yamlArrayTagTypes[node.tag]; isArray {
+		return parser.resolveArray(node, elementType)
+	}
+	switch node.kind {
+	case yamlMappingNode:
+		if node.tag != "" {
+			return sfsDataWrapper{}, parser.errorf(node.line, "tag %s can't be used on a mapping", node.tag)
+		}
+		sfsobject := NewSFSObject()
+		for i, key := range node.keys {
+			value, err := parser.resolve(node.items[i])
+			if err != nil {
+				return sfsDataWrapper{}, err
+			}
+			if _, exists := sfsobject.dataHolder[key]; exists {
+				return sfsDataWrapper{}, parser.errorf(node.items[i].line, "duplicate key %q", key)
+			}
+			if err := sfsobject.putsfsDataWrapper(key, &value); err != nil {
+				return sfsDataWrapper{}, parser.errorf(node.items[i].line, "%s", err)
+			}
+		}
+		return sfsDataWrapper{typeId: TypeSFSObject, data: sfsobject}, nil
+	case yamlSequenceNode:
+		if node.tag != "" {
+			return sfsDataWrapper{}, parser.errorf(node.line, "tag %s can't be used on a sequence", node.tag)
+		}
+		sfsarray := NewSFSArray()
+		for _, item := range node.items {
+			value, err := parser.resolve(item)
+			if err != nil {
+				return sfsDataWrapper{}, err
+			}
+			sfsarray.addsfsDataWrapper(value)
+		}
+		return sfsDataWrapper{typeId: TypeSFSArray, data: sfsarray}, nil
+	}
+	if node.tag == "" {
+		if node.quoted {
+			return yamlStringWrapper(node.value), nil
+		}
+		return yamlPlainScalar(node.value), nil
+	}
+	if node.tag == "!!binary" {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(node.value), ""))
+		if err != nil {
+			return sfsDataWrapper{}, parser.errorf(node.line, "invalid !!binary data: %s", err)
+		}
+		return sfsDataWrapper{typeId: TypeByteArray, data: data}, nil
+	}
+	typeId, known := yamlScalarTagTypes[node.tag]
+	if !known {
+		return sfsDataWrapper{}, parser.errorf(node.line, "unknown tag %s", node.tag)
+	}
+	wrapper, err := yamlTaggedScalar(node.value, typeId)
+	if err != nil {
+		return sfsDataWrapper{}, parser.errorf(node.line, "%s value: %s", node.tag, err)
+	}
+	return wrapper, nil
+}
+
+var yamlScalarTagTypes = map[string]DataType{
+	"!null":   TypeNull,
+	"!bool":   TypeBool,
+	"!byte":   TypeByte,
+	"!short":  TypeShort,
+	"!int":    TypeInt,
+	"!long":   TypeLong,
+	"!float":  TypeFloat,
+	"!double": TypeDouble,
+	"!string": TypeUtfString,
+	"!text":   TypeText,
+}
+
+var yamlArrayTagTypes = map[string]DataType{
+	"!bool[]":   TypeBool,
+	"!byte[]":   TypeByte,
+	"!short[]":  TypeShort,
+	"!int[]":    TypeInt,
+	"!long[]":   TypeLong,
+	"!float[]":  TypeFloat,
+	"!double[]": TypeDouble,
+	"!string[]": TypeUtfString,
+}
+
+func yamlTaggedScalar(text string, typeId DataType) (sfsDataWrapper, error) {
+	switch typeId {
+	case TypeNull:
+		if yamlPlainScalar(text).typeId != TypeNull {
+			return sfsDataWrapper{}, fmt.Errorf("%q is not null", text)
+		}
+		return sfsDataWrapper{typeId: TypeNull}, nil
+	case TypeBool:
+		wrapper := yamlPlainScalar(text)
+		if wrapper.typeId != TypeBool {
+			return sfsDataWrapper{}, fmt.Errorf("%q is not a bool", text)
+		}
+		return wrapper, nil
+	case TypeByte, TypeShort, TypeInt, TypeLong:
+		bitSize := map[DataType]int{TypeByte: 8, TypeShort: 16, TypeInt: 32, TypeLong: 64}[typeId]
+		value, err := strconv.ParseInt(text, 10, bitSize)
+		if err != nil {
+			return sfsDataWrapper{}, err
+		}
+		return sfsDataWrapper{typeId: typeId, data: integerData(typeId, value)}, nil
+	case TypeFloat:
+		value, err := parseYamlFloat(text, 32)
+		return sfsDataWrapper{typeId: TypeFloat, data: float32(value)}, err
+	case TypeDouble:
+		value, err := parseYamlFloat(text, 64)
+		return sfsDataWrapper{typeId: TypeDouble, data: value}, err
+	default:
+		return sfsDataWrapper{typeId: typeId, data: text}, nil
+	}
+}
+
+func (parser *yamlParser) resolveArray(node *yamlNode, elementType DataType) (sfsDataWrapper, error) {
+	if node.kind == yamlScalarNode && !node.quoted && node.value == "" {
+		node = &yamlNode{kind: yamlSequenceNode, line: node.line}
+	}
+	if node.kind != yamlSequenceNode {
+		return sfsDataWrapper{}, parser.errorf(node.line, "tag %s needs a sequence", node.tag)
+	}
+	values := make([]interface{}, len(node.items))
+	integers := make([]int64, len(node.items))
+	for i, item := range node.items {
+		if item.kind != yamlScalarNode || item.tag != "" {
+			return sfsDataWrapper{}, parser.errorf(item.line, "elements of %s must be untagged scalars", node.tag)
+		}
+		var err error
+		switch elementType {
+		case TypeByte:
+			// Bytes may be written signed or unsigned.
+			integers[i], err = strconv.ParseInt(item.value, 10, 16)
+			if err == nil && (integers[i] < math.MinInt8 || integers[i] > math.MaxUint8) {
+				err = fmt.Errorf("%d is out of range", integers[i])
+			}
+		case TypeShort, TypeInt, TypeLong:
+			var wrapper sfsDataWrapper
+			if wrapper, err = yamlTaggedScalar(item.value, elementType); err == nil {
+				integers[i] = reflect.ValueOf(wrapper.data).Int()
+			}
+		case TypeUtfString:
+			values[i] = item.value
+		default:
+			var wrapper sfsDataWrapper
+			wrapper, err = yamlTaggedScalar(item.value, elementType)
+			values[i] = wrapper.data
+		}
+		if err != nil {
+			return sfsDataWrapper{}, parser.errorf(item.line, "%s element: %s", node.tag, err)
+		}
+	}
+	switch elementType {
+	case TypeBool:
+		return sfsDataWrapper{typeId: TypeBoolArray, data: yamlArray[bool](values)}, nil
+	case TypeFloat:
+		return sfsDataWrapper{typeId: TypeFloatArray, data: yamlArray[float32](values)}, nil
+	case TypeDouble:
+		return sfsDataWrapper{typeId: TypeDoubleArray, data: yamlArray[float64](values)}, nil
+	case TypeUtfString:
+		return sfsDataWrapper{typeId: TypeUtfStringArray, data: yamlArray[string](values)}, nil
+	default:
+		return integerArray(elementType, integers), nil
+	}
+}
+
+func yamlArray[T any](values []interface{}) []T {
+	results := make([]T, len(values))
+	for i, value := range values {
+		results[i] = value.(T)
+	}
+	return results
+}
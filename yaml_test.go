@@ -0,0 +1,213 @@
+package sfstypes
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYamlRoundTrip(t *testing.T) {
+	objects := fuzzSeedObjects()
+	for _, data := range readGoldenCorpus(t) {
+		sfsobject, err := NewSFSObjectFromBinaryData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, sfsobject)
+	}
+	awkward := NewSFSObject()
+	for _, str := range []string{"", " padded ", "- dash", "a: b", "a #b", "true", "null", "~", "12", "1.5", ".inf",
+		"'single'", `"double"`, "tab\there", "line\nbreak", "\x1b\u0085   ", "ünïcødé"} {
+		awkward.PutUtfString("k"+str, str)
+	}
+	awkward.PutUtfStringArray("strings", []string{"a, b", "[x]", "{y}", ""})
+	awkward.PutByteArray("empty", []int8{})
+	awkward.PutSFSObject("emptyObject", NewSFSObject())
+	objects = append(objects, awkward)
+
+	for _, sfsobject := range objects {
+		yaml := sfsobject.ToYaml()
+		decoded, err := NewSFSObjectFromYamlData(yaml)
+		if err != nil {
+			t.Fatalf("%v\n%s", err, yaml)
+		}
+		if got, want := decoded.ToBinary(), sfsobject.ToBinary(); !bytes.Equal(got, want) {
+			t.Fatalf("round trip changed the payload:\n%s\n%x\n%x", yaml, want, got)
+		}
+	}
+}
+
+func TestYamlOutput(t *testing.T) {
+	nested := NewSFSObject()
+	nested.PutFloat("y", -2)
+	nested.PutFloat("x", 1.5)
+	items := NewSFSArray()
+	items.AddInt(1)
+	items.AddSFSObject(nested)
+	items.AddSFSArray(NewSFSArray())
+
+	sfsobject := NewSFSObject()
+	sfsobject.PutUtfString("name", "Player")
+	sfsobject.PutSFSArray("items", items)
+	sfsobject.PutLong("id", 42)
+	sfsobject.PutIntArray("scores", []int32{1, 2})
+	sfsobject.PutByteArray("avatar", []int8{1, 2, 3})
+	sfsobject.PutText("motd", "hi\n")
+	sfsobject.PutNull("alias")
+
+	want := `alias: null
+avatar: !!binary AQID
+id: !long 42
+items:
+  - 1
+  - x: !float 1.5
+    y: !float -2.0
+  - []
+motd: !text "hi\n"
+name: Player
+scores: !int[] [1, 2]
+`
+	if got := sfsobject.ToYaml(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	for i := 0; i < 10; i++ {
+		if got := sfsobject.ToYaml(); got != want {
+			t.Fatalf("output isn't deterministic:\n%s", got)
+		}
+	}
+}
+
+func TestYamlParsing(t *testing.T) {
+	const input = `---
+# a fixture
+id: 7            # INT
+big: 3000000000
+ratio: 0.5
+flags: [true, false]
+tags: !string[]
+  - a
+  - "b c"
+level: !short 5
+scores: !byte[] [-1, 255]
+pos: {x: !float 1, y: !float 2}
+motd: |
+  first
+  second
+note: |-
+  only line
+quoted: 'it''s'
+missing:
+empty: ~
+list:
+- - nested
+  - !long 1
+- key: value
+  other: 2
+`
+	sfsobject, err := NewSFSObjectFromYamlData(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path     string
+		wantType DataType
+		wantData interface{}
+	}{
+		{"id", TypeInt, int32(7)},
+		{"big", TypeLong, int64(3000000000)},
+		{"ratio", TypeDouble, 0.5},
+		{"flags", TypeSFSArray, nil},
+		{"flags[1]", TypeBool, false},
+		{"tags", TypeUtfStringArray, []string{"a", "b c"}},
+		{"level", TypeShort, int16(5)},
+		{"scores", TypeByteArray, []int8{-1, -1}},
+		{"pos.x", TypeFloat, float32(1)},
+		{"motd", TypeUtfString, "first\nsecond\n"},
+		{"note", TypeUtfString, "only line"},
+		{"quoted", TypeUtfString, "it's"},
+		{"missing", TypeNull, nil},
+		{"empty", TypeNull, nil},
+		{"list[0][0]", TypeUtfString, "nested"},
+		{"list[0][1]", TypeLong, int64(1)},
+		{"list[1].key", TypeUtfString, "value"},
+		{"list[1].other", TypeInt, int32(2)},
+	}
+	values := make(map[string]Value)
+	sfsobject.Walk(func(path string, value Value) error {
+		values[path] = value
+		return nil
+	})
+	for _, test := range tests {
+		value, exists := values[test.path]
+		if !exists {
+			t.Errorf("%s is missing", test.path)
+			continue
+		}
+		if value.Type() != test.wantType {
+			t.Errorf("%s: got %v, want %v", test.path, value.Type(), test.wantType)
+		} else if test.wantData != nil && !reflect.DeepEqual(value.Interface(), test.wantData) {
+			t.Errorf("%s: got %#v, want %#v", test.path, value.Interface(), test.wantData)
+		}
+	}
+}
+
+func TestYamlEscapes(t *testing.T) {
+	tests := map[string]string{
+		`\e\N\_\L\P`:           "\x1b\u0085   ",
+		`\/\ \"\\`:             `/ "\`,
+		`\0\a\b\t\n\v\f\r`:     "\x00\a\b\t\n\v\f\r",
+		`\x41\xe9é`:            "Aéé",
+		`\U0001F600 \x7f`:      "\U0001F600 \x7f",
+		`no escapes: # at all`: "no escapes: # at all",
+	}
+	for escaped, want := range tests {
+		sfsobject, err := NewSFSObjectFromYamlData(`key: "` + escaped + `"`)
+		if err != nil {
+			t.Errorf("%s: %v", escaped, err)
+			continue
+		}
+		if got, _ := sfsobject.GetUtfString("key"); got != want {
+			t.Errorf("%s: got %q, want %q", escaped, got, want)
+		}
+	}
+	for _, escaped := range []string{`\q`, `\x4`, `\uD800`, `\U00110000`, `\xzz`} {
+		if _, err := NewSFSObjectFromYamlData(`key: "` + escaped + `"`); err == nil {
+			t.Errorf("%s was accepted", escaped)
+		}
+	}
+}
+
+func TestYamlRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want string
+	}{
+		{"a: 1\n  b: 2", "line 2: unexpected indentation"},
+		{"a: 1\na: 2", `duplicate key "a"`},
+		{"a: {b: 1, b: 2}", `duplicate key "b"`},
+		{"a: !unknown 1", "unknown tag !unknown"},
+		{"a: !byte 128", "!byte value"},
+		{"a: !int[] [1, x]", "!int[] element"},
+		{"a: !byte[] [256]", "out of range"},
+		{"a: !int[] 5", "needs a sequence"},
+		{"a: !int[] [[1]]", "must be untagged scalars"},
+		{"a: !short {b: 1}", "can't be used on a mapping"},
+		{"a: !!binary ***", "invalid !!binary"},
+		{`a: "open`, "unterminated string"},
+		{"a: 'open", "unterminated string"},
+		{"a: [1, 2", `expected ","`},
+		{"a: [1] x", "unexpected"},
+		{"a: !short !long 1", ""},
+		{"- 1", "expected type SFS_OBJECT"},
+		{"[1]: x", "keys must be plain or quoted strings"},
+		{"just text", ""},
+		{strings.Repeat("[", maxDecodeDepth+1), "nested deeper"},
+	}
+	for _, test := range tests {
+		_, err := NewSFSObjectFromYamlData(test.yaml)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%q: got %v, want %q", test.yaml, err, test.want)
+		}
+	}
+}